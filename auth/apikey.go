@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyPrefix 所有 API key 的前綴，用來與 JWT 等其他 bearer token 區分
+const KeyPrefix = "tk_"
+
+var (
+	ErrKeyNotFound  = errors.New("api key not found")
+	ErrInvalidScope = errors.New("scope must be read, write or admin")
+)
+
+// APIKey 已發行的 API key，只保存 secret 的雜湊值
+type APIKey struct {
+	ID        string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string    `json:"name" example:"ci-pipeline"`
+	Scope     Scope     `json:"scope" example:"write" enums:"read,write,admin"`
+	Prefix    string    `json:"prefix" example:"tk_3f9a1c2b"`
+	Tenant    string    `json:"tenant,omitempty" example:"acme"`
+	CreatedAt time.Time `json:"created_at" example:"2026-10-19T09:00:00Z"`
+	hash      string
+}
+
+// KeyStore 保存 API key 的介面
+type KeyStore interface {
+	Issue(name string, scope Scope, tenant string) (string, *APIKey, error)
+	Add(name string, scope Scope, tenant, secret string) (*APIKey, error)
+	Lookup(secret string) (*APIKey, error)
+	List() ([]APIKey, error)
+	Revoke(id string) error
+}
+
+// MemoryKeyStore 以記憶體保存 API key
+type MemoryKeyStore struct {
+	mu     sync.RWMutex
+	byID   map[string]*APIKey
+	byHash map[string]*APIKey
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{
+		byID:   make(map[string]*APIKey),
+		byHash: make(map[string]*APIKey),
+	}
+}
+
+// Issue 產生新的 API key，回傳的明文 secret 只會出現這一次
+// tenant 不為空時，此 key 只能存取該 tenant 的資料
+func (s *MemoryKeyStore) Issue(name string, scope Scope, tenant string) (string, *APIKey, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	secret := KeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	key, err := s.Add(name, scope, tenant, secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Add 以指定的 secret 新增 API key（用於啟動時載入預先設定的 key）
+func (s *MemoryKeyStore) Add(name string, scope Scope, tenant, secret string) (*APIKey, error) {
+	if !scope.Valid() {
+		return nil, ErrInvalidScope
+	}
+
+	key := &APIKey{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Scope:     scope,
+		Prefix:    keyDisplayPrefix(secret),
+		Tenant:    tenant,
+		CreatedAt: time.Now().UTC(),
+		hash:      hashSecret(secret),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.byID[key.ID] = key
+	s.byHash[key.hash] = key
+
+	copied := *key
+	return &copied, nil
+}
+
+// Lookup 依 secret 找出 API key
+func (s *MemoryKeyStore) Lookup(secret string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, exists := s.byHash[hashSecret(secret)]
+	if !exists {
+		return nil, ErrKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+// List 依建立時間列出所有 API key
+func (s *MemoryKeyStore) List() ([]APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.byID))
+	for _, key := range s.byID {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke 撤銷 API key
+func (s *MemoryKeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.byID[id]
+	if !exists {
+		return ErrKeyNotFound
+	}
+	delete(s.byID, id)
+	delete(s.byHash, key.hash)
+	return nil
+}
+
+// hashSecret 以 SHA-256 雜湊 secret；key 本身為 256 位元亂數，不需要慢速雜湊
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// keyDisplayPrefix 取 secret 開頭幾個字元，方便使用者辨識 key
+func keyDisplayPrefix(secret string) string {
+	const n = 8
+	rest := strings.TrimPrefix(secret, KeyPrefix)
+	if len(rest) > n {
+		rest = rest[:n]
+	}
+	return KeyPrefix + rest
+}
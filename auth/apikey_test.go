@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryKeyStore(t *testing.T) {
+	store := NewMemoryKeyStore()
+
+	secret, key, err := store.Issue("ci", ScopeWrite, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, KeyPrefix))
+	assert.True(t, strings.HasPrefix(secret, key.Prefix))
+	assert.Equal(t, ScopeWrite, key.Scope)
+
+	// 只保存雜湊值
+	assert.NotContains(t, store.byID[key.ID].hash, secret)
+
+	found, err := store.Lookup(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+
+	keys, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	require.NoError(t, store.Revoke(key.ID))
+	_, err = store.Lookup(secret)
+	assert.Equal(t, ErrKeyNotFound, err)
+	assert.Equal(t, ErrKeyNotFound, store.Revoke(key.ID))
+}
+
+func TestMemoryKeyStore_InvalidScope(t *testing.T) {
+	store := NewMemoryKeyStore()
+
+	_, _, err := store.Issue("ci", Scope("root"), "")
+	assert.Equal(t, ErrInvalidScope, err)
+}
+
+func TestScope_Includes(t *testing.T) {
+	assert.True(t, ScopeAdmin.Includes(ScopeWrite))
+	assert.True(t, ScopeWrite.Includes(ScopeRead))
+	assert.False(t, ScopeRead.Includes(ScopeWrite))
+	assert.False(t, ScopeWrite.Includes(ScopeAdmin))
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSCacheTTL      = time.Hour
+	defaultJWKSMinRefresh    = 30 * time.Second
+	defaultJWKSFetchTimeout  = 5 * time.Second
+	maxJWKSResponseSizeBytes = 1 << 20
+)
+
+var errKeyNotFound = errors.New("signing key not found")
+
+// jwk JSON Web Key（RFC 7517），只解析驗證簽章需要的欄位
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// SigningKey 驗證簽章用的公鑰
+type SigningKey struct {
+	Key crypto.PublicKey
+	// Alg JWK 宣告的演算法，token 的 alg 必須相同；空字串表示不限定（例如 PEM）
+	Alg string
+}
+
+// KeySet 驗證 JWT 簽章用的公鑰集合
+// 從 JWKS URL 載入時會快取，遇到未知的 kid 或快取過期時重新抓取，以支援金鑰輪替
+type KeySet struct {
+	url        string
+	httpClient *http.Client
+	cacheTTL   time.Duration
+	minRefresh time.Duration
+
+	// fetchMu 同時只讓一個請求抓取 JWKS，抓取期間不持有 mu
+	fetchMu sync.Mutex
+
+	mu   sync.RWMutex
+	keys map[string]SigningKey
+	// fetchedAt 上次成功抓取的時間，attemptedAt 上次嘗試抓取的時間（失敗也會更新），fetchErr 為其結果
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	static      bool
+}
+
+// NewRemoteKeySet 建立從 JWKS URL 載入公鑰的 KeySet
+func NewRemoteKeySet(url string) *KeySet {
+	return &KeySet{
+		url:        url,
+		httpClient: &http.Client{Timeout: defaultJWKSFetchTimeout},
+		cacheTTL:   defaultJWKSCacheTTL,
+		minRefresh: defaultJWKSMinRefresh,
+		keys:       make(map[string]SigningKey),
+	}
+}
+
+// NewStaticKeySet 從本機檔案載入公鑰，檔案可以是 PEM（PUBLIC KEY 或 CERTIFICATE）或 JWKS JSON
+func NewStaticKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	keys, err := parseKeyFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse key file %s: %w", path, err)
+	}
+	return &KeySet{keys: keys, static: true}, nil
+}
+
+// Key 依 kid 取得公鑰；遠端 KeySet 找不到時會重新抓取一次
+func (ks *KeySet) Key(ctx context.Context, kid string) (SigningKey, error) {
+	ks.mu.RLock()
+	key, found := ks.lookup(kid)
+	stale := !ks.static && time.Since(ks.fetchedAt) > ks.cacheTTL
+	ks.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+	if ks.static {
+		return SigningKey{}, errKeyNotFound
+	}
+
+	if err := ks.refresh(ctx, found); err != nil {
+		// 抓取失敗時仍可使用快取中的 key
+		if found {
+			return key, nil
+		}
+		return SigningKey{}, err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, found := ks.lookup(kid); found {
+		return key, nil
+	}
+	return SigningKey{}, errKeyNotFound
+}
+
+// lookup 依 kid 找 key；集合只有一把 key 時，token 沒有 kid 或是本機設定的 key（例如沒有 kid 的 PEM）直接使用
+func (ks *KeySet) lookup(kid string) (SigningKey, bool) {
+	if key, ok := ks.keys[kid]; ok {
+		return key, true
+	}
+	if (kid == "" || ks.static) && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// refresh 重新抓取 JWKS；距離上次嘗試（不論成功與否）太近時略過並回傳上次的結果，
+// 避免偽造的 kid 或失效的 JWKS 端點讓每個請求都去抓取
+// cached 為 true 表示呼叫者已有可用的 key，此時不等待其他請求進行中的抓取
+func (ks *KeySet) refresh(ctx context.Context, cached bool) error {
+	if cached {
+		if !ks.fetchMu.TryLock() {
+			return nil
+		}
+	} else {
+		ks.fetchMu.Lock()
+	}
+	defer ks.fetchMu.Unlock()
+
+	ks.mu.RLock()
+	attemptedAt, lastErr := ks.attemptedAt, ks.fetchErr
+	ks.mu.RUnlock()
+	if !attemptedAt.IsZero() && time.Since(attemptedAt) < ks.minRefresh {
+		return lastErr
+	}
+
+	// 抓取結果由所有請求共用，不因觸發的請求結束而中斷，逾時由 httpClient 控制
+	keys, err := ks.fetch(context.WithoutCancel(ctx))
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.attemptedAt = time.Now()
+	ks.fetchErr = err
+	if err != nil {
+		return err
+	}
+	ks.keys = keys
+	ks.fetchedAt = ks.attemptedAt
+	return nil
+}
+
+func (ks *KeySet) fetch(ctx context.Context) (map[string]SigningKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSResponseSizeBytes))
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	return parseJWKS(data)
+}
+
+func parseKeyFile(data []byte) (map[string]SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return parseJWKS(data)
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = parsed
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return nil, errors.New("key must be RSA or ECDSA")
+	}
+	return map[string]SigningKey{"": {Key: key}}, nil
+}
+
+func parseJWKS(data []byte) (map[string]SigningKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]SigningKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// 略過不支援的 key 類型，不影響其他 key
+			continue
+		}
+		keys[k.Kid] = SigningKey{Key: key, Alg: k.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
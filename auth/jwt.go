@@ -0,0 +1,346 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 預設的時鐘誤差容許值
+const defaultJWTLeeway = 30 * time.Second
+
+// JWTAuthenticator 驗證 Authorization: Bearer 帶入的 JWT（例如公司 SSO / OIDC 發行的 ID 或 access token）
+// 簽章以 Keys 中的公鑰驗證，並檢查 iss、aud、exp 與 nbf
+type JWTAuthenticator struct {
+	Keys *KeySet
+	// Issuer 必須與 iss claim 相同
+	Issuer string
+	// Audience 必須出現在 aud claim 中，空字串表示不檢查
+	Audience string
+	// ScopeClaim 取得 scope 的 claim 名稱，預設為 "scope"
+	// 值可以是以空白分隔的字串或字串陣列，取其中最高的 read/write/admin
+	ScopeClaim string
+	// TenantClaim 取得 tenant 的 claim 名稱，空字串表示不從 token 取得 tenant
+	TenantClaim string
+	// DefaultScope token 沒有可辨識的 scope 時使用，空值表示拒絕此 token
+	DefaultScope Scope
+	// Leeway 檢查 exp、nbf 時容許的時鐘誤差，預設 30 秒
+	Leeway time.Duration
+
+	now func() time.Time
+}
+
+// jwtHeader JOSE header
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims 驗證需要用到的 registered claims，其餘 claim 保留在 raw 中
+type jwtClaims struct {
+	Issuer            string       `json:"iss"`
+	Subject           string       `json:"sub"`
+	Audience          audience     `json:"aud"`
+	ExpiresAt         *json.Number `json:"exp"`
+	NotBefore         *json.Number `json:"nbf"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+	Email             string       `json:"email"`
+
+	raw map[string]interface{}
+}
+
+// audience aud claim 可以是字串或字串陣列
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (a JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok || strings.HasPrefix(token, KeyPrefix) || strings.Count(token, ".") != 2 {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.verify(r.Context(), token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+	return a.principal(claims)
+}
+
+// verify 驗證簽章與 registered claims，回傳解析後的 claims
+func (a JWTAuthenticator) verify(ctx context.Context, token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+
+	hashFunc, ok := signingHashes[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	key, err := a.Keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	if err := verifySignature(header.Alg, hashFunc, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	if err := decodeSegment(parts[1], &claims.raw); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	if err := a.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (a JWTAuthenticator) validateClaims(claims *jwtClaims) error {
+	now := time.Now()
+	if a.now != nil {
+		now = a.now()
+	}
+	leeway := a.Leeway
+	if leeway == 0 {
+		leeway = defaultJWTLeeway
+	}
+
+	if a.Issuer != "" && claims.Issuer != a.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if a.Audience != "" && !claims.Audience.contains(a.Audience) {
+		return errors.New("unexpected audience")
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	exp, err := numericDate(*claims.ExpiresAt)
+	if err != nil {
+		return errors.New("invalid exp claim")
+	}
+	if now.After(exp.Add(leeway)) {
+		return errors.New("token expired")
+	}
+
+	if claims.NotBefore != nil {
+		nbf, err := numericDate(*claims.NotBefore)
+		if err != nil {
+			return errors.New("invalid nbf claim")
+		}
+		if now.Add(leeway).Before(nbf) {
+			return errors.New("token not yet valid")
+		}
+	}
+
+	if claims.Subject == "" {
+		return errors.New("token has no subject")
+	}
+	return nil
+}
+
+// principal 將 claims 對應到 Principal：sub 為 ID，name / preferred_username / email 為名稱
+func (a JWTAuthenticator) principal(claims *jwtClaims) (*Principal, error) {
+	scope := a.scopeFrom(claims.raw)
+	if scope == "" {
+		return nil, fmt.Errorf("%w: token has no recognised scope", ErrInvalidCredentials)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = claims.Email
+	}
+	if name == "" {
+		name = claims.Subject
+	}
+	p := &Principal{ID: claims.Subject, Name: name, Scope: scope, Source: "jwt"}
+	if a.TenantClaim != "" {
+		p.Tenant, _ = claims.raw[a.TenantClaim].(string)
+	}
+	return p, nil
+}
+
+// scopeFrom 從 scope claim 取出最高的 read/write/admin
+// 也接受 "tasks:write" 這類帶前綴的值，只看最後一段
+func (a JWTAuthenticator) scopeFrom(raw map[string]interface{}) Scope {
+	claim := a.ScopeClaim
+	if claim == "" {
+		claim = "scope"
+	}
+
+	var values []string
+	switch v := raw[claim].(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var best Scope
+	for _, v := range values {
+		if i := strings.LastIndexAny(v, ":/."); i >= 0 {
+			v = v[i+1:]
+		}
+		s := Scope(strings.ToLower(v))
+		if s.Valid() && scopeLevels[s] > scopeLevels[best] {
+			best = s
+		}
+	}
+	if best == "" {
+		return a.DefaultScope
+	}
+	return best
+}
+
+var signingHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// ecdsaCurves ES 演算法對應的曲線（RFC 7518 3.4）
+var ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// verifySignature 以 key 驗證簽章，key 宣告的演算法或 ECDSA 曲線與 alg 不符時拒絕
+func verifySignature(alg string, hashFunc crypto.Hash, key SigningKey, signingInput string, signature []byte) error {
+	if key.Alg != "" && key.Alg != alg {
+		return errors.New("signing key does not match algorithm")
+	}
+	sum := digest(hashFunc, signingInput)
+
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.Key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("signing key does not match algorithm")
+		}
+		if rsa.VerifyPKCS1v15(pub, hashFunc, sum, signature) != nil {
+			return errors.New("invalid signature")
+		}
+	case "PS":
+		pub, ok := key.Key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("signing key does not match algorithm")
+		}
+		if rsa.VerifyPSS(pub, hashFunc, sum, signature, nil) != nil {
+			return errors.New("invalid signature")
+		}
+	case "ES":
+		pub, ok := key.Key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve.Params().Name != ecdsaCurves[alg] {
+			return errors.New("signing key does not match algorithm")
+		}
+		// JWS 的 ECDSA 簽章為固定長度的 r||s，不是 ASN.1
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, sum, r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	return nil
+}
+
+func digest(hashFunc crypto.Hash, input string) []byte {
+	var h hash.Hash
+	switch hashFunc {
+	case crypto.SHA384:
+		h = sha512.New384()
+	case crypto.SHA512:
+		h = sha512.New()
+	default:
+		h = sha256.New()
+	}
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// numericDate 解析 NumericDate（秒數，可能帶小數）
+func numericDate(n json.Number) (time.Time, error) {
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), nil
+}
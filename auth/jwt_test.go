@@ -0,0 +1,550 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "task-api"
+)
+
+// testSigner 測試用的簽章金鑰
+type testSigner struct {
+	kid string
+	alg string
+	key crypto.Signer
+}
+
+func newRSASigner(t *testing.T, kid string) testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testSigner{kid: kid, alg: "RS256", key: key}
+}
+
+func newECSigner(t *testing.T, kid string) testSigner {
+	t.Helper()
+	return newECSignerOnCurve(t, kid, elliptic.P256())
+}
+
+// newECSignerOnCurve 以指定曲線的 key 簽出 ES256 token，用於測試曲線與演算法不符的情況
+func newECSignerOnCurve(t *testing.T, kid string, curve elliptic.Curve) testSigner {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return testSigner{kid: kid, alg: "ES256", key: key}
+}
+
+func (s testSigner) sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	header := map[string]string{"alg": s.alg, "typ": "JWT"}
+	if s.kid != "" {
+		header["kid"] = s.kid
+	}
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	sum := digest(crypto.SHA256, input)
+
+	var signature []byte
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum)
+		require.NoError(t, err)
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, sVal, err := ecdsa.Sign(rand.Reader, key, sum)
+		require.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = append(r.FillBytes(make([]byte, size)), sVal.FillBytes(make([]byte, size))...)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (s testSigner) jwk() map[string]string {
+	switch pub := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA", "kid": s.kid, "use": "sig", "alg": s.alg,
+			"n": base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return map[string]string{
+			"kty": "EC", "kid": s.kid, "use": "sig", "alg": s.alg, "crv": pub.Curve.Params().Name,
+			"x": base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+			"y": base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+		}
+	}
+	return nil
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"aud":   []string{testAudience, "other"},
+		"sub":   "user-123",
+		"name":  "Alice",
+		"scope": "openid tasks:write",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+}
+
+// jwksServer 本機 JWKS 測試伺服器，可替換金鑰模擬輪替
+type jwksServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	signers  []testSigner
+	requests int32
+}
+
+func newJWKSServer(t *testing.T, signers ...testSigner) *jwksServer {
+	t.Helper()
+	s := &jwksServer{signers: signers}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		keys := make([]map[string]string, 0, len(s.signers))
+		for _, signer := range s.signers {
+			keys = append(keys, signer.jwk())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setSigners(signers ...testSigner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signers = signers
+}
+
+func authenticate(a Authenticator, token string) (*Principal, error) {
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return a.Authenticate(req)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaSigner := newRSASigner(t, "rsa-1")
+	ecSigner := newECSigner(t, "ec-1")
+	server := newJWKSServer(t, rsaSigner, ecSigner)
+
+	authenticator := JWTAuthenticator{
+		Keys:     NewRemoteKeySet(server.URL),
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	}
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name          string
+		token         string
+		expectedError string
+		expected      *Principal
+	}{
+		{
+			name:     "RS256",
+			token:    rsaSigner.sign(t, validClaims()),
+			expected: &Principal{ID: "user-123", Name: "Alice", Scope: ScopeWrite, Source: "jwt"},
+		},
+		{
+			name:     "ES256",
+			token:    ecSigner.sign(t, with("scope", []string{"admin"})),
+			expected: &Principal{ID: "user-123", Name: "Alice", Scope: ScopeAdmin, Source: "jwt"},
+		},
+		{
+			name:     "沒有 name 時使用 preferred_username",
+			token:    rsaSigner.sign(t, func() map[string]interface{} { c := with("name", nil); c["preferred_username"] = "alice"; return c }()),
+			expected: &Principal{ID: "user-123", Name: "alice", Scope: ScopeWrite, Source: "jwt"},
+		},
+		{
+			name:          "過期",
+			token:         rsaSigner.sign(t, with("exp", time.Now().Add(-time.Hour).Unix())),
+			expectedError: "invalid credentials: token expired",
+		},
+		{
+			name:          "沒有 exp",
+			token:         rsaSigner.sign(t, with("exp", nil)),
+			expectedError: "invalid credentials: token has no expiry",
+		},
+		{
+			name:          "尚未生效",
+			token:         rsaSigner.sign(t, with("nbf", time.Now().Add(time.Hour).Unix())),
+			expectedError: "invalid credentials: token not yet valid",
+		},
+		{
+			name:          "issuer 不符",
+			token:         rsaSigner.sign(t, with("iss", "https://evil.example.com")),
+			expectedError: "invalid credentials: unexpected issuer",
+		},
+		{
+			name:          "audience 不符",
+			token:         rsaSigner.sign(t, with("aud", "someone-else")),
+			expectedError: "invalid credentials: unexpected audience",
+		},
+		{
+			name:          "沒有可辨識的 scope",
+			token:         rsaSigner.sign(t, with("scope", "openid profile")),
+			expectedError: "invalid credentials: token has no recognised scope",
+		},
+		{
+			name:          "未知的 kid",
+			token:         newRSASigner(t, "unknown").sign(t, validClaims()),
+			expectedError: "invalid credentials: signing key not found",
+		},
+		{
+			name:          "簽章被竄改的 kid",
+			token:         testSigner{kid: "rsa-1", alg: "RS256", key: newRSASigner(t, "").key}.sign(t, validClaims()),
+			expectedError: "invalid credentials: invalid signature",
+		},
+		{
+			name:          "不支援的演算法",
+			token:         encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + ".",
+			expectedError: `invalid credentials: unsupported signing algorithm "none"`,
+		},
+		{
+			name:          "HS256 不接受",
+			token:         encodeSegment(t, map[string]string{"alg": "HS256", "kid": "rsa-1"}) + "." + encodeSegment(t, validClaims()) + ".c2ln",
+			expectedError: `invalid credentials: unsupported signing algorithm "HS256"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := authenticate(authenticator, tt.token)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+				assert.Equal(t, tt.expectedError, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+func TestJWTAuthenticator_NoCredentials(t *testing.T) {
+	authenticator := JWTAuthenticator{Keys: NewRemoteKeySet("http://127.0.0.1:0")}
+
+	for _, token := range []string{"", "tk_abc.def.ghi", "not-a-jwt"} {
+		req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		_, err := authenticator.Authenticate(req)
+		assert.ErrorIs(t, err, ErrNoCredentials, token)
+	}
+}
+
+func TestJWTAuthenticator_DefaultScope(t *testing.T) {
+	signer := newRSASigner(t, "rsa-1")
+	server := newJWKSServer(t, signer)
+
+	authenticator := JWTAuthenticator{
+		Keys:         NewRemoteKeySet(server.URL),
+		Issuer:       testIssuer,
+		ScopeClaim:   "roles",
+		DefaultScope: ScopeRead,
+	}
+
+	claims := validClaims()
+	claims["roles"] = []string{"task-api/admin"}
+	p, err := authenticate(authenticator, signer.sign(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, p.Scope)
+
+	delete(claims, "roles")
+	p, err = authenticate(authenticator, signer.sign(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, ScopeRead, p.Scope)
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldSigner := newRSASigner(t, "key-1")
+	newSigner := newRSASigner(t, "key-2")
+	server := newJWKSServer(t, oldSigner)
+
+	keys := NewRemoteKeySet(server.URL)
+	keys.minRefresh = 0
+	authenticator := JWTAuthenticator{Keys: keys, Issuer: testIssuer, Audience: testAudience}
+
+	_, err := authenticate(authenticator, oldSigner.sign(t, validClaims()))
+	require.NoError(t, err)
+	_, err = authenticate(authenticator, oldSigner.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&server.requests), "已快取的 key 不應重新抓取")
+
+	// 發行者輪替金鑰：遇到新的 kid 時重新抓取 JWKS
+	server.setSigners(newSigner)
+	_, err = authenticate(authenticator, newSigner.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&server.requests))
+
+	// 舊的 key 已從 JWKS 移除
+	_, err = authenticate(authenticator, oldSigner.sign(t, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestKeySet_RefreshRateLimited(t *testing.T) {
+	signer := newRSASigner(t, "key-1")
+	server := newJWKSServer(t, signer)
+
+	authenticator := JWTAuthenticator{Keys: NewRemoteKeySet(server.URL), Issuer: testIssuer}
+
+	_, err := authenticate(authenticator, signer.sign(t, validClaims()))
+	require.NoError(t, err)
+
+	// 大量未知 kid 的 token 不應讓每個請求都打到 JWKS
+	for i := 0; i < 5; i++ {
+		_, err := authenticate(authenticator, newRSASigner(t, "forged").sign(t, validClaims()))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&server.requests))
+}
+
+func TestKeySet_CacheExpiry(t *testing.T) {
+	signer := newRSASigner(t, "key-1")
+	server := newJWKSServer(t, signer)
+
+	keys := NewRemoteKeySet(server.URL)
+	authenticator := JWTAuthenticator{Keys: keys, Issuer: testIssuer}
+
+	_, err := authenticate(authenticator, signer.sign(t, validClaims()))
+	require.NoError(t, err)
+
+	expire := func() {
+		keys.mu.Lock()
+		keys.fetchedAt = time.Now().Add(-2 * defaultJWKSCacheTTL)
+		keys.attemptedAt = keys.fetchedAt
+		keys.mu.Unlock()
+	}
+
+	// 快取過期後重新抓取
+	expire()
+	_, err = authenticate(authenticator, signer.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&server.requests))
+
+	// 其他請求抓取中時，已快取的 key 不等待抓取完成
+	expire()
+	keys.fetchMu.Lock()
+	_, err = authenticate(authenticator, signer.sign(t, validClaims()))
+	keys.fetchMu.Unlock()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&server.requests))
+
+	// 抓取失敗時仍可使用快取中的 key，且在 minRefresh 內不再重新抓取
+	server.setSigners()
+	for i := 0; i < 5; i++ {
+		_, err = authenticate(authenticator, signer.sign(t, validClaims()))
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&server.requests))
+
+	expire()
+	server.Close()
+	_, err = authenticate(authenticator, signer.sign(t, validClaims()))
+	require.NoError(t, err)
+}
+
+func TestNewStaticKeySet(t *testing.T) {
+	rsaSigner := newRSASigner(t, "")
+	dir := t.TempDir()
+
+	der, err := x509.MarshalPKIXPublicKey(rsaSigner.key.Public())
+	require.NoError(t, err)
+	pemPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	keys, err := NewStaticKeySet(pemPath)
+	require.NoError(t, err)
+	authenticator := JWTAuthenticator{Keys: keys, Issuer: testIssuer}
+
+	p, err := authenticate(authenticator, rsaSigner.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", p.ID)
+
+	// IdP 發行的 token 會帶 kid，PEM 沒有 kid 仍可驗證
+	withKid := rsaSigner
+	withKid.kid = "idp-key-1"
+	_, err = authenticate(authenticator, withKid.sign(t, validClaims()))
+	require.NoError(t, err)
+
+	// JWKS 格式的本機檔案
+	ecSigner := newECSigner(t, "ec-1")
+	data, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{ecSigner.jwk()}})
+	require.NoError(t, err)
+	jwksPath := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksPath, data, 0o600))
+
+	keys, err = NewStaticKeySet(jwksPath)
+	require.NoError(t, err)
+	authenticator.Keys = keys
+
+	_, err = authenticate(authenticator, ecSigner.sign(t, validClaims()))
+	require.NoError(t, err)
+	_, err = authenticate(authenticator, rsaSigner.sign(t, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = NewStaticKeySet(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestJWTAuthenticator_KeyAlgorithm(t *testing.T) {
+	rsaSigner := newRSASigner(t, "rsa-1")
+	p384Signer := newECSignerOnCurve(t, "ec-1", elliptic.P384())
+
+	tests := []struct {
+		name    string
+		signer  testSigner
+		jwk     func(jwk map[string]string)
+		wantErr bool
+	}{
+		{name: "演算法相符", signer: rsaSigner},
+		{name: "JWK 沒有宣告演算法", signer: rsaSigner, jwk: func(jwk map[string]string) { delete(jwk, "alg") }},
+		{name: "JWK 宣告的演算法不同", signer: rsaSigner, jwk: func(jwk map[string]string) { jwk["alg"] = "RS384" }, wantErr: true},
+		{name: "ES256 token 對 P-384 key", signer: p384Signer, jwk: func(jwk map[string]string) { delete(jwk, "alg") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwk := tt.signer.jwk()
+			if tt.jwk != nil {
+				tt.jwk(jwk)
+			}
+			data, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{jwk}})
+			require.NoError(t, err)
+			keys, err := parseJWKS(data)
+			require.NoError(t, err)
+			authenticator := JWTAuthenticator{Keys: &KeySet{keys: keys, static: true}, Issuer: testIssuer}
+
+			_, err = authenticate(authenticator, tt.signer.sign(t, validClaims()))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+				assert.ErrorContains(t, err, "signing key does not match algorithm")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMiddleware_JWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	signer := newRSASigner(t, "rsa-1")
+	server := newJWKSServer(t, signer)
+
+	store := NewMemoryKeyStore()
+	apiKey, _, err := store.Issue("ci", ScopeRead, "")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Middleware(
+		APIKeyAuthenticator{Store: store},
+		JWTAuthenticator{Keys: NewRemoteKeySet(server.URL), Issuer: testIssuer, Audience: testAudience},
+	))
+	router.GET("/me", func(c *gin.Context) {
+		c.JSON(http.StatusOK, PrincipalFrom(c))
+	})
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "JWT",
+			token:          signer.sign(t, validClaims()),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"user-123","name":"Alice","scope":"write","source":"jwt"}`,
+		},
+		{
+			name:           "API key 仍可使用",
+			token:          apiKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "無效的 JWT",
+			token:          signer.sign(t, map[string]interface{}{"iss": testIssuer, "sub": "x", "aud": testAudience, "exp": 1}),
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/unauthorized","code":"unauthorized","title":"Unauthorized","status":401,"detail":"invalid credentials: token expired","error":"invalid credentials: token expired"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_TenantClaim(t *testing.T) {
+	signer := newRSASigner(t, "rsa-1")
+	server := newJWKSServer(t, signer)
+
+	authenticator := JWTAuthenticator{Keys: NewRemoteKeySet(server.URL), Issuer: testIssuer, TenantClaim: "org"}
+
+	claims := validClaims()
+	claims["org"] = "acme"
+	p, err := authenticate(authenticator, signer.sign(t, claims))
+	require.NoError(t, err)
+	assert.Equal(t, "acme", p.Tenant)
+
+	authenticator.TenantClaim = ""
+	p, err = authenticate(authenticator, signer.sign(t, claims))
+	require.NoError(t, err)
+	assert.Empty(t, p.Tenant)
+}
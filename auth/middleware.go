@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+)
+
+var (
+	// ErrNoCredentials 請求沒有帶此 Authenticator 能處理的憑證
+	ErrNoCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials 憑證無效或已撤銷
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator 從請求中驗證呼叫者身分
+// 請求沒有帶可處理的憑證時回傳 ErrNoCredentials，讓下一個 Authenticator 嘗試
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// APIKeyAuthenticator 驗證 X-API-Key 或 Authorization: Bearer 帶入的 API key
+type APIKeyAuthenticator struct {
+	Store KeyStore
+}
+
+func (a APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	secret := r.Header.Get("X-API-Key")
+	if secret == "" {
+		token, ok := BearerToken(r)
+		if !ok || !strings.HasPrefix(token, KeyPrefix) {
+			return nil, ErrNoCredentials
+		}
+		secret = token
+	}
+
+	key, err := a.Store.Lookup(secret)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{ID: key.ID, Name: key.Name, Scope: key.Scope, Source: "api_key", Tenant: key.Tenant}, nil
+}
+
+// BearerToken 取出 Authorization: Bearer 的 token
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Middleware 依序以 authenticators 驗證請求，全部都沒有可用的憑證或驗證失敗時回傳 401
+func Middleware(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				unauthorized(c, err.Error())
+				return
+			}
+			setPrincipal(c, p)
+			c.Next()
+			return
+		}
+		unauthorized(c, "authentication required")
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="task-api"`)
+	problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, message))
+}
+
+// RequireScope 要求呼叫者至少擁有指定的 scope
+func RequireScope(required Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checkScope(c, required) {
+			c.Next()
+		}
+	}
+}
+
+// RequireGlobal 要求呼叫者不限定 tenant，用於會讀取或修改所有 tenant 資料的路由，需放在 RequireScope 之後
+func RequireGlobal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := PrincipalFrom(c)
+		if p == nil {
+			unauthorized(c, "authentication required")
+			return
+		}
+		if p.Tenant != "" {
+			problem.Abort(c, problem.New(http.StatusForbidden, "tenant_mismatch", "credentials limited to a tenant cannot access all tenants"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkScope 檢查呼叫者是否擁有 required，不足時中止請求並回傳 false
+func checkScope(c *gin.Context, required Scope) bool {
+	p := PrincipalFrom(c)
+	if p == nil {
+		unauthorized(c, "authentication required")
+		return false
+	}
+	if !p.Scope.Includes(required) {
+		problem.Abort(c, problem.New(http.StatusForbidden, "insufficient_scope", "insufficient scope: "+string(required)+" required"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := NewMemoryKeyStore()
+	readKey, _, err := store.Issue("reader", ScopeRead, "")
+	require.NoError(t, err)
+	writeKey, _, err := store.Issue("writer", ScopeWrite, "")
+	require.NoError(t, err)
+	adminKey, _, err := store.Issue("admin", ScopeAdmin, "")
+	require.NoError(t, err)
+
+	router := gin.New()
+	api := router.Group("/", Middleware(APIKeyAuthenticator{Store: store}))
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"principal": PrincipalFrom(c).Name})
+	}
+	api.GET("/tasks", RequireScope(ScopeRead), ok)
+	api.POST("/tasks", RequireScope(ScopeWrite), ok)
+	api.DELETE("/tasks", RequireScope(ScopeAdmin), ok)
+
+	tests := []struct {
+		name           string
+		method         string
+		header         string
+		value          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "沒有憑證",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/unauthorized","code":"unauthorized","title":"Unauthorized","status":401,"detail":"authentication required","error":"authentication required"}`,
+		},
+		{
+			name:           "無效的 key",
+			method:         http.MethodGet,
+			header:         "X-API-Key",
+			value:          "tk_invalid",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/unauthorized","code":"unauthorized","title":"Unauthorized","status":401,"detail":"invalid credentials","error":"invalid credentials"}`,
+		},
+		{
+			name:           "X-API-Key 讀取",
+			method:         http.MethodGet,
+			header:         "X-API-Key",
+			value:          readKey,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"principal":"reader"}`,
+		},
+		{
+			name:           "Bearer 寫入",
+			method:         http.MethodPost,
+			header:         "Authorization",
+			value:          "Bearer " + writeKey,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"principal":"writer"}`,
+		},
+		{
+			name:           "read scope 不能寫入",
+			method:         http.MethodPost,
+			header:         "X-API-Key",
+			value:          readKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/insufficient_scope","code":"insufficient_scope","title":"Forbidden","status":403,"detail":"insufficient scope: write required","error":"insufficient scope: write required"}`,
+		},
+		{
+			name:           "write scope 不能刪除全部",
+			method:         http.MethodDelete,
+			header:         "X-API-Key",
+			value:          writeKey,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/insufficient_scope","code":"insufficient_scope","title":"Forbidden","status":403,"detail":"insufficient scope: admin required","error":"insufficient scope: admin required"}`,
+		},
+		{
+			name:           "admin 可以刪除全部",
+			method:         http.MethodDelete,
+			header:         "Authorization",
+			value:          "Bearer " + adminKey,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"principal":"admin"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/tasks", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Package auth 提供請求的身分驗證與授權
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scope 權限範圍，admin 包含 write，write 包含 read
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid 回傳 scope 是否為已知的值
+func (s Scope) Valid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+// Includes 回傳 s 是否涵蓋 required
+func (s Scope) Includes(required Scope) bool {
+	return scopeLevels[s] >= scopeLevels[required]
+}
+
+// Principal 通過驗證的呼叫者
+type Principal struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Scope  Scope  `json:"scope"`
+	Source string `json:"source"`
+	// Tenant 呼叫者所屬的 tenant，空字串表示不限定
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// principalKey gin context 與 request context 共用的 key
+const principalKey = "auth.principal"
+
+type contextKey struct{}
+
+// WithPrincipal 將 principal 放入 context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext 從 context 取出 principal，未驗證時回傳 nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(contextKey{}).(*Principal)
+	return p
+}
+
+// PrincipalFrom 從 gin context 取出 principal，未驗證時回傳 nil
+func PrincipalFrom(c *gin.Context) *Principal {
+	if v, ok := c.Get(principalKey); ok {
+		if p, ok := v.(*Principal); ok {
+			return p
+		}
+	}
+	return FromContext(c.Request.Context())
+}
+
+// setPrincipal 同時寫入 gin context 與 request context，讓 storage 等下層也能取得
+func setPrincipal(c *gin.Context, p *Principal) {
+	c.Set(principalKey, p)
+	c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+}
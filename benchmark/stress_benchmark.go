@@ -2,9 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
@@ -14,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/gogolook/task-api/config"
 	"github.com/gogolook/task-api/model"
 	"github.com/gogolook/task-api/storage"
 )
@@ -50,7 +55,7 @@ func getConnectionCount() int {
 	count := 0
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
-		if strings.Contains(line, ":8080") && strings.Contains(line, "ESTABLISHED") {
+		if strings.Contains(line, ":"+serverPort()) && strings.Contains(line, "ESTABLISHED") {
 			count++
 		}
 	}
@@ -65,18 +70,77 @@ func getDetailedMemStats() string {
 		m.Alloc/1024, m.Sys/1024, m.NumGC, runtime.NumGoroutine())
 }
 
-// 可調整的並發參數
+// 可調整的並發參數，由 config 的 benchmark 區段載入（設定檔、TASK_API_BENCH_* 環境變數或 -benchmark.* 參數）
 var (
-	storageGoroutines  = 100000
-	httpConcurrency    = 1000
-	mixedReaders       = 70
-	mixedWriters       = 20
-	mixedListers       = 10
-	longRunningWorkers = 50
-	initialTaskCount   = 1000
-	mixedInitialTasks  = 1000
+	baseURL            string
+	apiKey             string
+	storageGoroutines  int
+	httpConcurrency    int
+	maxConcurrency     int
+	mixedReaders       int
+	mixedWriters       int
+	mixedListers       int
+	longRunningWorkers int
+	initialTaskCount   int
+	mixedInitialTasks  int
 )
 
+func loadConfig() {
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	bench := cfg.Benchmark
+	baseURL = strings.TrimRight(bench.BaseURL, "/")
+	apiKey = bench.APIKey.Value()
+	storageGoroutines = bench.StorageGoroutines
+	httpConcurrency = bench.HTTPConcurrency
+	maxConcurrency = bench.MaxConcurrency
+	mixedReaders = bench.MixedReaders
+	mixedWriters = bench.MixedWriters
+	mixedListers = bench.MixedListers
+	longRunningWorkers = bench.LongRunningWorkers
+	initialTaskCount = bench.InitialTaskCount
+	mixedInitialTasks = bench.MixedInitialTasks
+}
+
+// serverPort 回傳 baseURL 的 port，用於計算連線數
+func serverPort() string {
+	// baseURL 已由 config 驗證過
+	u, _ := url.Parse(baseURL)
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// apiKeyTransport 在每個請求帶上 benchmark.api_key（API 啟用驗證時需要）
+type apiKeyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func (t apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.base.RoundTrip(req)
+}
+
+func withAPIKey(base http.RoundTripper) http.RoundTripper {
+	if apiKey == "" {
+		return base
+	}
+	return apiKeyTransport{key: apiKey, base: base}
+}
+
 type TestResult struct {
 	TotalRequests    int64
 	SuccessRequests  int64
@@ -88,7 +152,9 @@ type TestResult struct {
 }
 
 func main() {
+	loadConfig()
 	fmt.Println("=== Task API 壓力測試 ===")
+	fmt.Printf("目標：%s\n", baseURL)
 	
 	// 1. 直接測試 Storage 層
 	fmt.Println("1. Storage 層壓力測試")
@@ -115,6 +181,9 @@ func testStorageStress() {
 	fmt.Printf("測試場景：%d 個 goroutine 同時對 Storage 進行讀寫\n", storageGoroutines)
 	
 	memStorage := storage.NewMemoryStorage()
+	// 超時後進行中的 storage 操作也會因 ctx 取消而盡早返回
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 	
 	// 準備一些初始資料
 	taskIDs := make([]string, initialTaskCount)
@@ -123,7 +192,7 @@ func testStorageStress() {
 			Name:   fmt.Sprintf("task-%d", i),
 			Status: i % 2,
 		}
-		memStorage.Create(task)
+		memStorage.Create(context.Background(), storage.Access{All: true}, task)
 		taskIDs[i] = task.ID
 	}
 	
@@ -143,7 +212,7 @@ func testStorageStress() {
 			// 隨機進行不同操作
 			switch id % 5 {
 			case 0, 1, 2: // 60% 讀取操作
-				_, err := memStorage.Get(taskIDs[id%len(taskIDs)])
+				_, err := memStorage.Get(ctx, storage.Access{All: true}, taskIDs[id%len(taskIDs)])
 				if err != nil {
 					atomic.AddInt64(&result.FailedRequests, 1)
 				} else {
@@ -155,7 +224,7 @@ func testStorageStress() {
 					Name:   fmt.Sprintf("new-task-%d", id),
 					Status: 0,
 				}
-				err := memStorage.Create(task)
+				err := memStorage.Create(ctx, storage.Access{All: true}, task)
 				if err != nil {
 					atomic.AddInt64(&result.FailedRequests, 1)
 				} else {
@@ -163,7 +232,7 @@ func testStorageStress() {
 				}
 				
 			case 4: // 20% 列表操作
-				listResult, err := memStorage.List(storage.NewPaginationParams(1))
+				listResult, err := memStorage.List(ctx, storage.Access{All: true}, storage.NewPaginationParams(1))
 				if err == nil && listResult.Pagination.Total >= 0 {
 					atomic.AddInt64(&result.SuccessRequests, 1)
 				} else {
@@ -205,7 +274,7 @@ func testStorageStress() {
 		fmt.Printf("   最大時間: %v\n", result.MaxTime)
 		fmt.Printf("   最小時間: %v\n", result.MinTime)
 		
-	case <-time.After(30 * time.Second):
+	case <-ctx.Done():
 		fmt.Printf("❌ Storage 測試超時 (30秒)!\n")
 		fmt.Printf("   這表示沒有超時保護可能導致卡死\n")
 		fmt.Printf("   已完成請求: %d/%d\n", result.SuccessRequests+result.FailedRequests, result.TotalRequests)
@@ -217,7 +286,6 @@ func testHTTPStress() {
 	fmt.Printf("測試場景：%d 個並發 HTTP 請求\n", httpConcurrency)
 	
 	// 檢查 API 是否運行
-	baseURL := "http://localhost:8080"
 	testClient := &http.Client{Timeout: 1 * time.Second}
 	_, err := testClient.Get(baseURL + "/health")
 	if err != nil {
@@ -237,7 +305,7 @@ func testHTTPStress() {
 	}
 	client := &http.Client{
 		Timeout:   10 * time.Second, // 10秒超時
-		Transport: transport,
+		Transport: withAPIKey(transport),
 	}
 	
 	startTime := time.Now()
@@ -260,7 +328,7 @@ func testHTTPStress() {
 				resp, err = client.Get(baseURL + "/tasks")
 				
 			case 2: // 25% POST 請求
-				task := model.Task{
+				task := model.TaskRequest{
 					Name:   fmt.Sprintf("http-task-%d", id),
 					Status: 0,
 				}
@@ -336,7 +404,6 @@ func testHTTPProgressiveStress() {
 	fmt.Println("測試場景：逐步增加並發數直到通過率低於 95%")
 	
 	// 檢查 API 是否運行
-	baseURL := "http://localhost:8080"
 	testClient := &http.Client{Timeout: 1 * time.Second}
 	_, err := testClient.Get(baseURL + "/health")
 	if err != nil {
@@ -362,7 +429,7 @@ func testHTTPProgressiveStress() {
 	}
 	client := &http.Client{
 		Timeout:   10 * time.Second,
-		Transport: transport,
+		Transport: withAPIKey(transport),
 	}
 	
 	fmt.Println("開始漸進式測試...")
@@ -370,7 +437,7 @@ func testHTTPProgressiveStress() {
 	fmt.Println("---------------------------------------------------------------")
 	
 	// 從 100 開始，每次增加 100，直到成功率低於 95%
-	for concurrency := 100; concurrency <= 5000; concurrency += 100 {
+	for concurrency := 100; concurrency <= maxConcurrency; concurrency += 100 {
 		result := runHTTPTest(client, baseURL, concurrency)
 		
 		successRate := float64(result.SuccessRequests) / float64(result.TotalRequests) * 100
@@ -448,7 +515,7 @@ func runHTTPTest(client *http.Client, baseURL string, concurrency int) TestResul
 			case 0: // 50% GET 請求
 				resp, err = client.Get(baseURL + "/tasks")
 			case 1: // 33% POST 請求
-				task := model.Task{
+				task := model.TaskRequest{
 					Name:   fmt.Sprintf("test-task-%d", id),
 					Status: 0,
 				}
@@ -503,6 +570,8 @@ func testMixedStress() {
 	fmt.Println("測試場景：混合讀寫操作，模擬真實使用情境")
 	
 	memStorage := storage.NewMemoryStorage()
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
 	
 	// 初始化一些資料
 	taskIDs := make([]string, mixedInitialTasks)
@@ -511,7 +580,7 @@ func testMixedStress() {
 			Name:   fmt.Sprintf("initial-task-%d", i),
 			Status: 0,
 		}
-		memStorage.Create(task)
+		memStorage.Create(context.Background(), storage.Access{All: true}, task)
 		taskIDs[i] = task.ID
 	}
 	
@@ -527,7 +596,7 @@ func testMixedStress() {
 		go func() {
 			defer wg.Done()
 			for j := 0; j < 100; j++ {
-				_, err := memStorage.Get(taskIDs[j%len(taskIDs)])
+				_, err := memStorage.Get(ctx, storage.Access{All: true}, taskIDs[j%len(taskIDs)])
 				atomic.AddInt64(&operations, 1)
 				if err != nil {
 					atomic.AddInt64(&errors, 1)
@@ -547,7 +616,7 @@ func testMixedStress() {
 					Name:   fmt.Sprintf("writer-%d-task-%d", id, j),
 					Status: 0,
 				}
-				err := memStorage.Create(task)
+				err := memStorage.Create(ctx, storage.Access{All: true}, task)
 				atomic.AddInt64(&operations, 1)
 				if err != nil {
 					atomic.AddInt64(&errors, 1)
@@ -563,7 +632,7 @@ func testMixedStress() {
 		go func() {
 			defer wg.Done()
 			for j := 0; j < 20; j++ {
-				listResult, err := memStorage.List(storage.NewPaginationParams(1))
+				listResult, err := memStorage.List(ctx, storage.Access{All: true}, storage.NewPaginationParams(1))
 				atomic.AddInt64(&operations, 1)
 				if err != nil || listResult.Pagination.Total < 0 {
 					atomic.AddInt64(&errors, 1)
@@ -610,7 +679,7 @@ func testMixedStress() {
 		fmt.Printf("   總時間: %v\n", totalTime)
 		fmt.Printf("   吞吐量: %.2f ops/sec\n", float64(finalOps)/totalTime.Seconds())
 		
-	case <-time.After(45 * time.Second):
+	case <-ctx.Done():
 		finalOps := atomic.LoadInt64(&operations)
 		finalErrs := atomic.LoadInt64(&errors)
 		
@@ -650,7 +719,7 @@ func testLongRunningStress() {
 						Status: 0,
 					}
 					
-					err := memStorage.Create(task)
+					err := memStorage.Create(context.Background(), storage.Access{All: true}, task)
 					atomic.AddInt64(&totalOps, 1)
 					if err != nil {
 						atomic.AddInt64(&totalErrs, 1)
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultRetryBackoff = 200 * time.Millisecond
+)
+
+// Client Task API 的 HTTP 客戶端
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+	header       http.Header
+}
+
+// Option 設定 Client 的選項
+type Option func(*Client)
+
+// WithHTTPClient 使用自訂的 http.Client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout 設定單次請求的逾時時間
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries 設定冪等請求的最大重試次數與重試間隔（每次重試間隔加倍）
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithHeader 在每個請求上加上指定的 header
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// New 建立指向 baseURL 的 Client
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL: %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:      u,
+		httpClient:   &http.Client{},
+		timeout:      defaultTimeout,
+		retryBackoff: defaultRetryBackoff,
+		header:       make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// do 發送請求並將回應解析到 out，遇到可重試的錯誤時依設定重試
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	return c.doWithHeader(ctx, method, path, nil, query, in, out)
+}
+
+// doWithHeader 與 do 相同，另外在這個請求加上 header
+func (c *Client) doWithHeader(ctx context.Context, method, path string, header http.Header, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts += c.maxRetries
+	}
+
+	backoff := c.retryBackoff
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := c.send(ctx, method, path, header, query, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+// send 發送單次請求，回傳錯誤是否值得重試
+func (c *Client) send(ctx context.Context, method, path string, header http.Header, query url.Values, body []byte, out interface{}) (bool, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := c.newRequest(ctx, method, path, header, query, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// 網路錯誤與單次逾時可重試，呼叫端取消則不重試
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := newAPIError(resp.StatusCode, data)
+		return isRetryableStatus(resp.StatusCode), apiErr
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return false, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// newRequest 建立指向 baseURL 下 path 的請求，並加上 Client 與這個請求的 header
+func (c *Client) newRequest(ctx context.Context, method, path string, header http.Header, query url.Values, body []byte) (*http.Request, error) {
+	// path 中的參數已由呼叫端以 url.PathEscape 編碼，同時設定 RawPath 才不會被再次編碼
+	rawPath := c.baseURL.EscapedPath() + path
+	unescaped, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	u := *c.baseURL
+	u.Path, u.RawPath = unescaped, rawPath
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// newAPIError 解析 problem details 錯誤回應，相容只有 error 欄位的舊格式
+func newAPIError(code int, data []byte) *APIError {
+	var body model.ErrorResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		return &APIError{StatusCode: code, Message: strings.TrimSpace(string(data))}
+	}
+	message := body.Detail
+	if message == "" {
+		message = body.Error
+	}
+	if message == "" {
+		message = strings.TrimSpace(string(data))
+	}
+	return &APIError{StatusCode: code, Code: body.Code, Message: message, Errors: body.Errors}
+}
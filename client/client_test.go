@@ -0,0 +1,258 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/task"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer 啟動掛載真實 handler 的測試伺服器
+func newTestServer(t *testing.T) (*Client, *storage.MemoryStorage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	r := gin.New()
+	taskHandler := task.NewTaskHandler(memStorage)
+	taskHandler.RegisterRoutes(r)
+	taskHandler.RegisterAdminRoutes(r)
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL)
+	require.NoError(t, err)
+	return c, memStorage
+}
+
+func TestClient_CRUD(t *testing.T) {
+	c, _ := newTestServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Health(ctx))
+
+	created, err := c.CreateTask(ctx, model.TaskRequest{Name: "Learn Go", Status: 0})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Learn Go", created.Name)
+
+	got, err := c.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	updated, err := c.UpdateTask(ctx, created.ID, model.TaskRequest{Name: "Learn Go", Status: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Status)
+
+	list, err := c.ListTasks(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, list.Pagination.Total)
+
+	require.NoError(t, c.DeleteTask(ctx, created.ID))
+
+	_, err = c.GetTask(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	// 刪除的任務可從回收桶還原，永久刪除後就無法還原
+	trash, err := c.ListTrash(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, trash.Data, 1)
+	assert.NotNil(t, trash.Data[0].DeletedAt)
+	got, err = c.RestoreTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, updated, got)
+	require.NoError(t, c.PurgeTask(ctx, created.ID))
+	_, err = c.RestoreTask(ctx, created.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.CreateTask(ctx, model.TaskRequest{Name: "Another", Status: 1})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteAllTasks(ctx))
+
+	list, err = c.ListTasks(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, list.Pagination.Total)
+
+	restored, err := c.RestoreDeletedTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+}
+
+func TestClient_Errors(t *testing.T) {
+	c, _ := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := c.CreateTask(ctx, model.TaskRequest{Name: "   ", Status: 0})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "validation_failed", apiErr.Code)
+	assert.Equal(t, "name cannot be empty", apiErr.Message)
+	assert.Equal(t, []model.FieldError{{Field: "name", Code: "blank", Message: "name cannot be empty"}}, apiErr.Errors)
+
+	err = c.DeleteTask(ctx, "non-existent")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = c.UpdateTask(ctx, "non-existent", model.TaskRequest{Name: "x", Status: 0})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_Shares(t *testing.T) {
+	c, _ := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := c.CreateTask(ctx, model.TaskRequest{Name: "Shared", Status: 0})
+	require.NoError(t, err)
+
+	share, err := c.ShareTask(ctx, created.ID, "bob", model.PermissionRead)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Share{UserID: "bob", Permission: model.PermissionRead}, share)
+
+	shares, err := c.ListShares(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Share{*share}, shares)
+
+	require.NoError(t, c.UnshareTask(ctx, created.ID, "bob"))
+	assert.ErrorIs(t, c.UnshareTask(ctx, created.ID, "bob"), ErrNotFound)
+
+	_, err = c.ShareTask(ctx, created.ID, "bob", model.Permission("owner"))
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestClient_PageIterator(t *testing.T) {
+	c, memStorage := newTestServer(t)
+	ctx := context.Background()
+
+	for i := 0; i < 250; i++ {
+		require.NoError(t, memStorage.Create(context.Background(), storage.Access{All: true}, &model.Task{Name: fmt.Sprintf("Task %d", i)}))
+	}
+
+	it := c.Pages(1)
+	pages, count := 0, 0
+	for it.Next(ctx) {
+		pages++
+		count += len(it.Page().Data)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, 250, count)
+}
+
+func TestClient_ExportTasks(t *testing.T) {
+	c, memStorage := newTestServer(t)
+	ctx := context.Background()
+
+	task := &model.Task{Name: "Learn Go", Status: 1}
+	require.NoError(t, memStorage.Create(ctx, storage.Access{All: true}, task))
+
+	body, err := c.ExportTasks(ctx, "csv")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, "id,name,status,due\n"+task.ID+",Learn Go,1,\n", string(data))
+
+	_, err = c.ExportTasks(ctx, "xml")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"1","name":"Task","status":0}`)
+	}))
+	defer server.Close()
+
+	t.Run("冪等請求會重試", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		c, err := New(server.URL, WithRetries(3, time.Millisecond))
+		require.NoError(t, err)
+
+		got, err := c.GetTask(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Task", got.Name)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("POST 不重試", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		c, err := New(server.URL, WithRetries(3, time.Millisecond))
+		require.NoError(t, err)
+
+		_, err = c.CreateTask(context.Background(), model.TaskRequest{Name: "Task"})
+		assert.ErrorIs(t, err, ErrServer)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.GetTask(context.Background(), "1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClient_EscapesPath(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"a b/c","name":"Task","status":0}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{name: "ID 中的空白與斜線只編碼一次", baseURL: server.URL, expected: "/tasks/a%20b%2Fc"},
+		{name: "base URL 帶有路徑", baseURL: server.URL + "/api%20v1/", expected: "/api%20v1/tasks/a%20b%2Fc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths = nil
+			c, err := New(tt.baseURL)
+			require.NoError(t, err)
+
+			_, err = c.GetTask(context.Background(), "a b/c")
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.expected}, paths)
+		})
+	}
+}
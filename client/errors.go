@@ -0,0 +1,46 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+)
+
+var (
+	// ErrBadRequest 請求資料驗證失敗（400）
+	ErrBadRequest = errors.New("bad request")
+	// ErrNotFound 任務不存在（404），與 storage.ErrTaskNotFound 相同
+	ErrNotFound = storage.ErrTaskNotFound
+	// ErrServer 伺服器內部錯誤（5xx）
+	ErrServer = errors.New("server error")
+)
+
+// APIError 伺服器回傳的錯誤，可透過 errors.Is 比對 ErrBadRequest、ErrNotFound、ErrServer
+type APIError struct {
+	StatusCode int
+	// Code 伺服器回傳的錯誤代碼，例如 validation_failed、task_not_found；舊版伺服器為空字串
+	Code    string
+	Message string
+	// Errors 驗證失敗的欄位
+	Errors []model.FieldError
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("task api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Unwrap 將 HTTP 狀態碼對應到對應的 sentinel error
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
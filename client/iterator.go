@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+
+	"github.com/gogolook/task-api/storage"
+)
+
+// PageIterator 依 PaginationInfo.HasNext 自動逐頁取得任務
+//
+//	it := c.Pages(1)
+//	for it.Next(ctx) {
+//		for _, task := range it.Page().Data { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type PageIterator struct {
+	client *Client
+	next   int
+	page   *storage.PaginationResult
+	err    error
+	done   bool
+}
+
+// Pages 建立從 startPage 開始的分頁迭代器
+func (c *Client) Pages(startPage int) *PageIterator {
+	if startPage < 1 {
+		startPage = 1
+	}
+	return &PageIterator{client: c, next: startPage}
+}
+
+// Next 取得下一頁，沒有更多資料或發生錯誤時回傳 false
+func (it *PageIterator) Next(ctx context.Context) bool {
+	if it.done {
+		return false
+	}
+
+	page, err := it.client.ListTasks(ctx, it.next)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+
+	it.page = page
+	it.next++
+	if !page.Pagination.HasNext {
+		it.done = true
+	}
+	return true
+}
+
+// Page 回傳目前這一頁的結果
+func (it *PageIterator) Page() *storage.PaginationResult {
+	return it.page
+}
+
+// Err 回傳迭代過程中發生的錯誤
+func (it *PageIterator) Err() error {
+	return it.err
+}
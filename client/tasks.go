@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+)
+
+// ListTasks 取得指定頁的任務清單
+func (c *Client) ListTasks(ctx context.Context, page int) (*storage.PaginationResult, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+
+	var result storage.PaginationResult
+	if err := c.do(ctx, http.MethodGet, "/tasks", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTask 取得單一任務
+func (c *Client) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	var task model.Task
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+url.PathEscape(id), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask 建立新任務
+func (c *Client) CreateTask(ctx context.Context, req model.TaskRequest) (*model.Task, error) {
+	var task model.Task
+	if err := c.do(ctx, http.MethodPost, "/tasks", nil, req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask 更新指定任務
+func (c *Client) UpdateTask(ctx context.Context, id string, req model.TaskRequest) (*model.Task, error) {
+	var task model.Task
+	if err := c.do(ctx, http.MethodPut, "/tasks/"+url.PathEscape(id), nil, req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask 將指定任務移到回收桶
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/tasks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// PurgeTask 永久刪除指定任務，任務可以在回收桶中
+func (c *Client) PurgeTask(ctx context.Context, id string) error {
+	query := url.Values{}
+	query.Set("hard", "true")
+	return c.do(ctx, http.MethodDelete, "/tasks/"+url.PathEscape(id), query, nil, nil)
+}
+
+// ListTrash 取得指定頁的回收桶任務清單
+func (c *Client) ListTrash(ctx context.Context, page int) (*storage.PaginationResult, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+
+	var result storage.PaginationResult
+	if err := c.do(ctx, http.MethodGet, "/tasks/trash", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RestoreTask 將任務移出回收桶
+func (c *Client) RestoreTask(ctx context.Context, id string) (*model.Task, error) {
+	var task model.Task
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+url.PathEscape(id)+"/restore", nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteAllTasks 刪除所有任務，需要 admin 權限，伺服器未開啟 server.allow_wipe 時不提供
+func (c *Client) DeleteAllTasks(ctx context.Context) error {
+	header := http.Header{model.ConfirmHeader: {model.ConfirmDeleteAll}}
+	return c.doWithHeader(ctx, http.MethodDelete, "/admin/tasks", header, nil, nil, nil)
+}
+
+// RestoreDeletedTasks 加回最近一次 DeleteAllTasks 刪除的任務，回傳加回的數量
+func (c *Client) RestoreDeletedTasks(ctx context.Context) (int, error) {
+	var resp model.RestoreResponse
+	if err := c.do(ctx, http.MethodPost, "/admin/tasks/restore", nil, nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Restored, nil
+}
+
+// ListShares 列出任務的分享設定（只有擁有者可以查看）
+func (c *Client) ListShares(ctx context.Context, id string) ([]model.Share, error) {
+	var shares []model.Share
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+url.PathEscape(id)+"/shares", nil, nil, &shares); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// ShareTask 將任務分享給其他使用者，已分享時更新權限
+func (c *Client) ShareTask(ctx context.Context, id, userID string, permission model.Permission) (*model.Share, error) {
+	var share model.Share
+	path := "/tasks/" + url.PathEscape(id) + "/shares/" + url.PathEscape(userID)
+	if err := c.do(ctx, http.MethodPut, path, nil, model.ShareRequest{Permission: permission}, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// UnshareTask 取消分享
+func (c *Client) UnshareTask(ctx context.Context, id, userID string) error {
+	path := "/tasks/" + url.PathEscape(id) + "/shares/" + url.PathEscape(userID)
+	return c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// ExportTasks 以 format（csv 或 jsonl）串流匯出所有任務，呼叫端讀完後需關閉回傳的 body
+// 逾時只限制收到回應 header 前的時間，讀取大量任務時不會被中斷；串流開始後無法重送，因此不重試
+func (c *Client) ExportTasks(ctx context.Context, format string) (io.ReadCloser, error) {
+	query := url.Values{}
+	query.Set("format", format)
+
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := c.newRequest(ctx, http.MethodGet, "/tasks/export", nil, query, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	var timer *time.Timer
+	if c.timeout > 0 {
+		timer = time.AfterFunc(c.timeout, cancel)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if timer != nil && !timer.Stop() {
+		// 收到 header 時剛好逾時，body 已無法讀取
+		resp.Body.Close()
+		cancel()
+		return nil, context.DeadlineExceeded
+	}
+
+	if resp.StatusCode >= 400 {
+		defer cancel()
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, newAPIError(resp.StatusCode, data)
+	}
+	return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelOnClose 關閉 body 時一併釋放請求的 context
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// Health 呼叫健康檢查 endpoint
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
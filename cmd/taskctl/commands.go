@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+)
+
+// command 子命令定義
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string, stderr io.Writer) error
+}
+
+var commandOrder = []string{"list", "get", "add", "done", "undo", "edit", "rm", "export"}
+
+var commands = map[string]command{
+	"list":   {summary: "list tasks", run: runList},
+	"get":    {summary: "show one or more tasks by ID", run: runGet},
+	"add":    {summary: "create a task", run: runAdd},
+	"done":   {summary: "mark tasks as completed", run: runSetStatus(1)},
+	"undo":   {summary: "mark tasks as incomplete", run: runSetStatus(0)},
+	"edit":   {summary: "change a task's name or status", run: runEdit},
+	"rm":     {summary: "move tasks to the trash by ID", run: runRemove},
+	"export": {summary: "export every task as json, jsonl or csv", run: runExport},
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("taskctl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseArgs 解析參數，允許 flag 出現在位置參數之後（例如 edit <id> -name x）
+func parseArgs(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return fs.Parse(append([]string{"--"}, positional...))
+}
+
+func parseDue(value string) (*time.Time, error) {
+	due, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: -due must be an RFC 3339 timestamp", errUsage)
+	}
+	return &due, nil
+}
+
+func requireArgs(fs *flag.FlagSet, min int, what string) error {
+	if fs.NArg() < min {
+		return fmt.Errorf("%w: missing %s", errUsage, what)
+	}
+	return nil
+}
+
+func runList(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("list", stderr)
+	page := fs.Int("page", 1, "page number to fetch")
+	all := fs.Bool("all", false, "fetch every page")
+	status := fs.Int("status", -1, "only show tasks with this status (0 or 1); searches every page")
+	contains := fs.String("name", "", "only show tasks whose name contains this text; searches every page")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	// 伺服器不支援篩選，篩選時需取得所有頁面才不會漏掉其他頁的任務
+	filtered := *status >= 0 || *contains != ""
+	if filtered && *page != 1 {
+		return fmt.Errorf("%w: -page cannot be combined with -status or -name", errUsage)
+	}
+
+	match := func(t model.Task) bool {
+		if *status >= 0 && t.Status != *status {
+			return false
+		}
+		return *contains == "" || strings.Contains(strings.ToLower(t.Name), strings.ToLower(*contains))
+	}
+
+	var tasks []model.Task
+	if *all || filtered {
+		it := a.client.Pages(1)
+		for it.Next(ctx) {
+			tasks = appendMatching(tasks, it.Page().Data, match)
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	} else {
+		result, err := a.client.ListTasks(ctx, *page)
+		if err != nil {
+			return err
+		}
+		tasks = appendMatching(tasks, result.Data, match)
+	}
+
+	return a.printTasks(tasks)
+}
+
+func appendMatching(dst, src []model.Task, match func(model.Task) bool) []model.Task {
+	for _, t := range src {
+		if match(t) {
+			dst = append(dst, t)
+		}
+	}
+	return dst
+}
+
+func runGet(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("get", stderr)
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "task ID"); err != nil {
+		return err
+	}
+
+	tasks := make([]model.Task, 0, fs.NArg())
+	for _, id := range fs.Args() {
+		task, err := a.client.GetTask(ctx, id)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, *task)
+	}
+	return a.printTasks(tasks)
+}
+
+func runAdd(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("add", stderr)
+	status := fs.Int("status", 0, "initial status (0 or 1)")
+	due := fs.String("due", "", "due time (RFC 3339)")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "task name"); err != nil {
+		return err
+	}
+
+	req := model.TaskRequest{
+		Name:   strings.Join(fs.Args(), " "),
+		Status: *status,
+	}
+	if *due != "" {
+		parsed, err := parseDue(*due)
+		if err != nil {
+			return err
+		}
+		req.Due = parsed
+	}
+
+	task, err := a.client.CreateTask(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.printTasks([]model.Task{*task})
+}
+
+// runSetStatus 產生將任務設為指定狀態的子命令（done / undo）
+func runSetStatus(status int) func(context.Context, *app, []string, io.Writer) error {
+	return func(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+		name := "done"
+		if status == 0 {
+			name = "undo"
+		}
+		fs := newFlagSet(name, stderr)
+		if err := parseArgs(fs, args); err != nil {
+			return err
+		}
+		if err := requireArgs(fs, 1, "task ID"); err != nil {
+			return err
+		}
+
+		tasks := make([]model.Task, 0, fs.NArg())
+		for _, id := range fs.Args() {
+			task, err := a.client.GetTask(ctx, id)
+			if err != nil {
+				return err
+			}
+			updated, err := a.client.UpdateTask(ctx, id, model.TaskRequest{Name: task.Name, Status: status, Due: task.Due})
+			if err != nil {
+				return err
+			}
+			tasks = append(tasks, *updated)
+		}
+		return a.printTasks(tasks)
+	}
+}
+
+func runEdit(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("edit", stderr)
+	name := fs.String("name", "", "new task name")
+	status := fs.Int("status", -1, "new status (0 or 1)")
+	due := fs.String("due", "", "new due time (RFC 3339)")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "task ID"); err != nil {
+		return err
+	}
+	if *name == "" && *status < 0 && *due == "" {
+		return fmt.Errorf("%w: nothing to change, pass -name, -status or -due", errUsage)
+	}
+
+	id := fs.Arg(0)
+	task, err := a.client.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	req := model.TaskRequest{Name: task.Name, Status: task.Status, Due: task.Due}
+	if *due != "" {
+		parsed, err := parseDue(*due)
+		if err != nil {
+			return err
+		}
+		req.Due = parsed
+	}
+	if *name != "" {
+		req.Name = *name
+	}
+	if *status >= 0 {
+		req.Status = *status
+	}
+
+	updated, err := a.client.UpdateTask(ctx, id, req)
+	if err != nil {
+		return err
+	}
+	return a.printTasks([]model.Task{*updated})
+}
+
+func runRemove(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("rm", stderr)
+	hard := fs.Bool("hard", false, "delete permanently instead of moving to the trash")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := requireArgs(fs, 1, "task ID"); err != nil {
+		return err
+	}
+
+	for _, id := range fs.Args() {
+		remove := a.client.DeleteTask
+		if *hard {
+			remove = a.client.PurgeTask
+		}
+		if err := remove(ctx, id); err != nil {
+			return err
+		}
+		if a.output == outputID {
+			fmt.Fprintln(a.stdout, id)
+		}
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, a *app, args []string, stderr io.Writer) error {
+	fs := newFlagSet("export", stderr)
+	format := fs.String("format", "jsonl", "export format: json, jsonl or csv")
+	out := fs.String("o", "", "write to file instead of stdout")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	// 伺服器只串流 csv 與 JSON Lines，json 由 JSON Lines 逐筆轉成陣列
+	serverFormat := *format
+	switch *format {
+	case "json":
+		serverFormat = "jsonl"
+	case "jsonl", "csv":
+	default:
+		return fmt.Errorf("%w: unknown export format %q", errUsage, *format)
+	}
+
+	body, err := a.client.ExportTasks(ctx, serverFormat)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if *out == "" {
+		return copyExport(a.stdout, body, *format)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	err = copyExport(f, body, *format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copyExport 將伺服器匯出的串流寫到 w，json 格式逐筆轉成與 -output json 相同的縮排陣列，不需把所有任務讀進記憶體
+func copyExport(w io.Writer, body io.Reader, format string) error {
+	if format != "json" {
+		_, err := io.Copy(w, body)
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	dec := json.NewDecoder(body)
+	n := 0
+	for {
+		var t model.Task
+		if err := dec.Decode(&t); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("decode export: %w", err)
+		}
+		data, err := json.MarshalIndent(t, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			bw.WriteString("[\n  ")
+		} else {
+			bw.WriteString(",\n  ")
+		}
+		bw.Write(data)
+		n++
+	}
+	if n == 0 {
+		bw.WriteString("[]\n")
+	} else {
+		bw.WriteString("\n]\n")
+	}
+	return bw.Flush()
+}
+
+func writeJSON(w io.Writer, tasks []model.Task) error {
+	if tasks == nil {
+		tasks = []model.Task{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tasks)
+}
@@ -0,0 +1,129 @@
+// taskctl 是 Task API 的命令列客戶端
+//
+//	taskctl [global flags] <command> [command flags] [args]
+//
+// Global flags:
+//
+//	-server   API 位址（預設 $TASKCTL_SERVER 或 http://localhost:8080）
+//	-token    認證用的 Bearer token（預設 $TASKCTL_TOKEN）
+//	-output   輸出格式：table、json、id（預設 table）
+//	-timeout  單次請求逾時時間
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gogolook/task-api/client"
+)
+
+// 結束代碼
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitValidation = 4
+)
+
+const defaultServer = "http://localhost:8080"
+
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// app 保存單次執行所需的狀態
+type app struct {
+	client *client.Client
+	output string
+	stdout io.Writer
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("taskctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	server := global.String("server", envOr("TASKCTL_SERVER", defaultServer), "Task API server URL")
+	token := global.String("token", os.Getenv("TASKCTL_TOKEN"), "bearer token used for authentication")
+	output := global.String("output", "table", "output format: table, json or id")
+	timeout := global.Duration("timeout", 10*time.Second, "per-request timeout")
+	global.Usage = func() { usage(stderr, global) }
+
+	if err := global.Parse(args); err != nil {
+		return exitUsage
+	}
+	if global.NArg() == 0 {
+		usage(stderr, global)
+		return exitUsage
+	}
+
+	switch *output {
+	case outputTable, outputJSON, outputID:
+	default:
+		fmt.Fprintf(stderr, "taskctl: unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	opts := []client.Option{client.WithTimeout(*timeout), client.WithRetries(2, 200*time.Millisecond)}
+	if *token != "" {
+		opts = append(opts, client.WithHeader("Authorization", "Bearer "+*token))
+	}
+	c, err := client.New(*server, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "taskctl: %v\n", err)
+		return exitUsage
+	}
+
+	a := &app{client: c, output: *output, stdout: stdout}
+
+	name, cmdArgs := global.Arg(0), global.Args()[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "taskctl: unknown command %q\n", name)
+		usage(stderr, global)
+		return exitUsage
+	}
+
+	if err := cmd.run(ctx, a, cmdArgs, stderr); err != nil {
+		fmt.Fprintf(stderr, "taskctl %s: %v\n", name, err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// exitCode 依錯誤種類決定結束代碼
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return exitUsage
+	case errors.Is(err, client.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, client.ErrBadRequest):
+		return exitValidation
+	}
+	return exitError
+}
+
+func usage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: taskctl [global flags] <command> [command flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nGlobal flags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nExit codes: 0 ok, 1 error, 2 usage, 3 not found, 4 validation error")
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/task"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *storage.MemoryStorage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	r := gin.New()
+	task.NewTaskHandler(memStorage).RegisterRoutes(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server, memStorage
+}
+
+func runCLI(server *httptest.Server, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-server", server.URL}, args...)
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestTaskctl(t *testing.T) {
+	server, memStorage := newTestServer(t)
+
+	code, out, _ := runCLI(server, "-output", "id", "add", "Learn", "Go")
+	require.Equal(t, exitOK, code)
+	id := strings.TrimSpace(out)
+	require.NotEmpty(t, id)
+
+	code, _, _ = runCLI(server, "done", id)
+	require.Equal(t, exitOK, code)
+	got, err := memStorage.Get(context.Background(), storage.Access{All: true}, id)
+	require.NoError(t, err)
+	assert.Equal(t, model.Task{ID: id, Name: "Learn Go", Status: 1}, *got)
+
+	code, _, _ = runCLI(server, "edit", id, "-name", "Learn Rust", "-due", "2026-10-19T09:00:00Z")
+	require.Equal(t, exitOK, code)
+
+	code, out, _ = runCLI(server, "list", "-status", "1")
+	require.Equal(t, exitOK, code)
+	assert.Contains(t, out, "Learn Rust")
+	assert.Contains(t, out, "done")
+
+	code, out, _ = runCLI(server, "-output", "json", "get", id)
+	require.Equal(t, exitOK, code)
+	assert.JSONEq(t, `[{"id":"`+id+`","name":"Learn Rust","status":1,"due":"2026-10-19T09:00:00Z"}]`, out)
+
+	code, out, _ = runCLI(server, "export", "-format", "csv")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, "id,name,status,due\n"+id+",Learn Rust,1,2026-10-19T09:00:00Z\n", out)
+
+	code, _, _ = runCLI(server, "rm", id)
+	require.Equal(t, exitOK, code)
+
+	code, out, _ = runCLI(server, "list", "-all")
+	require.Equal(t, exitOK, code)
+	assert.NotContains(t, out, id)
+
+	// 已在回收桶中的任務仍可永久刪除
+	code, _, _ = runCLI(server, "rm", "-hard", id)
+	require.Equal(t, exitOK, code)
+	code, _, _ = runCLI(server, "rm", "-hard", id)
+	assert.Equal(t, exitNotFound, code)
+}
+
+func TestTaskctl_ListFilter(t *testing.T) {
+	server, memStorage := newTestServer(t)
+
+	// 符合條件的任務在第二頁
+	ctx := context.Background()
+	for i := 0; i < storage.NewPaginationParams(1).Limit; i++ {
+		require.NoError(t, memStorage.Create(ctx, storage.Access{All: true}, &model.Task{Name: "Filler"}))
+	}
+	require.NoError(t, memStorage.Create(ctx, storage.Access{All: true}, &model.Task{Name: "Learn Go", Status: 1}))
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "依狀態篩選", args: []string{"list", "-status", "1"}},
+		{name: "依名稱篩選", args: []string{"list", "-name", "learn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, _ := runCLI(server, tt.args...)
+			require.Equal(t, exitOK, code)
+			assert.Contains(t, out, "Learn Go")
+			assert.NotContains(t, out, "Filler")
+		})
+	}
+}
+
+func TestTaskctl_Export(t *testing.T) {
+	server, memStorage := newTestServer(t)
+
+	ctx := context.Background()
+	first := &model.Task{Name: "Learn Go", Status: 1}
+	second := &model.Task{Name: "Learn Rust"}
+	require.NoError(t, memStorage.Create(ctx, storage.Access{All: true}, first))
+	require.NoError(t, memStorage.Create(ctx, storage.Access{All: true}, second))
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "JSON Lines",
+			format:   "jsonl",
+			expected: `{"id":"` + first.ID + `","name":"Learn Go","status":1}` + "\n" + `{"id":"` + second.ID + `","name":"Learn Rust","status":0}` + "\n",
+		},
+		{
+			name:     "CSV",
+			format:   "csv",
+			expected: "id,name,status,due\n" + first.ID + ",Learn Go,1,\n" + second.ID + ",Learn Rust,0,\n",
+		},
+		{
+			name:     "JSON 陣列與 -output json 格式相同",
+			format:   "json",
+			expected: "[\n  {\n    \"id\": \"" + first.ID + "\",\n    \"name\": \"Learn Go\",\n    \"status\": 1\n  },\n  {\n    \"id\": \"" + second.ID + "\",\n    \"name\": \"Learn Rust\",\n    \"status\": 0\n  }\n]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out, _ := runCLI(server, "export", "-format", tt.format)
+			require.Equal(t, exitOK, code)
+			assert.Equal(t, tt.expected, out)
+
+			path := filepath.Join(t.TempDir(), "tasks."+tt.format)
+			code, out, _ = runCLI(server, "export", "-format", tt.format, "-o", path)
+			require.Equal(t, exitOK, code)
+			assert.Empty(t, out)
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestTaskctl_ExitCodes(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{name: "任務不存在", args: []string{"get", "non-existent"}, expected: exitNotFound},
+		{name: "驗證錯誤", args: []string{"add", "-status", "2", "Task"}, expected: exitValidation},
+		{name: "未知子命令", args: []string{"frobnicate"}, expected: exitUsage},
+		{name: "缺少參數", args: []string{"rm"}, expected: exitUsage},
+		{name: "edit 沒有變更", args: []string{"edit", "some-id"}, expected: exitUsage},
+		{name: "篩選時指定頁數", args: []string{"list", "-page", "2", "-status", "1"}, expected: exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := runCLI(server, tt.args...)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
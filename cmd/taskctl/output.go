@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+)
+
+// 輸出格式
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputID    = "id"
+)
+
+// printTasks 依輸出格式印出任務
+func (a *app) printTasks(tasks []model.Task) error {
+	switch a.output {
+	case outputJSON:
+		return writeJSON(a.stdout, tasks)
+	case outputID:
+		for _, t := range tasks {
+			fmt.Fprintln(a.stdout, t.ID)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATUS\tDUE\tNAME")
+	for _, t := range tasks {
+		due := "-"
+		if t.Due != nil {
+			due = t.Due.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", t.ID, statusLabel(t.Status), due, t.Name)
+	}
+	return tw.Flush()
+}
+
+func statusLabel(status int) string {
+	if status == 1 {
+		return "done"
+	}
+	return "todo"
+}
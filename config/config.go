@@ -0,0 +1,376 @@
+// Package config 載入服務設定，依序套用預設值、設定檔（YAML/TOML）、環境變數與命令列參數
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogolook/task-api/cors"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
+	"github.com/gogolook/task-api/tracing"
+)
+
+// Config 服務的完整設定
+// 每個欄位都可以由設定檔（yaml/toml tag）、環境變數（env tag）與命令列參數（-section.field）覆寫
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server" json:"server"`
+	Storage   StorageConfig   `yaml:"storage" toml:"storage" json:"storage"`
+	Log       LogConfig       `yaml:"log" toml:"log" json:"log"`
+	Errors    ErrorsConfig    `yaml:"errors" toml:"errors" json:"errors"`
+	Health    HealthConfig    `yaml:"health" toml:"health" json:"health"`
+	Metrics   MetricsConfig   `yaml:"metrics" toml:"metrics" json:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing" toml:"tracing" json:"tracing"`
+	Tenancy   TenancyConfig   `yaml:"tenancy" toml:"tenancy" json:"tenancy"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth" json:"auth"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors" json:"cors"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit" json:"rate_limit"`
+	Benchmark BenchmarkConfig `yaml:"benchmark" toml:"benchmark" json:"benchmark"`
+}
+
+type ServerConfig struct {
+	Addr string `yaml:"addr" toml:"addr" json:"addr" env:"TASK_API_ADDR"`
+	// Mode gin 的執行模式：debug、release 或 test
+	Mode string `yaml:"mode" toml:"mode" json:"mode" env:"GIN_MODE"`
+	// ShutdownTimeout 收到 SIGTERM 後等待進行中請求完成的上限
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" json:"shutdown_timeout" env:"TASK_API_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay 收到 SIGTERM 後先回報未就緒、繼續服務的時間
+	ShutdownDelay Duration `yaml:"shutdown_delay" toml:"shutdown_delay" json:"shutdown_delay" env:"TASK_API_SHUTDOWN_DELAY"`
+	// MaxBodySize 請求 body 的大小上限，超過時回傳 413
+	MaxBodySize ByteSize `yaml:"max_body_size" toml:"max_body_size" json:"max_body_size" env:"TASK_API_MAX_BODY_SIZE"`
+	// MaxImportSize POST /tasks/import 的 body 大小上限，匯入以串流逐列解析
+	MaxImportSize ByteSize `yaml:"max_import_size" toml:"max_import_size" json:"max_import_size" env:"TASK_API_MAX_IMPORT_SIZE"`
+	// MaxJSONDepth JSON 物件與陣列的巢狀層數上限
+	MaxJSONDepth int `yaml:"max_json_depth" toml:"max_json_depth" json:"max_json_depth" env:"TASK_API_MAX_JSON_DEPTH"`
+	// AllowWipe 是否註冊清空任務的 DELETE /admin/tasks 與 POST /admin/tasks/restore，預設關閉，只在開發與測試環境開啟
+	AllowWipe bool `yaml:"allow_wipe" toml:"allow_wipe" json:"allow_wipe" env:"TASK_API_ALLOW_WIPE"`
+	// TrustedProxies 可信任的反向代理 IP 或 CIDR，只有來自這些位址的 X-Forwarded-For 才會用來判斷 client IP
+	// 預設不信任任何代理，client IP 一律為連線的來源位址
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies" json:"trusted_proxies" env:"TASK_API_TRUSTED_PROXIES"`
+}
+
+type StorageConfig struct {
+	// Backend 目前只支援 memory
+	Backend string `yaml:"backend" toml:"backend" json:"backend" env:"TASK_API_STORAGE"`
+	// TrashRetention 刪除的任務留在回收桶的時間，過期後永久刪除
+	TrashRetention Duration `yaml:"trash_retention" toml:"trash_retention" json:"trash_retention" env:"TASK_API_TRASH_RETENTION"`
+	// TrashPurgeInterval 清除過期回收桶任務的間隔
+	TrashPurgeInterval Duration `yaml:"trash_purge_interval" toml:"trash_purge_interval" json:"trash_purge_interval" env:"TASK_API_TRASH_PURGE_INTERVAL"`
+	// SnapshotDir 保存快照檔案的目錄，不存在時自動建立
+	SnapshotDir string `yaml:"snapshot_dir" toml:"snapshot_dir" json:"snapshot_dir" env:"TASK_API_SNAPSHOT_DIR"`
+	// SnapshotKeep 保留最新的快照份數，0 表示不限制
+	SnapshotKeep int `yaml:"snapshot_keep" toml:"snapshot_keep" json:"snapshot_keep" env:"TASK_API_SNAPSHOT_KEEP"`
+	// LoadSnapshot 啟動時還原的快照：latest 為最新的一份，也可指定快照 ID；空字串表示不載入
+	LoadSnapshot string `yaml:"load_snapshot" toml:"load_snapshot" json:"load_snapshot" env:"TASK_API_LOAD_SNAPSHOT"`
+}
+
+type LogConfig struct {
+	// Level 最低輸出等級：debug、info、warn 或 error
+	Level string `yaml:"level" toml:"level" json:"level" env:"TASK_API_LOG_LEVEL"`
+	// Format 輸出格式：json 或 text
+	Format string `yaml:"format" toml:"format" json:"format" env:"TASK_API_LOG_FORMAT"`
+	// SampleRate 成功請求記錄的比例，0 到 1；失敗的請求一律記錄
+	SampleRate float64 `yaml:"sample_rate" toml:"sample_rate" json:"sample_rate" env:"TASK_API_LOG_SAMPLE_RATE"`
+	// Redact 額外需要遮蔽的欄位名稱，憑證相關的 header 與參數預設即會遮蔽
+	Redact []string `yaml:"redact" toml:"redact" json:"redact" env:"TASK_API_LOG_REDACT"`
+}
+
+// Options 轉換為 logging 套件的設定
+func (c LogConfig) Options() logging.Options {
+	return logging.Options{Level: c.Level, Format: c.Format, Redact: c.Redact}
+}
+
+type ErrorsConfig struct {
+	// Compat 錯誤回應同時帶有舊格式的 error 與 request_id 欄位，所有客戶端改用 problem details 後可關閉
+	Compat bool `yaml:"compat" toml:"compat" json:"compat" env:"TASK_API_ERROR_COMPAT"`
+}
+
+// Options 轉換為 problem 套件的設定
+func (c ErrorsConfig) Options() problem.Options {
+	return problem.Options{Compat: c.Compat}
+}
+
+type HealthConfig struct {
+	// CheckTimeout 每項 readiness 檢查的逾時
+	CheckTimeout Duration `yaml:"check_timeout" toml:"check_timeout" json:"check_timeout" env:"TASK_API_HEALTH_CHECK_TIMEOUT"`
+}
+
+type MetricsConfig struct {
+	// Enabled 是否提供 GET /metrics 並記錄 HTTP 與 storage 指標
+	Enabled bool `yaml:"enabled" toml:"enabled" json:"enabled" env:"TASK_API_METRICS"`
+}
+
+type TracingConfig struct {
+	// Exporter span 的輸出方式：none、stdout（本機開發）或 otlp
+	Exporter string `yaml:"exporter" toml:"exporter" json:"exporter" env:"TASK_API_TRACING_EXPORTER"`
+	// Endpoint OTLP/HTTP collector 的網址，空字串時使用 OTEL_EXPORTER_OTLP_ENDPOINT 或 http://localhost:4318
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" json:"endpoint" env:"TASK_API_TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" toml:"service_name" json:"service_name" env:"TASK_API_TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" json:"sample_ratio" env:"TASK_API_TRACING_SAMPLE_RATIO"`
+}
+
+// Enabled 是否記錄 span
+func (c TracingConfig) Enabled() bool {
+	return c.Exporter != tracing.ExporterNone
+}
+
+// Options 轉換為 tracing 套件的設定
+func (c TracingConfig) Options() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.Exporter,
+		Endpoint:    c.Endpoint,
+		ServiceName: c.ServiceName,
+		SampleRatio: c.SampleRatio,
+	}
+}
+
+type TenancyConfig struct {
+	Enabled bool   `yaml:"enabled" toml:"enabled" json:"enabled" env:"TASK_API_MULTI_TENANT"`
+	Header  string `yaml:"header" toml:"header" json:"header" env:"TASK_API_TENANT_HEADER"`
+	Domain  string `yaml:"domain" toml:"domain" json:"domain" env:"TASK_API_TENANT_DOMAIN"`
+	// MaxTasks 每個 tenant 預設的任務上限，0 表示不限制
+	MaxTasks int            `yaml:"max_tasks" toml:"max_tasks" json:"max_tasks" env:"TASK_API_TENANT_MAX_TASKS"`
+	Quotas   map[string]int `yaml:"quotas" toml:"quotas" json:"quotas" env:"TASK_API_TENANT_QUOTAS"`
+}
+
+type AuthConfig struct {
+	Enabled  bool   `yaml:"enabled" toml:"enabled" json:"enabled" env:"TASK_API_AUTH"`
+	AdminKey Secret `yaml:"admin_key" toml:"admin_key" json:"admin_key" env:"TASK_API_ADMIN_KEY"`
+	// PolicyFile 自訂的 rbac 政策檔，空字串表示使用內建政策
+	PolicyFile           string    `yaml:"policy_file" toml:"policy_file" json:"policy_file" env:"TASK_API_POLICY_FILE"`
+	PolicyReloadInterval Duration  `yaml:"policy_reload_interval" toml:"policy_reload_interval" json:"policy_reload_interval" env:"TASK_API_POLICY_RELOAD_INTERVAL"`
+	JWT                  JWTConfig `yaml:"jwt" toml:"jwt" json:"jwt"`
+}
+
+// JWTConfig 未設定 JWKSURL 與 KeyFile 時不啟用 JWT 驗證
+type JWTConfig struct {
+	JWKSURL      string `yaml:"jwks_url" toml:"jwks_url" json:"jwks_url" env:"TASK_API_JWT_JWKS_URL"`
+	KeyFile      string `yaml:"key_file" toml:"key_file" json:"key_file" env:"TASK_API_JWT_KEY_FILE"`
+	Issuer       string `yaml:"issuer" toml:"issuer" json:"issuer" env:"TASK_API_JWT_ISSUER"`
+	Audience     string `yaml:"audience" toml:"audience" json:"audience" env:"TASK_API_JWT_AUDIENCE"`
+	ScopeClaim   string `yaml:"scope_claim" toml:"scope_claim" json:"scope_claim" env:"TASK_API_JWT_SCOPE_CLAIM"`
+	TenantClaim  string `yaml:"tenant_claim" toml:"tenant_claim" json:"tenant_claim" env:"TASK_API_JWT_TENANT_CLAIM"`
+	DefaultScope string `yaml:"default_scope" toml:"default_scope" json:"default_scope" env:"TASK_API_JWT_DEFAULT_SCOPE"`
+}
+
+// Enabled 回傳是否設定了驗證 JWT 用的公鑰來源
+func (c JWTConfig) Enabled() bool {
+	return c.JWKSURL != "" || c.KeyFile != ""
+}
+
+type CORSConfig struct {
+	Origins       []string `yaml:"origins" toml:"origins" json:"origins" env:"TASK_API_CORS_ORIGINS"`
+	Methods       []string `yaml:"methods" toml:"methods" json:"methods" env:"TASK_API_CORS_METHODS"`
+	Headers       []string `yaml:"headers" toml:"headers" json:"headers" env:"TASK_API_CORS_HEADERS"`
+	ExposeHeaders []string `yaml:"expose_headers" toml:"expose_headers" json:"expose_headers" env:"TASK_API_CORS_EXPOSE_HEADERS"`
+	Credentials   bool     `yaml:"credentials" toml:"credentials" json:"credentials" env:"TASK_API_CORS_CREDENTIALS"`
+	MaxAge        Duration `yaml:"max_age" toml:"max_age" json:"max_age" env:"TASK_API_CORS_MAX_AGE"`
+}
+
+// Policy 轉換為 cors 套件的設定
+func (c CORSConfig) Policy() cors.Config {
+	return cors.Config{
+		AllowedOrigins:   c.Origins,
+		AllowedMethods:   c.Methods,
+		AllowedHeaders:   c.Headers,
+		ExposedHeaders:   c.ExposeHeaders,
+		AllowCredentials: c.Credentials,
+		MaxAge:           time.Duration(c.MaxAge),
+	}
+}
+
+type RateLimitConfig struct {
+	Enabled      bool    `yaml:"enabled" toml:"enabled" json:"enabled" env:"TASK_API_RATE_LIMIT"`
+	ReadRate     float64 `yaml:"read_rate" toml:"read_rate" json:"read_rate" env:"TASK_API_RATE_READ"`
+	ReadBurst    int     `yaml:"read_burst" toml:"read_burst" json:"read_burst" env:"TASK_API_RATE_READ_BURST"`
+	WriteRate    float64 `yaml:"write_rate" toml:"write_rate" json:"write_rate" env:"TASK_API_RATE_WRITE"`
+	WriteBurst   int     `yaml:"write_burst" toml:"write_burst" json:"write_burst" env:"TASK_API_RATE_WRITE_BURST"`
+	DailyCreates int     `yaml:"daily_creates" toml:"daily_creates" json:"daily_creates" env:"TASK_API_DAILY_CREATE_QUOTA"`
+}
+
+// Limits 轉換為 ratelimit 套件的預設限制
+func (c RateLimitConfig) Limits() ratelimit.Limits {
+	return ratelimit.Limits{
+		ReadRate:     c.ReadRate,
+		ReadBurst:    c.ReadBurst,
+		WriteRate:    c.WriteRate,
+		WriteBurst:   c.WriteBurst,
+		DailyCreates: c.DailyCreates,
+	}
+}
+
+// BenchmarkConfig 壓力測試程式（benchmark/stress_benchmark.go）使用的設定
+type BenchmarkConfig struct {
+	BaseURL            string `yaml:"base_url" toml:"base_url" json:"base_url" env:"TASK_API_BENCH_URL"`
+	APIKey             Secret `yaml:"api_key" toml:"api_key" json:"api_key" env:"TASK_API_KEY"`
+	StorageGoroutines  int    `yaml:"storage_goroutines" toml:"storage_goroutines" json:"storage_goroutines" env:"TASK_API_BENCH_STORAGE_GOROUTINES"`
+	HTTPConcurrency    int    `yaml:"http_concurrency" toml:"http_concurrency" json:"http_concurrency" env:"TASK_API_BENCH_HTTP_CONCURRENCY"`
+	MaxConcurrency     int    `yaml:"max_concurrency" toml:"max_concurrency" json:"max_concurrency" env:"TASK_API_BENCH_MAX_CONCURRENCY"`
+	MixedReaders       int    `yaml:"mixed_readers" toml:"mixed_readers" json:"mixed_readers" env:"TASK_API_BENCH_MIXED_READERS"`
+	MixedWriters       int    `yaml:"mixed_writers" toml:"mixed_writers" json:"mixed_writers" env:"TASK_API_BENCH_MIXED_WRITERS"`
+	MixedListers       int    `yaml:"mixed_listers" toml:"mixed_listers" json:"mixed_listers" env:"TASK_API_BENCH_MIXED_LISTERS"`
+	LongRunningWorkers int    `yaml:"long_running_workers" toml:"long_running_workers" json:"long_running_workers" env:"TASK_API_BENCH_LONG_RUNNING_WORKERS"`
+	InitialTaskCount   int    `yaml:"initial_task_count" toml:"initial_task_count" json:"initial_task_count" env:"TASK_API_BENCH_INITIAL_TASKS"`
+	MixedInitialTasks  int    `yaml:"mixed_initial_tasks" toml:"mixed_initial_tasks" json:"mixed_initial_tasks" env:"TASK_API_BENCH_MIXED_INITIAL_TASKS"`
+}
+
+// Default 回傳所有設定的預設值
+func Default() *Config {
+	corsDefaults := cors.DefaultConfig()
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			Mode:            "debug",
+			ShutdownTimeout: Duration(10 * time.Second),
+			MaxBodySize:     ByteSize(1 << 20),
+			MaxImportSize:   ByteSize(32 << 20),
+			MaxJSONDepth:    32,
+		},
+		Storage: StorageConfig{
+			Backend:            "memory",
+			TrashRetention:     Duration(30 * 24 * time.Hour),
+			TrashPurgeInterval: Duration(time.Hour),
+			SnapshotDir:        "snapshots",
+			SnapshotKeep:       5,
+		},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     logging.FormatJSON,
+			SampleRate: 1,
+		},
+		Errors: ErrorsConfig{
+			Compat: true,
+		},
+		Health: HealthConfig{
+			CheckTimeout: Duration(2 * time.Second),
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "task-api",
+			SampleRatio: 1,
+		},
+		Tenancy: TenancyConfig{
+			Header: "X-Tenant-ID",
+			Quotas: map[string]int{},
+		},
+		Auth: AuthConfig{
+			Enabled:              true,
+			PolicyReloadInterval: Duration(5 * time.Second),
+		},
+		CORS: CORSConfig{
+			Origins:       corsDefaults.AllowedOrigins,
+			Methods:       corsDefaults.AllowedMethods,
+			Headers:       corsDefaults.AllowedHeaders,
+			ExposeHeaders: corsDefaults.ExposedHeaders,
+			Credentials:   corsDefaults.AllowCredentials,
+			MaxAge:        Duration(corsDefaults.MaxAge),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:    true,
+			ReadRate:   50,
+			ReadBurst:  100,
+			WriteRate:  10,
+			WriteBurst: 20,
+		},
+		Benchmark: BenchmarkConfig{
+			BaseURL:            "http://localhost:8080",
+			StorageGoroutines:  100000,
+			HTTPConcurrency:    1000,
+			MaxConcurrency:     5000,
+			MixedReaders:       70,
+			MixedWriters:       20,
+			MixedListers:       10,
+			LongRunningWorkers: 50,
+			InitialTaskCount:   1000,
+			MixedInitialTasks:  1000,
+		},
+	}
+}
+
+// Secret 不應出現在輸出中的設定值，例如 API key
+type Secret string
+
+const redacted = "[REDACTED]"
+
+// String 回傳遮蔽後的值，避免被印到 log
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Value 回傳原始值
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Duration 可由 "10m"、"5s" 等字串設定的時間長度
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// ByteSize 可由 "1MiB"、"512KiB" 或位元組數設定的大小
+type ByteSize int64
+
+// byteUnits 由大到小排列，MarshalText 取第一個能整除的單位
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"KB", 1e3},
+	{"B", 1},
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	for _, u := range byteUnits {
+		if b != 0 && int64(b)%u.size == 0 {
+			return []byte(strconv.FormatInt(int64(b)/u.size, 10) + u.suffix), nil
+		}
+	}
+	return []byte(strconv.FormatInt(int64(b), 10)), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a size (use bytes or a unit such as 512KiB or 1MiB)", string(text))
+	}
+	*b = ByteSize(n * unit)
+	return nil
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(nil, envMap(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), cfg)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.True(t, cfg.Auth.Enabled)
+	assert.False(t, cfg.Server.AllowWipe)
+	assert.Empty(t, cfg.Server.TrustedProxies)
+	assert.Equal(t, []string{"https://etrex.tw", "https://etrex.github.io"}, cfg.CORS.Origins)
+	assert.Equal(t, "http://localhost:8080", cfg.Benchmark.BaseURL)
+}
+
+func TestLoad_Layering(t *testing.T) {
+	path := writeFile(t, "task-api.yaml", `
+server:
+  addr: ":9000"
+  mode: release
+  max_import_size: 64MiB
+cors:
+  origins: ["https://*.staging.etrex.tw"]
+  max_age: 1h
+rate_limit:
+  read_rate: 5
+  read_burst: 10
+tenancy:
+  enabled: true
+  quotas:
+    acme: 100
+`)
+
+	env := envMap(map[string]string{
+		EnvConfigFile:              path,
+		"TASK_API_ADDR":            ":9100",
+		"TASK_API_RATE_READ":       "7",
+		"TASK_API_AUTH":            "off",
+		"TASK_API_ALLOW_WIPE":      "true",
+		"TASK_API_TRUSTED_PROXIES": "10.0.0.0/8,192.168.1.1",
+	})
+	cfg, err := Load([]string{"-server.addr=:9200", "-rate_limit.read_burst", "20", "-cors.credentials"}, env)
+	require.NoError(t, err)
+
+	// 命令列參數 > 環境變數 > 設定檔 > 預設值
+	assert.Equal(t, ":9200", cfg.Server.Addr)
+	assert.Equal(t, "release", cfg.Server.Mode)
+	assert.Equal(t, ByteSize(64<<20), cfg.Server.MaxImportSize)
+	assert.Equal(t, 7.0, cfg.RateLimit.ReadRate)
+	assert.Equal(t, 20, cfg.RateLimit.ReadBurst)
+	assert.Equal(t, 10.0, cfg.RateLimit.WriteRate)
+	assert.False(t, cfg.Auth.Enabled)
+	assert.True(t, cfg.Server.AllowWipe)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, cfg.Server.TrustedProxies)
+	assert.Equal(t, []string{"https://*.staging.etrex.tw"}, cfg.CORS.Origins)
+	assert.Equal(t, Duration(time.Hour), cfg.CORS.MaxAge)
+	assert.True(t, cfg.CORS.Credentials)
+	assert.True(t, cfg.Tenancy.Enabled)
+	assert.Equal(t, map[string]int{"acme": 100}, cfg.Tenancy.Quotas)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "task-api.toml", `
+[server]
+addr = ":9000"
+
+[auth]
+admin_key = "tk_secret"
+policy_reload_interval = "30s"
+
+[tenancy]
+enabled = true
+header = "X-Team"
+
+[tenancy.quotas]
+acme = 1000
+`)
+
+	cfg, err := Load([]string{"-config", path}, envMap(nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.Server.Addr)
+	assert.Equal(t, Secret("tk_secret"), cfg.Auth.AdminKey)
+	assert.Equal(t, Duration(30*time.Second), cfg.Auth.PolicyReloadInterval)
+	assert.Equal(t, "X-Team", cfg.Tenancy.Header)
+	assert.Equal(t, map[string]int{"acme": 1000}, cfg.Tenancy.Quotas)
+}
+
+func TestLoad_Env(t *testing.T) {
+	cfg, err := Load(nil, envMap(map[string]string{
+		"TASK_API_MULTI_TENANT":     "on",
+		"TASK_API_TENANT_QUOTAS":    "acme=1000,globex=500",
+		"TASK_API_CORS_ORIGINS":     "https://a.example.com, https://b.example.com",
+		"TASK_API_CORS_CREDENTIALS": "true",
+		"TASK_API_CORS_MAX_AGE":     "2m",
+		"TASK_API_KEY":              "tk_bench",
+		"TASK_API_MAX_BODY_SIZE":    "512KiB",
+		"TASK_API_TRASH_RETENTION":  "168h",
+		"TASK_API_SNAPSHOT_KEEP":    "10",
+		"TASK_API_LOAD_SNAPSHOT":    "latest",
+		// 空字串視為未設定
+		"TASK_API_ADDR": "",
+	}))
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Tenancy.Enabled)
+	assert.Equal(t, map[string]int{"acme": 1000, "globex": 500}, cfg.Tenancy.Quotas)
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.Origins)
+	assert.True(t, cfg.CORS.Credentials)
+	assert.Equal(t, Duration(2*time.Minute), cfg.CORS.MaxAge)
+	assert.Equal(t, "tk_bench", cfg.Benchmark.APIKey.Value())
+	assert.Equal(t, ByteSize(512<<10), cfg.Server.MaxBodySize)
+	assert.Equal(t, Duration(7*24*time.Hour), cfg.Storage.TrashRetention)
+	assert.Equal(t, Duration(time.Hour), cfg.Storage.TrashPurgeInterval)
+	assert.Equal(t, 10, cfg.Storage.SnapshotKeep)
+	assert.Equal(t, "snapshots", cfg.Storage.SnapshotDir)
+	assert.Equal(t, "latest", cfg.Storage.LoadSnapshot)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		wantErr string
+	}{
+		{name: "設定檔有未知的欄位", file: "server:\n  adress: \":9000\"\n", wantErr: "field adress not found"},
+		{name: "不支援的副檔名", args: []string{"-config", "task-api.json"}, wantErr: "unsupported format"},
+		{name: "環境變數格式錯誤", env: map[string]string{"TASK_API_RATE_READ": "fast"}, wantErr: `env TASK_API_RATE_READ: "fast" is not a number`},
+		{name: "布林值格式錯誤", env: map[string]string{"TASK_API_AUTH": "maybe"}, wantErr: "env TASK_API_AUTH: \"maybe\" is not a boolean"},
+		{name: "大小格式錯誤", env: map[string]string{"TASK_API_MAX_BODY_SIZE": "1 mega"}, wantErr: `"1 mega" is not a size`},
+		{name: "參數格式錯誤", args: []string{"-cors.max_age=forever"}, wantErr: "flag -cors.max_age"},
+		{name: "未知的參數", args: []string{"-port=80"}, wantErr: "flag provided but not defined: -port"},
+		{name: "多餘的參數", args: []string{"serve"}, wantErr: "unexpected arguments: serve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.env
+			if tt.file != "" {
+				env = map[string]string{EnvConfigFile: writeFile(t, "task-api.yaml", tt.file)}
+			}
+			_, err := Load(tt.args, envMap(env))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := Load([]string{"-h"}, envMap(nil))
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Mode = "production"
+	cfg.Server.MaxBodySize = 0
+	cfg.Server.MaxJSONDepth = 0
+	cfg.Storage.Backend = "postgres"
+	cfg.Storage.TrashRetention = 0
+	cfg.Storage.SnapshotKeep = -1
+	cfg.Tenancy.Quotas = map[string]int{"Acme Corp": 1, "globex": -1}
+	cfg.Auth.AdminKey = "secret"
+	cfg.Auth.JWT.KeyFile = "jwt.pem"
+	cfg.CORS.Origins = []string{"*"}
+	cfg.CORS.Credentials = true
+	cfg.RateLimit.WriteBurst = 0
+	cfg.Log.Level = "verbose"
+	cfg.Log.SampleRate = -1
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Benchmark.HTTPConcurrency = 0
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	// 一次列出所有錯誤，並以設定路徑開頭
+	for _, expected := range []string{
+		`server.mode: must be debug, release or test, got "production"`,
+		`server.max_body_size: must be positive`,
+		`server.max_json_depth: must be at least 1`,
+		`storage.backend: unsupported backend "postgres" (supported: memory)`,
+		`storage.trash_retention: must be positive`,
+		`storage.snapshot_keep: must not be negative`,
+		`tenancy.quotas: invalid tenant "Acme Corp"`,
+		`tenancy.quotas.globex: must not be negative`,
+		`auth.admin_key: must start with "tk_"`,
+		`auth.jwt.issuer: is required when jwks_url or key_file is set`,
+		`cors: allowed origin "*" cannot be combined with credentials`,
+		`rate_limit: rates, bursts and quotas must not be negative`,
+		`log.level: must be debug, info, warn or error, got "verbose"`,
+		`log.sample_rate: must be between 0 and 1`,
+		`tracing.exporter: must be none, stdout or otlp, got "jaeger"`,
+		`tracing.sample_ratio: must be between 0 and 1`,
+		`benchmark.http_concurrency: must be at least 1`,
+	} {
+		assert.Contains(t, err.Error(), expected)
+	}
+
+	// 停用速率限制時不檢查其數值
+	cfg = Default()
+	cfg.RateLimit.Enabled = false
+	cfg.RateLimit.ReadBurst = 0
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected ByteSize
+		marshal  string
+	}{
+		{name: "位元組數", text: "1500", expected: 1500, marshal: "1500B"},
+		{name: "二進位單位", text: "1MiB", expected: 1 << 20, marshal: "1MiB"},
+		{name: "十進位單位", text: "2MB", expected: 2e6, marshal: "2MB"},
+		{name: "數字與單位間的空白", text: "512 KiB", expected: 512 << 10, marshal: "512KiB"},
+		{name: "優先使用二進位單位", text: "1024KiB", expected: 1 << 20, marshal: "1MiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b ByteSize
+			require.NoError(t, b.UnmarshalText([]byte(tt.text)))
+			assert.Equal(t, tt.expected, b)
+
+			text, err := b.MarshalText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.marshal, string(text))
+		})
+	}
+
+	var b ByteSize
+	assert.Error(t, b.UnmarshalText([]byte("1.5MiB")))
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.Auth.AdminKey = "tk_admin"
+	cfg.Benchmark.APIKey = "tk_bench"
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tk_admin")
+	assert.NotContains(t, string(data), "tk_bench")
+	assert.Contains(t, string(data), `"admin_key":"[REDACTED]"`)
+	assert.Contains(t, string(data), `"max_age":"10m0s"`)
+
+	assert.Equal(t, "[REDACTED]", cfg.Auth.AdminKey.String())
+	assert.Equal(t, "", Secret("").String())
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gogolook/task-api/tenant"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile 指定設定檔路徑的環境變數，命令列的 -config 優先
+const EnvConfigFile = "TASK_API_CONFIG"
+
+// field 一個可設定的葉節點欄位
+type field struct {
+	path  string // 例如 "rate_limit.read_rate"
+	env   string
+	value reflect.Value
+}
+
+// fields 依宣告順序列出 cfg 所有可設定的欄位
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+			path := prefix + name
+			if sf.Type.Kind() == reflect.Struct && !isLeaf(sf.Type) {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			out = append(out, field{path: path, env: sf.Tag.Get("env"), value: v.Field(i)})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+func isLeaf(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// Load 依序套用預設值、設定檔、環境變數與 args 中的命令列參數，並驗證結果
+// lookupEnv 通常為 os.LookupEnv；args 含 -h 時回傳 flag.ErrHelp
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	all := fields(cfg)
+
+	// 命令列參數最後套用，先記錄下來
+	fs := flag.NewFlagSet("task-api", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML or TOML config file (env "+EnvConfigFile+")")
+	var flagValues []func() error
+	for _, f := range all {
+		f := f
+		usage := "sets " + f.path
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		set := func(s string) error {
+			flagValues = append(flagValues, func() error {
+				if err := setValue(f.value, s); err != nil {
+					return fmt.Errorf("flag -%s: %w", f.path, err)
+				}
+				return nil
+			})
+			return nil
+		}
+		// 布林值可以只寫 -tenancy.enabled
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(f.path, usage, set)
+		} else {
+			fs.Func(f.path, usage, set)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(EnvConfigFile)
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range all {
+		if f.env == "" {
+			continue
+		}
+		if s, ok := lookupEnv(f.env); ok && s != "" {
+			if err := setValue(f.value, s); err != nil {
+				return nil, fmt.Errorf("env %s: %w", f.env, err)
+			}
+		}
+	}
+
+	for _, apply := range flagValues {
+		if err := apply(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile 依副檔名以 YAML 或 TOML 解析設定檔，未知的欄位視為錯誤以便發現拼錯的設定
+func loadFile(cfg *Config, path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".yaml", ".yml", ".toml":
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	if ext == ".toml" {
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return fmt.Errorf("parse config file %s: %w", path, err)
+		}
+		return nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// setValue 將環境變數或命令列參數的字串值寫入欄位
+// 布林值接受 on/off；清單以逗號分隔；map 使用 "acme=1000,globex=500"
+func setValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := parseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(s)))
+	case reflect.Map:
+		// 目前只有 tenancy.quotas 是 map
+		m, err := tenant.ParseQuotas(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("%q is not a boolean (use true/false or on/off)", s)
+	}
+	return b, nil
+}
+
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/cors"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/tenant"
+	"github.com/gogolook/task-api/tracing"
+)
+
+// Validate 檢查所有設定，一次回報所有錯誤，每個錯誤都以設定路徑開頭
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(path, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Addr == "" {
+		fail("server.addr", "must not be empty")
+	}
+	switch c.Server.Mode {
+	case "debug", "release", "test":
+	default:
+		fail("server.mode", "must be debug, release or test, got %q", c.Server.Mode)
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("server.shutdown_timeout", "must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		fail("server.shutdown_delay", "must not be negative")
+	}
+	if c.Server.MaxBodySize <= 0 {
+		fail("server.max_body_size", "must be positive")
+	}
+	if c.Server.MaxImportSize <= 0 {
+		fail("server.max_import_size", "must be positive")
+	}
+	if c.Server.MaxJSONDepth < 1 {
+		fail("server.max_json_depth", "must be at least 1")
+	}
+
+	if c.Storage.Backend != "memory" {
+		fail("storage.backend", "unsupported backend %q (supported: memory)", c.Storage.Backend)
+	}
+	if c.Storage.TrashRetention <= 0 {
+		fail("storage.trash_retention", "must be positive")
+	}
+	if c.Storage.TrashPurgeInterval <= 0 {
+		fail("storage.trash_purge_interval", "must be positive")
+	}
+	if strings.TrimSpace(c.Storage.SnapshotDir) == "" {
+		fail("storage.snapshot_dir", "must not be empty")
+	}
+	if c.Storage.SnapshotKeep < 0 {
+		fail("storage.snapshot_keep", "must not be negative")
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level", "%v", err)
+	}
+	if c.Log.Format != logging.FormatJSON && c.Log.Format != logging.FormatText {
+		fail("log.format", "%v, got %q", logging.ErrInvalidFormat, c.Log.Format)
+	}
+	if c.Log.SampleRate < 0 || c.Log.SampleRate > 1 {
+		fail("log.sample_rate", "must be between 0 and 1")
+	}
+
+	if c.Health.CheckTimeout <= 0 {
+		fail("health.check_timeout", "must be positive")
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		fail("tracing.exporter", "must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			fail("tracing.endpoint", "must be an absolute URL")
+		}
+	}
+	if c.Tracing.Enabled() && strings.TrimSpace(c.Tracing.ServiceName) == "" {
+		fail("tracing.service_name", "must not be empty")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be between 0 and 1")
+	}
+
+	if c.Tenancy.Enabled && strings.TrimSpace(c.Tenancy.Header) == "" && c.Tenancy.Domain == "" {
+		fail("tenancy.header", "header or domain is required when tenancy is enabled")
+	}
+	if c.Tenancy.MaxTasks < 0 {
+		fail("tenancy.max_tasks", "must not be negative")
+	}
+	ids := make([]string, 0, len(c.Tenancy.Quotas))
+	for id := range c.Tenancy.Quotas {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if quota := c.Tenancy.Quotas[id]; !tenant.Valid(id) {
+			fail("tenancy.quotas", "invalid tenant %q", id)
+		} else if quota < 0 {
+			fail("tenancy.quotas."+id, "must not be negative")
+		}
+	}
+
+	if key := c.Auth.AdminKey.Value(); key != "" && !strings.HasPrefix(key, auth.KeyPrefix) {
+		fail("auth.admin_key", "must start with %q", auth.KeyPrefix)
+	}
+	if c.Auth.PolicyFile != "" && c.Auth.PolicyReloadInterval <= 0 {
+		fail("auth.policy_reload_interval", "must be positive")
+	}
+	if jwt := c.Auth.JWT; jwt.Enabled() {
+		if jwt.Issuer == "" {
+			fail("auth.jwt.issuer", "is required when jwks_url or key_file is set")
+		}
+		if jwt.JWKSURL != "" {
+			if u, err := url.Parse(jwt.JWKSURL); err != nil || u.Scheme == "" || u.Host == "" {
+				fail("auth.jwt.jwks_url", "must be an absolute URL")
+			}
+		}
+		if jwt.DefaultScope != "" && !auth.Scope(jwt.DefaultScope).Valid() {
+			fail("auth.jwt.default_scope", "%v", auth.ErrInvalidScope)
+		}
+	}
+
+	if _, err := cors.New(c.CORS.Policy()); err != nil {
+		fail("cors", "%v", err)
+	}
+
+	if c.RateLimit.Enabled {
+		if err := c.RateLimit.Limits().Validate(); err != nil {
+			fail("rate_limit", "%v", err)
+		}
+	}
+
+	if u, err := url.Parse(c.Benchmark.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		fail("benchmark.base_url", "must be an absolute URL")
+	}
+	counts := []struct {
+		path string
+		n    int
+		min  int
+	}{
+		{"benchmark.storage_goroutines", c.Benchmark.StorageGoroutines, 1},
+		{"benchmark.http_concurrency", c.Benchmark.HTTPConcurrency, 1},
+		{"benchmark.max_concurrency", c.Benchmark.MaxConcurrency, 1},
+		{"benchmark.mixed_readers", c.Benchmark.MixedReaders, 0},
+		{"benchmark.mixed_writers", c.Benchmark.MixedWriters, 0},
+		{"benchmark.mixed_listers", c.Benchmark.MixedListers, 0},
+		{"benchmark.long_running_workers", c.Benchmark.LongRunningWorkers, 1},
+		{"benchmark.initial_task_count", c.Benchmark.InitialTaskCount, 0},
+		{"benchmark.mixed_initial_tasks", c.Benchmark.MixedInitialTasks, 0},
+	}
+	for _, count := range counts {
+		if count.n < count.min {
+			fail(count.path, "must be at least %d", count.min)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+}
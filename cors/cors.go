@@ -0,0 +1,282 @@
+// Package cors 依設定的來源清單處理跨來源請求（CORS）與 preflight
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+)
+
+// Config CORS 政策
+type Config struct {
+	// AllowedOrigins 允許的來源，例如 "https://etrex.tw"；"https://*.etrex.tw" 允許所有子網域，"*" 允許任何來源
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders 允許請求帶入的 header，"*" 允許任何 header
+	AllowedHeaders []string
+	// ExposedHeaders 讓瀏覽器端程式可以讀取的回應 header
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge 瀏覽器快取 preflight 結果的時間，0 表示不送出 Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+// DefaultConfig 回傳預設政策：允許正式環境的前端，並公開分頁、ETag 與速率限制 header
+func DefaultConfig() Config {
+	return Config{
+		AllowedOrigins: []string{"https://etrex.tw", "https://etrex.github.io"},
+		AllowedMethods: []string{
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowedHeaders: []string{
+			"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match",
+			"X-API-Key", "X-Request-ID", "X-Tenant-ID",
+		},
+		ExposedHeaders: []string{
+			"ETag", "Content-Disposition",
+			"X-Total-Count", "X-Total-Pages", "X-Page", "X-Per-Page",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			"X-Daily-Quota-Limit", "X-Daily-Quota-Remaining", "X-Request-ID",
+		},
+		MaxAge: 10 * time.Minute,
+	}
+}
+
+// codeRejected 來源、方法或 header 不被 CORS 政策允許時的錯誤代碼
+const codeRejected = "cors_rejected"
+
+// originPattern 解析後的允許來源
+type originPattern struct {
+	any    bool
+	exact  string
+	prefix string // 萬用子網域的 "scheme://"
+	suffix string // 萬用子網域的 ".domain[:port]"
+}
+
+func parseOrigin(origin string) (originPattern, error) {
+	origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+	if origin == "*" {
+		return originPattern{any: true}, nil
+	}
+
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || scheme == "" || host == "" || strings.ContainsAny(host, "/?#") {
+		return originPattern{}, fmt.Errorf("invalid origin %q: must look like https://example.com", origin)
+	}
+	if rest, wildcard := strings.CutPrefix(host, "*."); wildcard {
+		if rest == "" || strings.Contains(rest, "*") {
+			return originPattern{}, fmt.Errorf("invalid origin %q", origin)
+		}
+		return originPattern{prefix: scheme + "://", suffix: "." + rest}, nil
+	}
+	if strings.Contains(host, "*") {
+		return originPattern{}, fmt.Errorf("invalid origin %q: wildcards are only allowed as the first label", origin)
+	}
+	return originPattern{exact: origin}, nil
+}
+
+func (p originPattern) matches(origin string) bool {
+	switch {
+	case p.any:
+		return true
+	case p.exact != "":
+		return origin == p.exact
+	}
+	sub, ok := strings.CutPrefix(origin, p.prefix)
+	if !ok {
+		return false
+	}
+	sub, ok = strings.CutSuffix(sub, p.suffix)
+	return ok && validSubdomain(sub)
+}
+
+// validSubdomain 避免 "https://evil.com#.etrex.tw" 之類的來源通過萬用比對
+func validSubdomain(s string) bool {
+	if s == "" || strings.HasPrefix(s, ".") || strings.HasSuffix(s, ".") {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// policy 預先計算好 header 值的 Config
+type policy struct {
+	origins          []originPattern
+	anyOrigin        bool
+	methods          map[string]bool
+	allowMethods     string
+	headers          map[string]bool
+	anyHeader        bool
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// New 依 cfg 建立 CORS middleware，需掛在驗證之前，preflight 請求不帶憑證
+func New(cfg Config) (gin.HandlerFunc, error) {
+	p := &policy{
+		methods:          make(map[string]bool),
+		headers:          make(map[string]bool),
+		allowCredentials: cfg.AllowCredentials,
+	}
+
+	for _, o := range cfg.AllowedOrigins {
+		pattern, err := parseOrigin(o)
+		if err != nil {
+			return nil, err
+		}
+		p.anyOrigin = p.anyOrigin || pattern.any
+		p.origins = append(p.origins, pattern)
+	}
+	if p.anyOrigin && cfg.AllowCredentials {
+		return nil, errors.New("allowed origin \"*\" cannot be combined with credentials")
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, m := range cfg.AllowedMethods {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m != "" && !p.methods[m] {
+			p.methods[m] = true
+			methods = append(methods, m)
+		}
+	}
+	p.allowMethods = strings.Join(methods, ", ")
+
+	headers := make([]string, 0, len(cfg.AllowedHeaders))
+	for _, h := range cfg.AllowedHeaders {
+		h = strings.TrimSpace(h)
+		if h == "*" {
+			p.anyHeader = true
+			continue
+		}
+		if h != "" && !p.headers[strings.ToLower(h)] {
+			p.headers[strings.ToLower(h)] = true
+			headers = append(headers, h)
+		}
+	}
+	p.allowHeaders = strings.Join(headers, ", ")
+	p.exposeHeaders = strings.Join(cfg.ExposedHeaders, ", ")
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return p.handle, nil
+}
+
+func (p *policy) handle(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+	// 回應內容依 Origin 而不同時，告知快取分開保存
+	if !p.anyOrigin {
+		c.Writer.Header().Add("Vary", "Origin")
+	}
+	if preflight {
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		c.Next()
+		return
+	}
+
+	if !p.allowed(origin) {
+		if preflight {
+			problem.Abort(c, problem.New(http.StatusForbidden, codeRejected, "origin not allowed"))
+			return
+		}
+		// 一般請求照常處理，瀏覽器因為沒有 CORS header 而不會讓頁面讀取回應
+		c.Next()
+		return
+	}
+
+	if preflight {
+		p.handlePreflight(c, origin)
+		return
+	}
+
+	p.setOrigin(c, origin)
+	if p.exposeHeaders != "" {
+		c.Header("Access-Control-Expose-Headers", p.exposeHeaders)
+	}
+	c.Next()
+}
+
+func (p *policy) handlePreflight(c *gin.Context, origin string) {
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	if !p.methods[method] {
+		problem.Abort(c, problem.New(http.StatusForbidden, codeRejected, "method "+method+" not allowed by CORS policy"))
+		return
+	}
+
+	requested := c.GetHeader("Access-Control-Request-Headers")
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !p.anyHeader && !p.headers[h] {
+			problem.Abort(c, problem.New(http.StatusForbidden, codeRejected, "header "+h+" not allowed by CORS policy"))
+			return
+		}
+	}
+
+	p.setOrigin(c, origin)
+	c.Header("Access-Control-Allow-Methods", p.allowMethods)
+	if p.anyHeader && requested != "" {
+		c.Header("Access-Control-Allow-Headers", requested)
+	} else if p.allowHeaders != "" {
+		c.Header("Access-Control-Allow-Headers", p.allowHeaders)
+	}
+	if p.maxAge != "" {
+		c.Header("Access-Control-Max-Age", p.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (p *policy) setOrigin(c *gin.Context, origin string) {
+	if p.anyOrigin {
+		c.Header("Access-Control-Allow-Origin", "*")
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *policy) allowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	if _, err := url.Parse(origin); err != nil {
+		return false
+	}
+	for _, pattern := range p.origins {
+		if pattern.matches(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitList 解析以逗號分隔的設定值，略過空白項目
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,166 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T, cfg Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	middleware, err := New(cfg)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware)
+	router.GET("/tasks", func(c *gin.Context) {
+		c.Header("X-Total-Count", "1")
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	router.PATCH("/tasks/:id", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	return router
+}
+
+func preflight(router http.Handler, origin, method, headers string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/tasks/1", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPreflight(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AllowedOrigins = []string{"https://etrex.tw", "https://*.staging.etrex.tw"}
+	router := newRouter(t, cfg)
+
+	tests := []struct {
+		name           string
+		origin         string
+		method         string
+		headers        string
+		expectedStatus int
+		expectedOrigin string
+		expectedBody   string
+	}{
+		{name: "允許的來源", origin: "https://etrex.tw", method: "PATCH", headers: "Content-Type, X-API-Key", expectedStatus: http.StatusNoContent, expectedOrigin: "https://etrex.tw"},
+		{name: "萬用子網域", origin: "https://pr-42.staging.etrex.tw", method: "DELETE", expectedStatus: http.StatusNoContent, expectedOrigin: "https://pr-42.staging.etrex.tw"},
+		{name: "多層子網域", origin: "https://a.b.staging.etrex.tw", method: "GET", expectedStatus: http.StatusNoContent, expectedOrigin: "https://a.b.staging.etrex.tw"},
+		{name: "萬用字元不包含網域本身", origin: "https://staging.etrex.tw", method: "GET", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"origin not allowed","error":"origin not allowed"}`},
+		{name: "scheme 不同", origin: "http://pr-42.staging.etrex.tw", method: "GET", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"origin not allowed","error":"origin not allowed"}`},
+		{name: "偽裝成子網域的來源", origin: "https://evil.com?.staging.etrex.tw", method: "GET", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"origin not allowed","error":"origin not allowed"}`},
+		{name: "未列出的來源", origin: "https://example.com", method: "GET", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"origin not allowed","error":"origin not allowed"}`},
+		{name: "不允許的方法", origin: "https://etrex.tw", method: "TRACE", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"method TRACE not allowed by CORS policy","error":"method TRACE not allowed by CORS policy"}`},
+		{name: "不允許的 header", origin: "https://etrex.tw", method: "GET", headers: "X-Debug", expectedStatus: http.StatusForbidden, expectedBody: `{"type":"https://task-api.etrex.tw/problems/cors_rejected","code":"cors_rejected","title":"Forbidden","status":403,"detail":"header x-debug not allowed by CORS policy","error":"header x-debug not allowed by CORS policy"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := preflight(router, tt.origin, tt.method, tt.headers)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Contains(t, w.Header().Values("Vary"), "Origin")
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+				return
+			}
+			assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "Accept, Authorization, Content-Type, If-Match, If-None-Match, X-API-Key, X-Request-ID, X-Tenant-ID", w.Header().Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AllowCredentials = true
+	router := newRouter(t, cfg)
+
+	do := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("允許的來源可讀取公開的 header", func(t *testing.T) {
+		w := do("https://etrex.github.io")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://etrex.github.io", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "ETag")
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Total-Count")
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "RateLimit-Remaining")
+		assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("未列出的來源照常處理但不帶 CORS header", func(t *testing.T) {
+		w := do("https://example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(t, []string{"Origin"}, w.Header().Values("Vary"))
+	})
+
+	t.Run("同源請求", func(t *testing.T) {
+		w := do("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestAnyOrigin(t *testing.T) {
+	router := newRouter(t, Config{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "PATCH"},
+		AllowedHeaders: []string{"*"},
+		MaxAge:         time.Hour,
+	})
+
+	w := preflight(router, "https://anything.example", "PATCH", "X-Custom, Content-Type")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Custom, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	assert.NotContains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "萬用來源搭配 credentials", cfg: Config{AllowedOrigins: []string{"*"}, AllowCredentials: true}},
+		{name: "缺少 scheme", cfg: Config{AllowedOrigins: []string{"etrex.tw"}}},
+		{name: "包含路徑", cfg: Config{AllowedOrigins: []string{"https://etrex.tw/app"}}},
+		{name: "萬用字元不在開頭", cfg: Config{AllowedOrigins: []string{"https://app.*.etrex.tw"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"https://a.com", "https://*.b.com"}, SplitList(" https://a.com, ,https://*.b.com "))
+	assert.Empty(t, SplitList(""))
+}
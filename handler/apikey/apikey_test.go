@@ -0,0 +1,181 @@
+package apikey
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := auth.NewMemoryKeyStore()
+	router := gin.New()
+	NewAPIKeyHandler(store).RegisterRoutes(router)
+
+	// 發行
+	body := bytes.NewBufferString(`{"name":"ci","scope":"write"}`)
+	req := httptest.NewRequest(http.MethodPost, "/admin/keys", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var issued IssueKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.Equal(t, "ci", issued.Name)
+	assert.Equal(t, auth.ScopeWrite, issued.Scope)
+	assert.NotEmpty(t, issued.Key)
+
+	// 列表不包含 secret
+	req = httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), issued.Key)
+	assert.Contains(t, w.Body.String(), issued.ID)
+
+	// 撤銷
+	req = httptest.NewRequest(http.MethodDelete, "/admin/keys/"+issued.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"message":"api key revoked successfully"}`, w.Body.String())
+
+	_, err := store.Lookup(issued.Key)
+	assert.ErrorIs(t, err, auth.ErrKeyNotFound)
+
+	req = httptest.NewRequest(http.MethodDelete, "/admin/keys/"+issued.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/api_key_not_found","code":"api_key_not_found","title":"Not Found","status":404,"detail":"api key not found","error":"api key not found"}`, w.Body.String())
+}
+
+func TestIssueKey_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewAPIKeyHandler(auth.NewMemoryKeyStore()).RegisterRoutes(router)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedBody string
+	}{
+		{name: "name 為空", body: `{"name":" ","scope":"read"}`, expectedBody: `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"name cannot be empty","errors":[{"field":"name","code":"blank","message":"name cannot be empty"}],"error":"name cannot be empty"}`},
+		{name: "未知的 scope", body: `{"name":"ci","scope":"root"}`, expectedBody: `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"scope must be read, write or admin","errors":[{"field":"scope","code":"enum","message":"scope must be read, write or admin"}],"error":"scope must be read, write or admin"}`},
+		{name: "不合法的 tenant", body: `{"name":"ci","scope":"read","tenant":"Acme Corp"}`, expectedBody: `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"invalid tenant","errors":[{"field":"tenant","code":"format","message":"invalid tenant"}],"error":"invalid tenant"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/keys", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestIssueKey_Tenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		p := &auth.Principal{ID: "admin-1", Scope: auth.ScopeAdmin, Tenant: "acme"}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	})
+	NewAPIKeyHandler(auth.NewMemoryKeyStore()).RegisterRoutes(router)
+
+	issue := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/keys", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 未指定時沿用發行者的 tenant
+	w := issue(`{"name":"ci","scope":"write"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var issued IssueKeyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.Equal(t, "acme", issued.Tenant)
+
+	w = issue(`{"name":"ci","scope":"write","tenant":"globex"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/tenant_mismatch","code":"tenant_mismatch","title":"Forbidden","status":403,"detail":"cannot issue keys for another tenant","error":"cannot issue keys for another tenant"}`, w.Body.String())
+}
+
+func TestListAndRevokeKeys_Tenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := auth.NewMemoryKeyStore()
+	_, bootstrap, err := store.Issue("bootstrap-admin", auth.ScopeAdmin, "")
+	require.NoError(t, err)
+	_, acmeKey, err := store.Issue("acme-ci", auth.ScopeWrite, "acme")
+	require.NoError(t, err)
+	_, globexKey, err := store.Issue("globex-ci", auth.ScopeWrite, "globex")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		p := &auth.Principal{ID: "admin-1", Scope: auth.ScopeAdmin, Tenant: "acme"}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	})
+	NewAPIKeyHandler(store).RegisterRoutes(router)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	// 只列出自己 tenant 的 key
+	w := do(http.MethodGet, "/admin/keys")
+	require.Equal(t, http.StatusOK, w.Code)
+	var keys []auth.APIKey
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &keys))
+	require.Len(t, keys, 1)
+	assert.Equal(t, acmeKey.ID, keys[0].ID)
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+	}{
+		{name: "不能撤銷 bootstrap admin key", id: bootstrap.ID, expectedStatus: http.StatusNotFound},
+		{name: "不能撤銷其他 tenant 的 key", id: globexKey.ID, expectedStatus: http.StatusNotFound},
+		{name: "不存在的 key", id: "missing", expectedStatus: http.StatusNotFound},
+		{name: "撤銷自己 tenant 的 key", id: acmeKey.ID, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(http.MethodDelete, "/admin/keys/"+tt.id)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	// 其他 tenant 的 key 不受影響
+	remaining, err := store.List()
+	require.NoError(t, err)
+	ids := make([]string, 0, len(remaining))
+	for _, key := range remaining {
+		ids = append(ids, key.ID)
+	}
+	assert.ElementsMatch(t, []string{bootstrap.ID, globexKey.ID}, ids)
+}
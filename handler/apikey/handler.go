@@ -0,0 +1,31 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+)
+
+type APIKeyHandler struct {
+	store auth.KeyStore
+}
+
+func NewAPIKeyHandler(store auth.KeyStore) *APIKeyHandler {
+	return &APIKeyHandler{
+		store: store,
+	}
+}
+
+// RegisterRoutes 將 API key 管理路由註冊到指定的 router（應掛在需要 admin scope 的 group 下）
+func (h *APIKeyHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/admin/keys", h.ListKeys)
+	r.POST("/admin/keys", h.IssueKey)
+	r.DELETE("/admin/keys/:id", h.RevokeKey)
+}
+
+// callerTenant 回傳呼叫者所屬的 tenant，屬於某個 tenant 的管理者只能管理該 tenant 的 key；空字串表示不限定
+func callerTenant(c *gin.Context) string {
+	if p := auth.PrincipalFrom(c); p != nil {
+		return p.Tenant
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/tenant"
+)
+
+// IssueKeyRequest 發行 API key 的請求內容
+type IssueKeyRequest struct {
+	Name  string     `json:"name" example:"ci-pipeline"`
+	Scope auth.Scope `json:"scope" example:"write" enums:"read,write,admin"`
+	// Tenant 限定此 key 只能存取的 tenant，發行者本身屬於某個 tenant 時預設為該 tenant
+	Tenant string `json:"tenant,omitempty" example:"acme"`
+}
+
+// IssueKeyResponse 發行 API key 的回應，key 只會在此時回傳一次
+type IssueKeyResponse struct {
+	auth.APIKey
+	Key string `json:"key" example:"tk_3f9a1c2b..."`
+}
+
+// IssueKey 處理發行新 API key 的 HTTP 請求
+// @Summary Issue an API key
+// @Description Issue a new API key scoped to read, write or admin, optionally limited to one tenant. The plaintext key is only returned once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body IssueKeyRequest true "Key name and scope"
+// @Success 201 {object} IssueKeyResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/keys [post]
+func (h *APIKeyHandler) IssueKey(c *gin.Context) {
+	var req IssueKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.Abort(c, render.BindError(fmt.Errorf("invalid JSON: %w", err)))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		problem.Abort(c, problem.Validation(model.FieldError{Field: "name", Code: "blank", Message: "name cannot be empty"}))
+		return
+	}
+
+	// 屬於某個 tenant 的管理者只能發行該 tenant 的 key
+	if p := auth.PrincipalFrom(c); p != nil && p.Tenant != "" {
+		if req.Tenant == "" {
+			req.Tenant = p.Tenant
+		}
+		if req.Tenant != p.Tenant {
+			problem.Abort(c, problem.New(http.StatusForbidden, "tenant_mismatch", "cannot issue keys for another tenant"))
+			return
+		}
+	}
+	if req.Tenant != "" && !tenant.Valid(req.Tenant) {
+		problem.Abort(c, problem.Validation(model.FieldError{Field: "tenant", Code: "format", Message: "invalid tenant"}))
+		return
+	}
+
+	secret, key, err := h.store.Issue(req.Name, req.Scope, req.Tenant)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) {
+			problem.Abort(c, problem.Validation(model.FieldError{Field: "scope", Code: "enum", Message: err.Error()}))
+			return
+		}
+		problem.Abort(c, problem.Unexpected(err, "failed to issue api key"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, IssueKeyResponse{APIKey: *key, Key: secret})
+}
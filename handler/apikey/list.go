@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/problem"
+)
+
+// ListKeys 處理列出所有 API key 的 HTTP 請求
+// @Summary List API keys
+// @Description List issued API keys. Secrets are never returned. Admins pinned to a tenant only see that tenant's keys.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} auth.APIKey
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.store.List()
+	if err != nil {
+		problem.Abort(c, problem.Unexpected(err, "failed to list api keys"))
+		return
+	}
+
+	if tenant := callerTenant(c); tenant != "" {
+		filtered := make([]auth.APIKey, 0, len(keys))
+		for _, key := range keys {
+			if key.Tenant == tenant {
+				filtered = append(filtered, key)
+			}
+		}
+		keys = filtered
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/problem"
+)
+
+// RevokeKey 處理撤銷 API key 的 HTTP 請求
+// @Summary Revoke an API key
+// @Description Revoke an API key by its ID. Requests using it are rejected immediately. Admins pinned to a tenant can only revoke that tenant's keys.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Key ID"
+// @Success 200 {object} model.MessageResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	id := c.Param("id")
+
+	// 其他 tenant 的 key 視為不存在，不透露 key 是否存在
+	if tenant := callerTenant(c); tenant != "" {
+		owned, err := h.ownedBy(id, tenant)
+		if err != nil {
+			problem.Abort(c, problem.Unexpected(err, "failed to revoke api key"))
+			return
+		}
+		if !owned {
+			abortKeyNotFound(c)
+			return
+		}
+	}
+
+	if err := h.store.Revoke(id); err != nil {
+		if errors.Is(err, auth.ErrKeyNotFound) {
+			abortKeyNotFound(c)
+			return
+		}
+		problem.Abort(c, problem.Unexpected(err, "failed to revoke api key"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked successfully"})
+}
+
+// ownedBy 回傳 key 是否存在且屬於 tenant
+func (h *APIKeyHandler) ownedBy(id, tenant string) (bool, error) {
+	keys, err := h.store.List()
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key.ID == id {
+			return key.Tenant == tenant, nil
+		}
+	}
+	return false, nil
+}
+
+func abortKeyNotFound(c *gin.Context) {
+	problem.Abort(c, problem.New(http.StatusNotFound, "api_key_not_found", "api key not found"))
+}
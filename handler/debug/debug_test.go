@@ -0,0 +1,29 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Default()
+	cfg.Auth.AdminKey = "tk_admin_secret"
+	router := gin.New()
+	NewDebugHandler(cfg).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"addr":":8080"`)
+	assert.Contains(t, w.Body.String(), `"admin_key":"[REDACTED]"`)
+	assert.NotContains(t, w.Body.String(), "tk_admin_secret")
+}
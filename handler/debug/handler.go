@@ -0,0 +1,39 @@
+package debug
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/config"
+)
+
+// DebugHandler 提供除錯用的唯讀端點，例如查詢生效中的設定
+type DebugHandler struct {
+	cfg *config.Config
+}
+
+// NewDebugHandler 建立除錯 handler；回傳的設定中 secret 會以 [REDACTED] 遮蔽
+func NewDebugHandler(cfg *config.Config) *DebugHandler {
+	return &DebugHandler{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes 將除錯路由註冊到指定的 router（應掛在需要 admin 權限的 group 下）
+func (h *DebugHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/debug/config", h.GetConfig)
+}
+
+// GetConfig 處理查詢生效中設定的 HTTP 請求
+// @Summary Show the effective configuration
+// @Description Show the configuration the server is running with, after applying defaults, the config file, environment variables and flags. Secrets are redacted.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} config.Config
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /debug/config [get]
+func (h *DebugHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cfg)
+}
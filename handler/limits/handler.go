@@ -0,0 +1,31 @@
+package limits
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/ratelimit"
+)
+
+type LimitsHandler struct {
+	limiter *ratelimit.Limiter
+}
+
+func NewLimitsHandler(limiter *ratelimit.Limiter) *LimitsHandler {
+	return &LimitsHandler{
+		limiter: limiter,
+	}
+}
+
+// RegisterRoutes 將速率限制管理路由註冊到指定的 router（應掛在需要 admin 權限的 group 下）
+func (h *LimitsHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/admin/rate-limits", h.GetLimits)
+	r.PUT("/admin/rate-limits/default", h.SetDefaultLimits)
+	r.PUT("/admin/rate-limits/keys/:id", h.SetKeyLimits)
+	r.DELETE("/admin/rate-limits/keys/:id", h.DeleteKeyLimits)
+}
+
+// LimitsResponse 目前的預設限制與個別 API key 的限制
+type LimitsResponse struct {
+	Default ratelimit.Limits `json:"default"`
+	// Overrides key 為 API key ID 或 JWT subject
+	Overrides map[string]ratelimit.Limits `json:"overrides"`
+}
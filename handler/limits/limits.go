@@ -0,0 +1,113 @@
+package limits
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
+)
+
+// GetLimits 處理查詢速率限制的 HTTP 請求
+// @Summary Get rate limits
+// @Description Get the default per-client rate limits and the per-key overrides.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} LimitsResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/rate-limits [get]
+func (h *LimitsHandler) GetLimits(c *gin.Context) {
+	c.JSON(http.StatusOK, LimitsResponse{
+		Default:   h.limiter.Defaults(),
+		Overrides: h.limiter.Overrides(),
+	})
+}
+
+// SetDefaultLimits 處理調整預設速率限制的 HTTP 請求
+// @Summary Set default rate limits
+// @Description Replace the limits applied to clients without an override. A rate of 0 disables that budget; daily_creates 0 disables the creation quota. Takes effect immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param limits body ratelimit.Limits true "New default limits"
+// @Success 200 {object} ratelimit.Limits
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/rate-limits/default [put]
+func (h *LimitsHandler) SetDefaultLimits(c *gin.Context) {
+	limits, ok := bindLimits(c)
+	if !ok {
+		return
+	}
+	if err := h.limiter.SetDefaults(limits); err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetKeyLimits 處理設定個別 API key 速率限制的 HTTP 請求
+// @Summary Set rate limits for a key
+// @Description Override the limits for one API key (by key ID) or JWT subject. Takes effect immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID or JWT subject"
+// @Param limits body ratelimit.Limits true "Limits for this key"
+// @Success 200 {object} ratelimit.Limits
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/rate-limits/keys/{id} [put]
+func (h *LimitsHandler) SetKeyLimits(c *gin.Context) {
+	limits, ok := bindLimits(c)
+	if !ok {
+		return
+	}
+	if err := h.limiter.SetOverride(c.Param("id"), limits); err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// DeleteKeyLimits 處理移除個別 API key 速率限制的 HTTP 請求
+// @Summary Remove rate limits for a key
+// @Description Remove the override for one key so the default limits apply again.
+// @Tags admin
+// @Produce json
+// @Param id path string true "API key ID or JWT subject"
+// @Success 200 {object} model.MessageResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/rate-limits/keys/{id} [delete]
+func (h *LimitsHandler) DeleteKeyLimits(c *gin.Context) {
+	if !h.limiter.RemoveOverride(c.Param("id")) {
+		problem.Abort(c, problem.New(http.StatusNotFound, "rate_limit_override_not_found", "rate limit override not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rate limit override removed successfully"})
+}
+
+func bindLimits(c *gin.Context) (ratelimit.Limits, bool) {
+	var limits ratelimit.Limits
+	if err := c.ShouldBindJSON(&limits); err != nil {
+		problem.Abort(c, render.BindError(fmt.Errorf("invalid JSON: %w", err)))
+		return limits, false
+	}
+	return limits, true
+}
@@ -0,0 +1,87 @@
+package limits
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := ratelimit.New(ratelimit.Limits{ReadRate: 50, ReadBurst: 100, WriteRate: 10, WriteBurst: 20})
+	require.NoError(t, err)
+	router := gin.New()
+	NewLimitsHandler(limiter).RegisterRoutes(router)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/admin/rate-limits", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"default":{"read_rate":50,"read_burst":100,"write_rate":10,"write_burst":20,"daily_creates":0},"overrides":{}}`, w.Body.String())
+
+	// 調整預設值與個別 key
+	w = do(http.MethodPut, "/admin/rate-limits/default", `{"read_rate":5,"read_burst":10,"write_rate":1,"write_burst":2,"daily_creates":100}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = do(http.MethodPut, "/admin/rate-limits/keys/key-1", `{"read_rate":500,"read_burst":1000}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = do(http.MethodGet, "/admin/rate-limits", "")
+	assert.JSONEq(t, `{
+		"default":{"read_rate":5,"read_burst":10,"write_rate":1,"write_burst":2,"daily_creates":100},
+		"overrides":{"key-1":{"read_rate":500,"read_burst":1000,"write_rate":0,"write_burst":0,"daily_creates":0}}
+	}`, w.Body.String())
+
+	w = do(http.MethodDelete, "/admin/rate-limits/keys/key-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"message":"rate limit override removed successfully"}`, w.Body.String())
+
+	w = do(http.MethodDelete, "/admin/rate-limits/keys/key-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/rate_limit_override_not_found","code":"rate_limit_override_not_found","title":"Not Found","status":404,"detail":"rate limit override not found","error":"rate limit override not found"}`, w.Body.String())
+}
+
+func TestSetLimits_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := ratelimit.New(ratelimit.Limits{})
+	require.NoError(t, err)
+	router := gin.New()
+	NewLimitsHandler(limiter).RegisterRoutes(router)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{name: "負的 rate", target: "/admin/rate-limits/default", body: `{"read_rate":-1,"read_burst":1}`},
+		{name: "有 rate 但沒有 burst", target: "/admin/rate-limits/keys/key-1", body: `{"write_rate":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.target, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			detail := ratelimit.ErrInvalidLimits.Error()
+			assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"`+detail+`","error":"`+detail+`"}`, w.Body.String())
+		})
+	}
+	assert.Equal(t, ratelimit.Limits{}, limiter.Defaults())
+	assert.Empty(t, limiter.Overrides())
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedMediaType 請求的 Content-Type 不支援
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// BindRaw 依 Content-Type 將請求 body 解析為 map，未指定 Content-Type 時視為 JSON
+// 數字一律轉為 float64，讓各格式的驗證行為與 JSON 一致
+// JSON 與 MessagePack 拒絕重複的 key；各格式都受 LimitBody 設定的巢狀層數限制
+func BindRaw(c *gin.Context) (map[string]interface{}, error) {
+	mediaType := MIMEJSON
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+		}
+		mediaType = parsed
+	}
+
+	var raw map[string]interface{}
+	maxDepth := MaxDepth(c)
+	switch mediaType {
+	case MIMEJSON:
+		dec := NewJSONDecoder(c.Request.Body, maxDepth)
+		var err error
+		if raw, err = dec.Decode(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		if err := dec.end(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return raw, nil
+	case MIMEYAML, MIMEXYAML:
+		// yaml.v3 沒有 token 串流，先解析為語法樹，檢查通過後才轉為 Go 的值（包含展開 alias）
+		var node yaml.Node
+		if err := yaml.NewDecoder(c.Request.Body).Decode(&node); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		if err := checkYAML(&node, maxDepth); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		if err := node.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case MIMEMsgPack, MIMEXMsgPack:
+		// 先在原始資料上檢查巢狀層數與重複的 key，通過後才解析
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MessagePack: %w", err)
+		}
+		if err := checkMsgPack(data, maxDepth); err != nil {
+			return nil, fmt.Errorf("invalid MessagePack: %w", err)
+		}
+		if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid MessagePack: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+
+	for key, value := range raw {
+		raw[key] = normalizeNumber(value)
+	}
+	return raw, nil
+}
+
+func normalizeNumber(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
@@ -0,0 +1,347 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONDecoder 以 token 串流解析 JSON 物件，拒絕重複的 key 與過深的巢狀結構
+// encoding/json 遇到重複的 key 會默默以後者覆蓋，也不限制巢狀層數
+type JSONDecoder struct {
+	dec      *json.Decoder
+	maxDepth int
+}
+
+// NewJSONDecoder 建立 JSONDecoder，maxDepth 不大於 0 時使用 DefaultMaxDepth
+func NewJSONDecoder(r io.Reader, maxDepth int) *JSONDecoder {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return &JSONDecoder{dec: json.NewDecoder(r), maxDepth: maxDepth}
+}
+
+// Decode 讀取下一個 JSON 物件，數字一律為 float64；沒有更多資料時回傳 io.EOF
+func (d *JSONDecoder) Decode() (map[string]interface{}, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, errors.New("expected a JSON object")
+	}
+	return d.object(1)
+}
+
+// end 確認物件之後沒有多餘的資料
+func (d *JSONDecoder) end() error {
+	_, err := d.dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.New("unexpected data after the JSON object")
+}
+
+func (d *JSONDecoder) object(depth int) (map[string]interface{}, error) {
+	if depth > d.maxDepth {
+		return nil, fmt.Errorf("nesting exceeds %d levels", d.maxDepth)
+	}
+
+	obj := map[string]interface{}{}
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := tok.(string)
+		if _, exists := obj[key]; exists {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+		if obj[key], err = d.value(depth); err != nil {
+			return nil, err
+		}
+	}
+	// 讀掉結尾的 '}'
+	if _, err := d.dec.Token(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (d *JSONDecoder) array(depth int) ([]interface{}, error) {
+	if depth > d.maxDepth {
+		return nil, fmt.Errorf("nesting exceeds %d levels", d.maxDepth)
+	}
+
+	arr := []interface{}{}
+	for d.dec.More() {
+		v, err := d.value(depth)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	if _, err := d.dec.Token(); err != nil {
+		return nil, err
+	}
+	return arr, nil
+}
+
+func (d *JSONDecoder) value(depth int) (interface{}, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		return d.object(depth + 1)
+	case json.Delim('['):
+		return d.array(depth + 1)
+	}
+	return tok, nil
+}
+
+// checkYAML 檢查 YAML 語法樹的巢狀層數與重複的 key，alias 以展開後的層數計算
+func checkYAML(root *yaml.Node, maxDepth int) error {
+	if root.Kind == 0 {
+		return nil
+	}
+	_, err := (&yamlChecker{maxDepth: maxDepth, heights: make(map[*yaml.Node]int)}).height(root, 0)
+	return err
+}
+
+// yamlChecker 計算語法樹各節點的高度；同一節點只計算一次，被大量 alias 引用時也不會重複走訪
+type yamlChecker struct {
+	maxDepth int
+	heights  map[*yaml.Node]int
+}
+
+// visiting 標記計算中的節點，用於偵測引用自己的 alias
+const visiting = -1
+
+// height 回傳節點展開後的巢狀層數（純量為 0），depth 為節點外層的集合數
+func (y *yamlChecker) height(n *yaml.Node, depth int) (int, error) {
+	if h, ok := y.heights[n]; ok {
+		if h == visiting {
+			return 0, errors.New("anchor contains itself")
+		}
+		if depth+h > y.maxDepth {
+			return 0, y.tooDeep()
+		}
+		return h, nil
+	}
+	y.heights[n] = visiting
+
+	var h int
+	var err error
+	switch n.Kind {
+	case yaml.DocumentNode:
+		h, err = y.children(n.Content, depth)
+	case yaml.AliasNode:
+		h, err = y.height(n.Alias, depth)
+	case yaml.SequenceNode, yaml.MappingNode:
+		if depth+1 > y.maxDepth {
+			return 0, y.tooDeep()
+		}
+		if n.Kind == yaml.MappingNode {
+			if err := duplicateKey(n); err != nil {
+				return 0, err
+			}
+		}
+		h, err = y.children(n.Content, depth+1)
+		h++
+	}
+	if err != nil {
+		return 0, err
+	}
+	if depth+h > y.maxDepth {
+		return 0, y.tooDeep()
+	}
+	y.heights[n] = h
+	return h, nil
+}
+
+func (y *yamlChecker) tooDeep() error {
+	return fmt.Errorf("nesting exceeds %d levels", y.maxDepth)
+}
+
+// children 回傳子節點中最大的高度
+func (y *yamlChecker) children(nodes []*yaml.Node, depth int) (int, error) {
+	highest := 0
+	for _, child := range nodes {
+		h, err := y.height(child, depth)
+		if err != nil {
+			return 0, err
+		}
+		highest = max(highest, h)
+	}
+	return highest, nil
+}
+
+// duplicateKey 檢查 mapping 中重複的純量 key，合併用的 "<<" 不算
+func duplicateKey(n *yaml.Node) error {
+	keys := make(map[string]bool, len(n.Content)/2)
+	for i := 0; i < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if key.Kind != yaml.ScalarNode || key.Tag == "!!merge" {
+			continue
+		}
+		if keys[key.Value] {
+			return fmt.Errorf("duplicate key %q", key.Value)
+		}
+		keys[key.Value] = true
+	}
+	return nil
+}
+
+// checkMsgPack 走訪 MessagePack 原始資料，拒絕重複的 key 與過深的巢狀結構
+// codec 解析為 map 時遇到重複的 key 會默默以後者覆蓋，因此需在解析後另外檢查原始資料
+func checkMsgPack(data []byte, maxDepth int) error {
+	_, err := (&msgpackWalker{data: data, maxDepth: maxDepth}).value(0)
+	return err
+}
+
+var errMsgPackTruncated = errors.New("unexpected end of data")
+
+// msgpackWalker 只檢查結構的 MessagePack 讀取器，不建立解析後的值
+type msgpackWalker struct {
+	data     []byte
+	pos      int
+	maxDepth int
+}
+
+// next 讀取 n 個 byte
+func (w *msgpackWalker) next(n int) ([]byte, error) {
+	if n < 0 || len(w.data)-w.pos < n {
+		return nil, errMsgPackTruncated
+	}
+	b := w.data[w.pos : w.pos+n]
+	w.pos += n
+	return b, nil
+}
+
+// length 讀取 n 個 byte 的 big-endian 長度
+func (w *msgpackWalker) length(n int) (int, error) {
+	b, err := w.next(n)
+	if err != nil {
+		return 0, err
+	}
+	size := 0
+	for _, v := range b {
+		size = size<<8 | int(v)
+	}
+	return size, nil
+}
+
+// value 略過下一個值，值為字串時回傳其內容供檢查重複的 key
+func (w *msgpackWalker) value(depth int) (string, error) {
+	head, err := w.next(1)
+	if err != nil {
+		return "", err
+	}
+	b := head[0]
+
+	var size, skip int
+	switch {
+	case b <= 0x7f || b >= 0xe0, b == 0xc0, b == 0xc2, b == 0xc3:
+		return "", nil
+	case b&0xf0 == 0x80:
+		return "", w.mapValue(depth+1, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return "", w.array(depth+1, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return w.str(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xd9, 0xda, 0xdb: // str 8/16/32
+		if size, err = w.length(1 << (b - 0xd9)); err != nil {
+			return "", err
+		}
+		return w.str(size)
+	case 0xdc, 0xdd: // array 16/32
+		if size, err = w.length(2 << (b - 0xdc)); err != nil {
+			return "", err
+		}
+		return "", w.array(depth+1, size)
+	case 0xde, 0xdf: // map 16/32
+		if size, err = w.length(2 << (b - 0xde)); err != nil {
+			return "", err
+		}
+		return "", w.mapValue(depth+1, size)
+	case 0xc4, 0xc5, 0xc6: // bin 8/16/32
+		skip, err = w.length(1 << (b - 0xc4))
+	case 0xc7, 0xc8, 0xc9: // ext 8/16/32，長度之後還有 1 byte 的型別
+		skip, err = w.length(1 << (b - 0xc7))
+		skip++
+	case 0xca, 0xcb: // float 32/64
+		skip = 4 << (b - 0xca)
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8/16/32/64
+		skip = 1 << (b - 0xcc)
+	case 0xd0, 0xd1, 0xd2, 0xd3: // int 8/16/32/64
+		skip = 1 << (b - 0xd0)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1/2/4/8/16
+		skip = 1<<(b-0xd4) + 1
+	default:
+		return "", fmt.Errorf("invalid type byte 0x%x", b)
+	}
+	if err != nil {
+		return "", err
+	}
+	_, err = w.next(skip)
+	return "", err
+}
+
+func (w *msgpackWalker) str(size int) (string, error) {
+	b, err := w.next(size)
+	return string(b), err
+}
+
+func (w *msgpackWalker) array(depth, size int) error {
+	if depth > w.maxDepth {
+		return fmt.Errorf("nesting exceeds %d levels", w.maxDepth)
+	}
+	for i := 0; i < size; i++ {
+		if _, err := w.value(depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *msgpackWalker) mapValue(depth, size int) error {
+	if depth > w.maxDepth {
+		return fmt.Errorf("nesting exceeds %d levels", w.maxDepth)
+	}
+	keys := make(map[string]bool, min(size, 64))
+	for i := 0; i < size; i++ {
+		start := w.pos
+		key, err := w.value(depth)
+		if err != nil {
+			return err
+		}
+		// 字串以內容比較，其他型別的 key 以原始編碼比較
+		if !isMsgPackStr(w.data[start]) {
+			key = "\x00" + string(w.data[start:w.pos])
+		}
+		if keys[key] {
+			return fmt.Errorf("duplicate key %q", strings.TrimPrefix(key, "\x00"))
+		}
+		keys[key] = true
+		if _, err := w.value(depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMsgPackStr(b byte) bool {
+	return b&0xe0 == 0xa0 || (b >= 0xd9 && b <= 0xdb)
+}
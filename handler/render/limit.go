@@ -0,0 +1,78 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+)
+
+// DefaultMaxDepth 未經 LimitBody 設定時的巢狀層數上限
+const DefaultMaxDepth = 32
+
+// maxDepthKey 儲存巢狀層數上限的 gin context key
+const maxDepthKey = "render.maxDepth"
+
+// BodyLimits 請求 body 的大小與巢狀層數上限
+type BodyLimits struct {
+	// MaxSize 預設的 body 大小上限（位元組）
+	MaxSize int64
+	// Routes 個別路由的大小上限，key 為 "METHOD /路由樣式"，例如 "POST /tasks/import"
+	Routes map[string]int64
+	// MaxDepth JSON 物件與陣列的巢狀層數上限
+	MaxDepth int
+}
+
+// LimitBody 限制請求 body 的大小，超過時回傳 413
+// Content-Length 已超過上限時直接拒絕；其餘情況在讀取時由 http.MaxBytesReader 中止，
+// 呼叫端以 BindError 轉換讀取錯誤
+func LimitBody(l BodyLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.MaxDepth > 0 {
+			c.Set(maxDepthKey, l.MaxDepth)
+		}
+
+		limit := l.MaxSize
+		if n, ok := l.Routes[c.Request.Method+" "+c.FullPath()]; ok {
+			limit = n
+		}
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			problem.Abort(c, tooLarge(limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// MaxDepth 回傳目前請求的巢狀層數上限
+func MaxDepth(c *gin.Context) int {
+	if depth := c.GetInt(maxDepthKey); depth > 0 {
+		return depth
+	}
+	return DefaultMaxDepth
+}
+
+// BindError 將解析請求 body 的錯誤轉為 problem
+// 超過大小上限回傳 413，不支援的 Content-Type 回傳 415，其餘為 400
+func BindError(err error) *problem.Error {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		return tooLarge(maxBytes.Limit)
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, err.Error())
+	}
+	return problem.New(http.StatusBadRequest, problem.CodeMalformedBody, err.Error())
+}
+
+func tooLarge(limit int64) *problem.Error {
+	return problem.New(http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit))
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	ginrender "github.com/gin-gonic/gin/render"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/storage"
+	"github.com/ugorji/go/codec"
+)
+
+// 支援的 media type
+const (
+	MIMEJSON     = "application/json"
+	MIMEYAML     = "application/yaml"
+	MIMEXYAML    = "application/x-yaml"
+	MIMEMsgPack  = "application/msgpack"
+	MIMEXMsgPack = "application/x-msgpack"
+	MIMECSV      = "text/csv"
+)
+
+// formatKey 儲存協商結果的 gin context key
+const formatKey = "render.format"
+
+// msgpackHandle 使用新版 msgpack 規格，字串以 str 型別編碼與解碼
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+	h.RawToString = true
+	return h
+}()
+
+// Negotiate 依 Accept header 選擇回應格式，無法滿足時回傳 406
+// allowCSV 只應在回傳任務清單的路由上開啟
+func Negotiate(allowCSV bool) gin.HandlerFunc {
+	offered := []string{MIMEJSON, MIMEYAML, MIMEXYAML, MIMEMsgPack, MIMEXMsgPack}
+	if allowCSV {
+		offered = append(offered, MIMECSV)
+	}
+
+	return func(c *gin.Context) {
+		format := c.NegotiateFormat(offered...)
+		if format == "" {
+			problem.Abort(c, problem.New(http.StatusNotAcceptable, problem.CodeNotAcceptable, "not acceptable"))
+			return
+		}
+		c.Set(formatKey, format)
+		c.Next()
+	}
+}
+
+// Render 以協商出的格式寫出回應，未經協商時使用 JSON
+func Render(c *gin.Context, code int, obj interface{}) {
+	if result, ok := obj.(*storage.PaginationResult); ok {
+		setPaginationHeaders(c, result.Pagination)
+	}
+
+	switch c.GetString(formatKey) {
+	case MIMEYAML, MIMEXYAML:
+		c.YAML(code, obj)
+	case MIMEMsgPack, MIMEXMsgPack:
+		c.Render(code, msgPack{data: obj})
+	case MIMECSV:
+		if tasks, ok := taskList(obj); ok {
+			c.Render(code, csvTasks{tasks: tasks})
+			return
+		}
+		// 錯誤訊息等非清單資料無法以 CSV 表示，改用 JSON
+		c.JSON(code, obj)
+	default:
+		c.JSON(code, obj)
+	}
+}
+
+func taskList(obj interface{}) ([]model.Task, bool) {
+	switch v := obj.(type) {
+	case *storage.PaginationResult:
+		return v.Data, true
+	case []model.Task:
+		return v, true
+	}
+	return nil, false
+}
+
+// setPaginationHeaders 將分頁資訊放到 header，讓 CSV 等無法攜帶 metadata 的格式也能分頁
+func setPaginationHeaders(c *gin.Context, p storage.PaginationInfo) {
+	c.Header("X-Page", strconv.Itoa(p.Page))
+	c.Header("X-Per-Page", strconv.Itoa(p.Limit))
+	c.Header("X-Total-Count", strconv.Itoa(p.Total))
+	c.Header("X-Total-Pages", strconv.Itoa(p.Pages))
+}
+
+// msgPack 以 msgpackHandle 編碼的 render
+type msgPack struct {
+	data interface{}
+}
+
+func (r msgPack) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return codec.NewEncoder(w, msgpackHandle).Encode(r.data)
+}
+
+func (r msgPack) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", MIMEMsgPack)
+}
+
+// csvTasks 將任務清單寫成 CSV 的 render
+type csvTasks struct {
+	tasks []model.Task
+}
+
+func (r csvTasks) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(model.CSVHeader); err != nil {
+		return err
+	}
+	for _, t := range r.tasks {
+		if err := cw.Write(model.CSVRecord(t)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r csvTasks) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", MIMECSV+"; charset=utf-8")
+}
+
+var (
+	_ ginrender.Render = msgPack{}
+	_ ginrender.Render = csvTasks{}
+)
@@ -0,0 +1,391 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	result := &storage.PaginationResult{
+		Data: []model.Task{
+			{ID: "1", Name: "Task 1", Status: 0},
+			{ID: "2", Name: "Task, with comma", Status: 1},
+		},
+		Pagination: storage.PaginationInfo{Page: 1, Limit: 100, Total: 2, Pages: 1},
+	}
+
+	r := gin.New()
+	r.GET("/list", Negotiate(true), func(c *gin.Context) {
+		Render(c, http.StatusOK, result)
+	})
+	r.GET("/single", Negotiate(false), func(c *gin.Context) {
+		Render(c, http.StatusOK, result.Data[0])
+	})
+	r.POST("/echo", Negotiate(false), func(c *gin.Context) {
+		raw, err := BindRaw(c)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, ErrUnsupportedMediaType) {
+				status = http.StatusUnsupportedMediaType
+			}
+			Render(c, status, gin.H{"error": err.Error()})
+			return
+		}
+		// 數字需統一為 float64
+		if _, ok := raw["status"].(float64); !ok {
+			Render(c, http.StatusBadRequest, gin.H{"error": "status is not float64"})
+			return
+		}
+		Render(c, http.StatusOK, raw)
+	})
+	return r
+}
+
+func TestRender(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		name                string
+		path                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "預設回傳 JSON",
+			path:                "/single",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"id":"1","name":"Task 1","status":0}`,
+		},
+		{
+			name:                "YAML 清單",
+			path:                "/list",
+			accept:              "application/yaml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/yaml; charset=utf-8",
+			expectedBody:        "has_next: false",
+		},
+		{
+			name:                "CSV 清單",
+			path:                "/list",
+			accept:              "text/csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,name,status,due\n1,Task 1,0,\n2,\"Task, with comma\",1,\n",
+		},
+		{
+			name:           "單筆資料不支援 CSV",
+			path:           "/single",
+			accept:         "text/csv",
+			expectedStatus: http.StatusNotAcceptable,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/not_acceptable","code":"not_acceptable","title":"Not Acceptable","status":406,"detail":"not acceptable","error":"not acceptable"}`,
+		},
+		{
+			name:           "不支援的格式",
+			path:           "/list",
+			accept:         "application/xml",
+			expectedStatus: http.StatusNotAcceptable,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/not_acceptable","code":"not_acceptable","title":"Not Acceptable","status":406,"detail":"not acceptable","error":"not acceptable"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestRender_PaginationHeaders(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/list", nil)
+	req.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "1", w.Header().Get("X-Page"))
+	assert.Equal(t, "100", w.Header().Get("X-Per-Page"))
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "1", w.Header().Get("X-Total-Pages"))
+}
+
+func TestRender_MsgPack(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/single", nil)
+	req.Header.Set("Accept", "application/msgpack")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MIMEMsgPack, w.Header().Get("Content-Type"))
+
+	var task model.Task
+	require.NoError(t, codec.NewDecoder(w.Body, msgpackHandle).Decode(&task))
+	assert.Equal(t, model.Task{ID: "1", Name: "Task 1", Status: 0}, task)
+}
+
+func TestBindRaw(t *testing.T) {
+	router := newTestRouter()
+
+	var msgpackBody bytes.Buffer
+	require.NoError(t, codec.NewEncoder(&msgpackBody, msgpackHandle).Encode(map[string]interface{}{"name": "Task", "status": 1}))
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           []byte
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "JSON",
+			contentType:    "application/json",
+			body:           []byte(`{"name":"Task","status":1}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"Task","status":1}`,
+		},
+		{
+			name:           "YAML",
+			contentType:    "application/yaml",
+			body:           []byte("name: Task\nstatus: 1\n"),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"Task","status":1}`,
+		},
+		{
+			name:           "MessagePack",
+			contentType:    "application/msgpack",
+			body:           msgpackBody.Bytes(),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"Task","status":1}`,
+		},
+		{
+			name:           "重複的 key",
+			contentType:    "application/json",
+			body:           []byte(`{"name":"Task","status":1,"name":"Other"}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid JSON: duplicate key \"name\""}`,
+		},
+		{
+			name:           "YAML 重複的 key",
+			contentType:    "application/yaml",
+			body:           []byte("name: Task\nstatus: 1\nname: Other\n"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid YAML: duplicate key \"name\""}`,
+		},
+		{
+			name:           "MessagePack 重複的 key",
+			contentType:    "application/msgpack",
+			body:           []byte("\x83\xa4name\xa4Task\xa6status\x01\xa4name\xa5Other"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid MessagePack: duplicate key \"name\""}`,
+		},
+		{
+			name:           "MessagePack 巢狀物件中重複的 key",
+			contentType:    "application/msgpack",
+			body:           []byte("\x83\xa4name\xa4Task\xa6status\x01\xa4tags\x82\xa1a\x01\xd9\x01a\x02"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid MessagePack: duplicate key \"a\""}`,
+		},
+		{
+			name:           "物件後有多餘的資料",
+			contentType:    "application/json",
+			body:           []byte(`{"name":"Task","status":1}{"name":"Other"}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid JSON: unexpected data after the JSON object"}`,
+		},
+		{
+			name:           "JSON 巢狀過深",
+			contentType:    "application/json",
+			body:           []byte(`{"name":"Task","status":1,"tags":[` + strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth) + `]}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid JSON: nesting exceeds 32 levels"}`,
+		},
+		{
+			name:           "YAML 巢狀過深",
+			contentType:    "application/yaml",
+			body:           []byte("name: Task\nstatus: 1\ntags: " + strings.Repeat("[", DefaultMaxDepth) + strings.Repeat("]", DefaultMaxDepth) + "\n"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid YAML: nesting exceeds 32 levels"}`,
+		},
+		{
+			name:           "MessagePack 巢狀過深",
+			contentType:    "application/msgpack",
+			body:           []byte("\x83\xa4name\xa4Task\xa6status\x01\xa4tags\x91" + strings.Repeat("\x91", DefaultMaxDepth-1) + "\x90"),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid MessagePack: nesting exceeds 32 levels"}`,
+		},
+		{
+			name:           "YAML alias 展開後巢狀過深",
+			contentType:    "application/yaml",
+			body:           []byte("name: Task\nstatus: 1\n" + yamlAliasChain(DefaultMaxDepth)),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid YAML: nesting exceeds 32 levels"}`,
+		},
+		{
+			name:           "不支援的 Content-Type",
+			contentType:    "application/xml",
+			body:           []byte(`<task/>`),
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   `{"error":"unsupported media type: application/xml"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(problem.Middleware(problem.Options{}))
+	router.Use(LimitBody(BodyLimits{
+		MaxSize:  32,
+		MaxDepth: 2,
+		Routes:   map[string]int64{"POST /import": 64},
+	}))
+	bind := func(c *gin.Context) {
+		raw, err := BindRaw(c)
+		if err != nil {
+			problem.Abort(c, BindError(err))
+			return
+		}
+		c.JSON(http.StatusOK, raw)
+	}
+	router.POST("/tasks", bind)
+	router.POST("/import", bind)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "未超過上限",
+			path:           "/tasks",
+			body:           `{"name":"Task","status":1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"Task","status":1}`,
+		},
+		{
+			name:           "Content-Length 超過上限",
+			path:           "/tasks",
+			body:           `{"name":"` + strings.Repeat("a", 32) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/payload_too_large","code":"payload_too_large","title":"Request Entity Too Large","status":413,"detail":"request body exceeds 32 bytes"}`,
+		},
+		{
+			name:           "未帶 Content-Length 時讀取中止",
+			path:           "/tasks",
+			body:           `{"name":"` + strings.Repeat("a", 32) + `"}`,
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/payload_too_large","code":"payload_too_large","title":"Request Entity Too Large","status":413,"detail":"request body exceeds 32 bytes"}`,
+		},
+		{
+			name:           "個別路由的上限",
+			path:           "/import",
+			body:           `{"name":"` + strings.Repeat("a", 32) + `"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"` + strings.Repeat("a", 32) + `"}`,
+		},
+		{
+			name:           "設定的巢狀層數",
+			path:           "/tasks",
+			body:           `{"tags":[[1]]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/malformed_body","code":"malformed_body","title":"Bad Request","status":400,"detail":"invalid JSON: nesting exceeds 2 levels"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestJSONDecoder(t *testing.T) {
+	dec := NewJSONDecoder(strings.NewReader(`{"name":"Task","status":1,"meta":{"tags":["a"]}}
+{"name":"Other","status":0}
+`), 0)
+
+	raw, err := dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Task", "status": float64(1), "meta": map[string]interface{}{"tags": []interface{}{"a"}}}, raw)
+
+	raw, err = dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Other", "status": float64(0)}, raw)
+
+	_, err = dec.Decode()
+	assert.Equal(t, io.EOF, err)
+
+	// 巢狀物件中重複的 key
+	_, err = NewJSONDecoder(strings.NewReader(`{"meta":{"a":1,"a":2}}`), 0).Decode()
+	assert.EqualError(t, err, `duplicate key "a"`)
+
+	_, err = NewJSONDecoder(strings.NewReader(`["Task"]`), 0).Decode()
+	assert.EqualError(t, err, "expected a JSON object")
+}
+
+// yamlAliasChain 產生 n 個 anchor，每個都以 alias 包住前一個，文字只有兩層但展開後有 n 層
+func yamlAliasChain(n int) string {
+	var b strings.Builder
+	b.WriteString("x0: &a0 [1]\n")
+	for i := 1; i < n; i++ {
+		fmt.Fprintf(&b, "x%d: &a%d [*a%d]\n", i, i, i-1)
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package snapshots
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/problem"
+)
+
+// CreateSnapshot 處理建立快照的 HTTP 請求
+// @Summary Create a snapshot
+// @Description Save a point-in-time copy of every task, including the trash and shares of every tenant. Writes are only blocked while the storage marks its data as shared. Only the newest snapshots are kept (storage.snapshot_keep).
+// @Tags admin
+// @Produce json
+// @Success 201 {object} snapshot.Info
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/snapshots [post]
+func (h *SnapshotHandler) CreateSnapshot(c *gin.Context) {
+	snap, err := h.storage.Snapshot(c.Request.Context())
+	if err != nil {
+		problem.Abort(c, problem.Unexpected(err, "failed to create snapshot"))
+		return
+	}
+
+	info, err := h.store.Save(snap)
+	if err != nil {
+		problem.Abort(c, problem.Unexpected(err, "failed to save snapshot"))
+		return
+	}
+	logging.FromContext(c.Request.Context()).Info("created snapshot", "snapshot", info.ID, "tasks", info.Tasks, "size", info.Size)
+
+	c.JSON(http.StatusCreated, info)
+}
@@ -0,0 +1,36 @@
+package snapshots
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/snapshot"
+)
+
+// DownloadSnapshot 處理下載快照檔案的 HTTP 請求
+// @Summary Download a snapshot
+// @Description Download a snapshot file. The first line is a JSON header with the format version and the SHA-256 checksum of the second line, which holds the tasks.
+// @Tags admin
+// @Produce application/vnd.task-api.snapshot
+// @Param id path string true "Snapshot ID"
+// @Success 200 {file} file
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/snapshots/{id} [get]
+func (h *SnapshotHandler) DownloadSnapshot(c *gin.Context) {
+	f, info, err := h.store.Open(c.Param("id"))
+	if err != nil {
+		problem.Abort(c, snapshotProblem(err, "failed to open snapshot"))
+		return
+	}
+	defer f.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, snapshot.MIMEType, f, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s%s"`, info.ID, snapshot.Extension),
+	})
+}
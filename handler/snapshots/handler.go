@@ -0,0 +1,52 @@
+package snapshots
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/snapshot"
+	"github.com/gogolook/task-api/storage"
+)
+
+// 快照相關的錯誤代碼
+const (
+	codeSnapshotNotFound = "snapshot_not_found"
+	codeInvalidSnapshot  = "invalid_snapshot"
+	codeConfirmRequired  = "confirmation_required"
+)
+
+type SnapshotHandler struct {
+	storage storage.Snapshotter
+	store   *snapshot.Store
+}
+
+func NewSnapshotHandler(s storage.Snapshotter, store *snapshot.Store) *SnapshotHandler {
+	return &SnapshotHandler{
+		storage: s,
+		store:   store,
+	}
+}
+
+// RegisterRoutes 將快照管理路由註冊到指定的 router（應掛在需要 admin scope 的 group 下）
+func (h *SnapshotHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/admin/snapshots", h.ListSnapshots)
+	r.POST("/admin/snapshots", h.CreateSnapshot)
+	r.GET("/admin/snapshots/:id", h.DownloadSnapshot)
+	r.POST("/admin/snapshots/:id/restore", h.RestoreSnapshot)
+}
+
+// snapshotProblem 將快照的錯誤轉為 problem：不存在回傳 404，檔案損毀或與目前的 storage 不相容回傳 422
+func snapshotProblem(err error, detail string) *problem.Error {
+	switch {
+	case errors.Is(err, snapshot.ErrNotFound):
+		return problem.New(http.StatusNotFound, codeSnapshotNotFound, snapshot.ErrNotFound.Error())
+	case errors.Is(err, snapshot.ErrChecksumMismatch),
+		errors.Is(err, snapshot.ErrInvalidFormat),
+		errors.Is(err, snapshot.ErrUnsupportedVersion),
+		errors.Is(err, storage.ErrInvalidSnapshot):
+		return problem.New(http.StatusUnprocessableEntity, codeInvalidSnapshot, err.Error())
+	}
+	return problem.Unexpected(err, detail)
+}
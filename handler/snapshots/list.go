@@ -0,0 +1,29 @@
+package snapshots
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
+)
+
+// ListSnapshots 處理列出快照的 HTTP 請求
+// @Summary List snapshots
+// @Description List the saved snapshots, newest first.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} snapshot.Info
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/snapshots [get]
+func (h *SnapshotHandler) ListSnapshots(c *gin.Context) {
+	infos, err := h.store.List()
+	if err != nil {
+		problem.Abort(c, problem.Unexpected(err, "failed to list snapshots"))
+		return
+	}
+
+	c.JSON(http.StatusOK, infos)
+}
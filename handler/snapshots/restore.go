@@ -0,0 +1,49 @@
+package snapshots
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+)
+
+// RestoreSnapshot 處理還原快照的 HTTP 請求
+// 請求需帶 X-Confirm: restore-snapshot；快照之後的所有修改都會被取代
+// @Summary Restore a snapshot
+// @Description Replace every task, including the trash and shares of every tenant, with the content of a snapshot. The checksum is verified first. Changes made after the snapshot are lost, so take a new snapshot first if they may be needed.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Snapshot ID"
+// @Param X-Confirm header string true "Must be restore-snapshot" Enums(restore-snapshot)
+// @Success 200 {object} model.RestoreResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Failure 428 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/snapshots/{id}/restore [post]
+func (h *SnapshotHandler) RestoreSnapshot(c *gin.Context) {
+	if c.GetHeader(model.ConfirmHeader) != model.ConfirmRestoreSnapshot {
+		problem.Abort(c, problem.New(http.StatusPreconditionRequired, codeConfirmRequired,
+			model.ConfirmHeader+": "+model.ConfirmRestoreSnapshot+" header is required to restore a snapshot"))
+		return
+	}
+
+	id := c.Param("id")
+	snap, err := h.store.Load(id)
+	if err != nil {
+		problem.Abort(c, snapshotProblem(err, "failed to load snapshot"))
+		return
+	}
+	if err := h.storage.RestoreSnapshot(c.Request.Context(), snap); err != nil {
+		problem.Abort(c, snapshotProblem(err, "failed to restore snapshot"))
+		return
+	}
+	logging.FromContext(c.Request.Context()).Warn("restored snapshot", "snapshot", id, "tasks", snap.Count())
+
+	c.JSON(http.StatusOK, model.RestoreResponse{Message: "Snapshot restored successfully", Restored: snap.Count()})
+}
@@ -0,0 +1,175 @@
+package snapshots
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/snapshot"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	all := storage.Access{All: true}
+
+	memStorage := storage.NewMemoryStorage()
+	dir := t.TempDir()
+	store, err := snapshot.NewStore(dir, 3)
+	require.NoError(t, err)
+	router := gin.New()
+	NewSnapshotHandler(memStorage, store).RegisterRoutes(router)
+
+	do := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	confirm := http.Header{model.ConfirmHeader: {model.ConfirmRestoreSnapshot}}
+
+	task := &model.Task{Name: "Before Migration"}
+	require.NoError(t, memStorage.Create(ctx, all, task))
+
+	// 建立
+	w := do(http.MethodPost, "/admin/snapshots", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created snapshot.Info
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, 1, created.Tasks)
+
+	// 列表
+	w = do(http.MethodGet, "/admin/snapshots", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var infos []snapshot.Info
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &infos))
+	assert.Equal(t, []snapshot.Info{created}, infos)
+
+	// 下載的檔案可以解析並通過 checksum 驗證
+	w = do(http.MethodGet, "/admin/snapshots/"+created.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, snapshot.MIMEType, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="`+created.ID+`.snapshot"`, w.Header().Get("Content-Disposition"))
+	snap, header, err := snapshot.Decode(bytes.NewReader(w.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, created.Checksum, header.Checksum)
+	assert.Equal(t, "Before Migration", snap.Partitions[0].Tasks[0].Name)
+
+	// 還原需要確認 header
+	require.NoError(t, memStorage.Update(ctx, all, task.ID, &model.Task{Name: "After Migration"}))
+	require.NoError(t, memStorage.Create(ctx, all, &model.Task{Name: "Created Later"}))
+	w = do(http.MethodPost, "/admin/snapshots/"+created.ID+"/restore", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/confirmation_required","code":"confirmation_required","title":"Precondition Required","status":428,"detail":"X-Confirm: restore-snapshot header is required to restore a snapshot","error":"X-Confirm: restore-snapshot header is required to restore a snapshot"}`, w.Body.String())
+
+	w = do(http.MethodPost, "/admin/snapshots/"+created.ID+"/restore", confirm)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"message":"Snapshot restored successfully","restored":1}`, w.Body.String())
+	got, err := memStorage.Get(ctx, all, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Before Migration", got.Name)
+	assert.Equal(t, 1, memStorage.Count())
+
+	// 不存在的快照
+	w = do(http.MethodGet, "/admin/snapshots/20261019T090000Z-00000000", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/snapshot_not_found","code":"snapshot_not_found","title":"Not Found","status":404,"detail":"snapshot not found","error":"snapshot not found"}`, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/admin/snapshots/latest/restore", confirm).Code)
+
+	// 被修改的快照不會還原
+	path := filepath.Join(dir, created.ID+snapshot.Extension)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte("Before Migration"), []byte("Tampered Tasks!!"), 1), 0o600))
+	w = do(http.MethodPost, "/admin/snapshots/"+created.ID+"/restore", confirm)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"invalid_snapshot"`)
+	assert.Contains(t, w.Body.String(), "snapshot checksum mismatch")
+}
+
+func TestRestoreSnapshot_TenancyMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := snapshot.NewStore(t.TempDir(), 3)
+	require.NoError(t, err)
+	snap, err := storage.NewMemoryStorage().Snapshot(context.Background())
+	require.NoError(t, err)
+	info, err := store.Save(snap)
+	require.NoError(t, err)
+
+	// 未啟用多租戶時的快照無法還原到多租戶的 storage
+	router := gin.New()
+	NewSnapshotHandler(storage.NewTenantStorage(0, nil), store).RegisterRoutes(router)
+	req := httptest.NewRequest(http.MethodPost, "/admin/snapshots/"+info.ID+"/restore", nil)
+	req.Header.Set(model.ConfirmHeader, model.ConfirmRestoreSnapshot)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid snapshot: snapshot was taken with tenancy disabled")
+}
+
+func TestSnapshotRoutes_TenantAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := snapshot.NewStore(t.TempDir(), 3)
+	require.NoError(t, err)
+	snap, err := storage.NewTenantStorage(0, nil).Snapshot(context.Background())
+	require.NoError(t, err)
+	info, err := store.Save(snap)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		tenant         string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{name: "tenant admin 不能列出", tenant: "acme", method: http.MethodGet, path: "/admin/snapshots", expectedStatus: http.StatusForbidden},
+		{name: "tenant admin 不能建立", tenant: "acme", method: http.MethodPost, path: "/admin/snapshots", expectedStatus: http.StatusForbidden},
+		{name: "tenant admin 不能下載", tenant: "acme", method: http.MethodGet, path: "/admin/snapshots/" + info.ID, expectedStatus: http.StatusForbidden},
+		{name: "tenant admin 不能還原", tenant: "acme", method: http.MethodPost, path: "/admin/snapshots/" + info.ID + "/restore", expectedStatus: http.StatusForbidden},
+		{name: "不限定 tenant 的 admin 可以列出", method: http.MethodGet, path: "/admin/snapshots", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				p := &auth.Principal{ID: "admin-1", Scope: auth.ScopeAdmin, Tenant: tt.tenant}
+				c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+				c.Next()
+			})
+			NewSnapshotHandler(storage.NewTenantStorage(0, nil), store).RegisterRoutes(router.Group("/", auth.RequireScope(auth.ScopeAdmin), auth.RequireGlobal()))
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(model.ConfirmHeader, model.ConfirmRestoreSnapshot)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/tenant_mismatch","code":"tenant_mismatch","title":"Forbidden","status":403,"detail":"credentials limited to a tenant cannot access all tenants","error":"credentials limited to a tenant cannot access all tenants"}`, w.Body.String())
+			}
+		})
+	}
+
+	infos, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, infos, 1)
+}
@@ -4,35 +4,51 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
 	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
 )
 
 // CreateTask 處理建立新資料的 HTTP 請求
 // @Summary Create a new task
 // @Description Create a new task with name and status
 // @Tags tasks
-// @Accept json
-// @Produce json
+// @Accept json,application/yaml,application/msgpack
+// @Produce json,application/yaml,application/msgpack
 // @Param task body model.TaskRequest true "Task data"
 // @Success 201 {object} model.Task
-// @Failure 400 {object} model.BadRequestResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 415 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var task model.Task
 	
 	// 驗證請求資料
 	if err := validateTaskRequest(c, &task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Abort(c, validationProblem(err))
 		return
 	}
 
-	// 嘗試寫入到 storage，若失敗回傳伺服器錯誤
-	if err := h.storage.Create(&task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+	// 驗證通過才計入每日建立配額
+	if !ratelimit.ReserveCreates(c, 1) {
+		return
+	}
+
+	// 嘗試寫入到 storage，超過任務數上限回傳 403，其他錯誤回傳伺服器錯誤
+	if err := h.storage.Create(c.Request.Context(), accessFor(c), &task); err != nil {
+		ratelimit.ReleaseCreates(c, 1)
+		problem.Abort(c, storageProblem(err, "failed to create task"))
 		return
 	}
 
 	// 回傳建立成功的資料
-	c.JSON(http.StatusCreated, task)
+	render.Render(c, http.StatusCreated, task)
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package task
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -10,11 +11,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// problemBody 回傳未經過 problem.Middleware（相容模式）時的錯誤回應
+func problemBody(status int, code, detail string) string {
+	body, _ := json.Marshal(model.ErrorResponse{
+		Type:   problem.TypeBase + code,
+		Code:   code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Error:  detail,
+	})
+	return string(body)
+}
+
 func TestCreateTask(t *testing.T) {
 	// 設定 Gin 為測試模式
 	gin.SetMode(gin.TestMode)
@@ -33,7 +48,7 @@ func TestCreateTask(t *testing.T) {
 				"status": 0,
 			},
 			mockStorage: &storage.MockStorage{
-				CreateFunc: func(task *model.Task) error {
+				CreateFunc: func(ctx context.Context, access storage.Access, task *model.Task) error {
 					task.ID = "test-id-123"
 					return nil
 				},
@@ -46,7 +61,7 @@ func TestCreateTask(t *testing.T) {
 			requestBody: `{invalid json}`,
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"code":"malformed_body"`,
 		},
 		{
 			name: "缺少 name 欄位",
@@ -55,7 +70,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"required","message":"name is required"}]`,
 		},
 		{
 			name: "缺少 status 欄位",
@@ -64,7 +79,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"required","message":"status is required"}]`,
 		},
 		{
 			name: "status 值超出範圍 (小於 0)",
@@ -74,7 +89,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"enum","message":"status must be 0 or 1"}]`,
 		},
 		{
 			name: "status 值超出範圍 (大於 1)",
@@ -84,7 +99,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"enum","message":"status must be 0 or 1"}]`,
 		},
 		{
 			name: "status 型別錯誤 (字串)",
@@ -94,7 +109,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"type","message":"status must be a number"}]`,
 		},
 		{
 			name: "name 型別錯誤 (數字)",
@@ -104,7 +119,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"type","message":"name must be a string"}]`,
 		},
 		{
 			name: "name 為空字串",
@@ -114,7 +129,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"blank","message":"name cannot be empty"}]`,
 		},
 		{
 			name: "name 為純空白字串",
@@ -124,7 +139,28 @@ func TestCreateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"blank","message":"name cannot be empty"}]`,
+		},
+		{
+			name: "一次回報所有欄位錯誤",
+			requestBody: map[string]interface{}{
+				"name":     "",
+				"status":   2,
+				"priority": "high",
+			},
+			mockStorage: &storage.MockStorage{},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"errors":[{"field":"name","code":"blank","message":"name cannot be empty"},{"field":"status","code":"enum","message":"status must be 0 or 1"},{"field":"priority","code":"unknown","message":"unknown field \"priority\""}]`,
+		},
+		{
+			name: "狀態不是整數",
+			requestBody: map[string]interface{}{
+				"name":   "Task",
+				"status": 1.5,
+			},
+			mockStorage: &storage.MockStorage{},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"errors":[{"field":"status","code":"type","message":"status must be an integer"}]`,
 		},
 		{
 			name: "Storage 錯誤",
@@ -133,12 +169,12 @@ func TestCreateTask(t *testing.T) {
 				"status": 0,
 			},
 			mockStorage: &storage.MockStorage{
-				CreateFunc: func(task *model.Task) error {
+				CreateFunc: func(ctx context.Context, access storage.Access, task *model.Task) error {
 					return errors.New("storage error")
 				},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"failed to create task"}`,
+			expectedBody:   problemBody(http.StatusInternalServerError, problem.CodeInternal, "failed to create task"),
 		},
 	}
 
@@ -179,4 +215,68 @@ func TestCreateTask(t *testing.T) {
 			assert.Contains(t, w.Body.String(), tt.expectedBody)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestCreateTask_ContentNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		contentType    string
+		accept         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "YAML 請求與回應",
+			contentType:    "application/yaml",
+			accept:         "application/yaml",
+			body:           "name: Test Task\nstatus: 1\n",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "id: test-id-123\nname: Test Task\nstatus: 1\n",
+		},
+		{
+			name:           "不支援的 Content-Type",
+			contentType:    "application/xml",
+			body:           "<task/>",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   problemBody(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType, "unsupported media type: application/xml"),
+		},
+		{
+			name:           "不支援的 Accept",
+			contentType:    "application/json",
+			accept:         "text/csv",
+			body:           `{"name":"Test Task","status":0}`,
+			expectedStatus: http.StatusNotAcceptable,
+			expectedBody:   problemBody(http.StatusNotAcceptable, problem.CodeNotAcceptable, "not acceptable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			handler := NewTaskHandler(&storage.MockStorage{
+				CreateFunc: func(ctx context.Context, access storage.Access, task *model.Task) error {
+					created = true
+					task.ID = "test-id-123"
+					return nil
+				},
+			})
+			router := gin.New()
+			handler.RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedStatus == http.StatusCreated, created)
+		})
+	}
+}
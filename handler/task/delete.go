@@ -1,37 +1,55 @@
 package task
 
 import (
-	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/problem"
 )
 
 // DeleteTask 處理刪除指定資料的 HTTP 請求
+// 預設移到回收桶，hard=true 時永久刪除（也可刪除已在回收桶中的任務）
 // @Summary Delete a task
-// @Description Delete a specific task by its ID
+// @Description Move a task to the trash. It can be restored until the trash retention expires. With hard=true the task is deleted permanently, whether or not it is in the trash
 // @Tags tasks
 // @Accept json
-// @Produce json
+// @Produce json,application/yaml,application/msgpack
 // @Param id path string true "Task ID"
+// @Param hard query bool false "Delete permanently instead of moving to the trash"
 // @Success 200 {object} model.MessageResponse
-// @Failure 404 {object} model.NotFoundResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [delete]
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	id := c.Param("id")
+	hard, err := strconv.ParseBool(c.DefaultQuery("hard", "false"))
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "hard must be true or false"))
+		return
+	}
 
-	// 從 storage 刪除資料，若資料不存在回傳 404，其他錯誤回傳 500
-	if err := h.storage.Delete(id); err != nil {
-		if errors.Is(err, storage.ErrTaskNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete task"})
+	// 從 storage 刪除資料，若資料不存在回傳 404，不是擁有者回傳 403，其他錯誤回傳 500
+	if hard {
+		err = h.storage.Purge(c.Request.Context(), accessFor(c), id)
+	} else {
+		err = h.storage.Delete(c.Request.Context(), accessFor(c), id)
+	}
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to delete task"))
 		return
 	}
 
 	// 回傳刪除成功訊息
-	c.JSON(http.StatusOK, gin.H{"message": "task deleted successfully"})
+	if hard {
+		render.Render(c, http.StatusOK, gin.H{"message": "task deleted permanently"})
+		return
+	}
+	render.Render(c, http.StatusOK, gin.H{"message": "task deleted successfully"})
 }
\ No newline at end of file
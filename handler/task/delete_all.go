@@ -4,23 +4,62 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
 )
 
 // DeleteAllTasks 處理刪除所有任務的 HTTP 請求
+// 請求需帶 X-Confirm: delete-all-tasks；清空前的任務會保留，可用 RestoreTasks 加回
 // @Summary Delete all tasks
-// @Description Delete all tasks (testing utility)
-// @Tags tasks
+// @Description Delete all tasks of the current tenant. Requires admin scope and the X-Confirm header, and is only available when server.allow_wipe is on. The deleted tasks are kept as a snapshot until they are restored or the next wipe.
+// @Tags admin
 // @Accept json
-// @Produce json
+// @Produce json,application/yaml,application/msgpack
+// @Param X-Confirm header string true "Must be delete-all-tasks" Enums(delete-all-tasks)
 // @Success 200 {object} model.MessageResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 428 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
-// @Router /tasks [delete]
+// @Security ApiKeyAuth
+// @Router /admin/tasks [delete]
 func (h *TaskHandler) DeleteAllTasks(c *gin.Context) {
-	err := h.storage.DeleteAll()
+	if c.GetHeader(model.ConfirmHeader) != model.ConfirmDeleteAll {
+		problem.Abort(c, problem.New(http.StatusPreconditionRequired, codeConfirmRequired,
+			model.ConfirmHeader+": "+model.ConfirmDeleteAll+" header is required to delete all tasks"))
+		return
+	}
+
+	err := h.storage.DeleteAll(c.Request.Context(), accessFor(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Abort(c, storageProblem(err, "failed to delete tasks"))
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"message": "All tasks deleted successfully"})
-}
\ No newline at end of file
+	render.Render(c, http.StatusOK, gin.H{"message": "All tasks deleted successfully"})
+}
+
+// RestoreTasks 處理還原最近一次清空的任務的 HTTP 請求
+// @Summary Restore deleted tasks
+// @Description Add back the tasks removed by the last DELETE /admin/tasks of the current tenant. Tasks created since then are kept. The snapshot is discarded once restored.
+// @Tags admin
+// @Produce json,application/yaml,application/msgpack
+// @Success 200 {object} model.RestoreResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/tasks/restore [post]
+func (h *TaskHandler) RestoreTasks(c *gin.Context) {
+	restored, err := h.storage.RestoreAll(c.Request.Context(), accessFor(c))
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to restore tasks"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, model.RestoreResponse{Message: "Deleted tasks restored successfully", Restored: restored})
+}
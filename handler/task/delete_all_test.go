@@ -1,11 +1,15 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,14 +21,16 @@ func TestDeleteAllTasks(t *testing.T) {
 
 	tests := []struct {
 		name           string
+		confirm        string
 		mockStorage    *storage.MockStorage
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
-			name: "成功刪除所有任務",
+			name:    "成功刪除所有任務",
+			confirm: model.ConfirmDeleteAll,
 			mockStorage: &storage.MockStorage{
-				DeleteAllFunc: func() error {
+				DeleteAllFunc: func(ctx context.Context, access storage.Access) error {
 					return nil
 				},
 			},
@@ -32,14 +38,33 @@ func TestDeleteAllTasks(t *testing.T) {
 			expectedBody:   `{"message":"All tasks deleted successfully"}`,
 		},
 		{
-			name: "刪除失敗",
+			name:    "刪除失敗",
+			confirm: model.ConfirmDeleteAll,
 			mockStorage: &storage.MockStorage{
-				DeleteAllFunc: func() error {
-					return storage.ErrTaskNotFound
+				DeleteAllFunc: func(ctx context.Context, access storage.Access) error {
+					return errors.New("storage error")
 				},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"task not found"}`,
+			expectedBody:   problemBody(http.StatusInternalServerError, problem.CodeInternal, "failed to delete tasks"),
+		},
+		{
+			name: "缺少確認 header",
+			mockStorage: &storage.MockStorage{
+				DeleteAllFunc: func(ctx context.Context, access storage.Access) error {
+					t.Error("DeleteAll should not be called without confirmation")
+					return nil
+				},
+			},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   problemBody(http.StatusPreconditionRequired, codeConfirmRequired, "X-Confirm: delete-all-tasks header is required to delete all tasks"),
+		},
+		{
+			name:           "確認值不符",
+			confirm:        "yes",
+			mockStorage:    &storage.MockStorage{},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   problemBody(http.StatusPreconditionRequired, codeConfirmRequired, "X-Confirm: delete-all-tasks header is required to delete all tasks"),
 		},
 	}
 
@@ -49,8 +74,11 @@ func TestDeleteAllTasks(t *testing.T) {
 			handler := NewTaskHandler(tt.mockStorage)
 
 			// 建立 request
-			req, err := http.NewRequest(http.MethodDelete, "/tasks", nil)
+			req, err := http.NewRequest(http.MethodDelete, "/admin/tasks", nil)
 			require.NoError(t, err)
+			if tt.confirm != "" {
+				req.Header.Set(model.ConfirmHeader, tt.confirm)
+			}
 
 			// 建立 response recorder
 			w := httptest.NewRecorder()
@@ -69,4 +97,49 @@ func TestDeleteAllTasks(t *testing.T) {
 			assert.Equal(t, tt.expectedBody, w.Body.String())
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestRestoreTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		mockStorage    *storage.MockStorage
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "成功還原",
+			mockStorage: &storage.MockStorage{
+				RestoreAllFunc: func(ctx context.Context, access storage.Access) (int, error) {
+					return 3, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"Deleted tasks restored successfully","restored":3}`,
+		},
+		{
+			name: "沒有可還原的任務",
+			mockStorage: &storage.MockStorage{
+				RestoreAllFunc: func(ctx context.Context, access storage.Access) (int, error) {
+					return 0, storage.ErrNoSnapshot
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   problemBody(http.StatusNotFound, codeNoSnapshot, "no deleted tasks to restore"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			NewTaskHandler(tt.mockStorage).RegisterAdminRoutes(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/tasks/restore", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package task
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +21,7 @@ func TestDeleteTask(t *testing.T) {
 	tests := []struct {
 		name           string
 		taskID         string
+		query          string
 		mockStorage    *storage.MockStorage
 		expectedStatus int
 		expectedBody   string
@@ -27,34 +30,54 @@ func TestDeleteTask(t *testing.T) {
 			name:   "成功刪除資料",
 			taskID: "test-id-123",
 			mockStorage: &storage.MockStorage{
-				DeleteFunc: func(id string) error {
+				DeleteFunc: func(ctx context.Context, access storage.Access, id string) error {
 					return nil
 				},
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"message":"task deleted successfully"}`,
 		},
+		{
+			name:   "永久刪除",
+			taskID: "test-id-123",
+			query:  "?hard=true",
+			mockStorage: &storage.MockStorage{
+				PurgeFunc: func(ctx context.Context, access storage.Access, id string) error {
+					return nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"task deleted permanently"}`,
+		},
+		{
+			name:           "hard 參數格式錯誤",
+			taskID:         "test-id-123",
+			query:          "?hard=yes",
+			mockStorage:    &storage.MockStorage{},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   problemBody(http.StatusBadRequest, problem.CodeValidationFailed, "hard must be true or false"),
+		},
 		{
 			name:   "資料不存在",
 			taskID: "non-existing-id",
 			mockStorage: &storage.MockStorage{
-				DeleteFunc: func(id string) error {
+				DeleteFunc: func(ctx context.Context, access storage.Access, id string) error {
 					return storage.ErrTaskNotFound
 				},
 			},
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"error":"task not found"}`,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
 		},
 		{
 			name:   "Storage 錯誤",
 			taskID: "test-id-123",
 			mockStorage: &storage.MockStorage{
-				DeleteFunc: func(id string) error {
+				DeleteFunc: func(ctx context.Context, access storage.Access, id string) error {
 					return errors.New("storage error")
 				},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"failed to delete task"}`,
+			expectedBody:   problemBody(http.StatusInternalServerError, problem.CodeInternal, "failed to delete task"),
 		},
 	}
 
@@ -64,7 +87,7 @@ func TestDeleteTask(t *testing.T) {
 			handler := NewTaskHandler(tt.mockStorage)
 
 			// 建立 request
-			req, err := http.NewRequest(http.MethodDelete, "/tasks/"+tt.taskID, nil)
+			req, err := http.NewRequest(http.MethodDelete, "/tasks/"+tt.taskID+tt.query, nil)
 			require.NoError(t, err)
 
 			// 建立 response recorder
@@ -0,0 +1,113 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+)
+
+// exportChunkSize 匯出時每次寫出的筆數，寫完一批就 flush 給客戶端
+const exportChunkSize = 500
+
+// ExportTasks 處理匯出所有任務的 HTTP 請求
+// @Summary Export tasks
+// @Description Stream every task as CSV or JSON Lines
+// @Tags tasks
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, jsonl) default(jsonl)
+// @Success 200 {string} string "Exported tasks"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/export [get]
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", formatJSONLines)
+
+	var write func(tasks []model.Task) error
+	switch format {
+	case formatCSV:
+		cw := csv.NewWriter(c.Writer)
+		headerWritten := false
+		write = func(tasks []model.Task) error {
+			if !headerWritten {
+				if err := cw.Write(model.CSVHeader); err != nil {
+					return err
+				}
+				headerWritten = true
+			}
+			for _, t := range tasks {
+				if err := cw.Write(model.CSVRecord(t)); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	case formatJSONLines:
+		enc := json.NewEncoder(c.Writer)
+		write = func(tasks []model.Task) error {
+			for _, t := range tasks {
+				if err := enc.Encode(t); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	default:
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "format must be csv or jsonl"))
+		return
+	}
+
+	h.streamTasks(c, contentTypes[format], "tasks."+format, write)
+}
+
+// streamTasks 以 storage 的一致快照分批取出所有任務並交給 write 寫出，每批寫完就 flush 給客戶端
+// 回傳是否完整寫出所有任務
+func (h *TaskHandler) streamTasks(c *gin.Context, contentType, filename string, write func(tasks []model.Task) error) bool {
+	// 取得第一批後才送出 header，storage 一開始就失敗時仍可回傳錯誤
+	started := false
+	start := func() {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Status(http.StatusOK)
+		started = true
+	}
+
+	err := h.storage.Scan(c.Request.Context(), accessFor(c), exportChunkSize, func(tasks []model.Task) error {
+		if !started {
+			start()
+		}
+		if err := write(tasks); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			problem.Abort(c, storageProblem(err, "failed to export tasks"))
+			return false
+		}
+		// header 已送出，只能中斷串流
+		c.Error(err)
+		return false
+	}
+
+	// 沒有任何任務時仍寫出空的內容（例如 CSV 標題列）
+	if !started {
+		start()
+		if err := write(nil); err != nil {
+			c.Error(err)
+			return false
+		}
+		c.Writer.Flush()
+	}
+	return true
+}
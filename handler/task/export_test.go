@@ -0,0 +1,92 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	for i := 0; i < exportChunkSize+10; i++ {
+		require.NoError(t, memStorage.Create(context.Background(), storage.Access{All: true}, &model.Task{Name: fmt.Sprintf("Task %d", i), Status: i % 2}))
+	}
+
+	router := gin.New()
+	NewTaskHandler(memStorage).RegisterRoutes(router)
+
+	t.Run("CSV 匯出所有資料", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export?format=csv", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, exportChunkSize+11)
+		assert.Equal(t, "id,name,status,due", lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], ",Task 0,0,"))
+	})
+
+	t.Run("JSON Lines 匯出所有資料", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, exportChunkSize+10)
+		assert.Contains(t, lines[0], `"name":"Task 0"`)
+	})
+
+	t.Run("不支援的格式", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export?format=xml", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, problemBody(http.StatusBadRequest, problem.CodeValidationFailed, "format must be csv or jsonl"), w.Body.String())
+	})
+
+	t.Run("沒有任務時只寫出 CSV 標題列", func(t *testing.T) {
+		router := gin.New()
+		NewTaskHandler(storage.NewMemoryStorage()).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export?format=csv", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "id,name,status,due\n", w.Body.String())
+	})
+
+	t.Run("storage 錯誤", func(t *testing.T) {
+		router := gin.New()
+		NewTaskHandler(&storage.MockStorage{
+			ScanFunc: func(ctx context.Context, access storage.Access, chunk int, fn func(tasks []model.Task) error) error {
+				return errors.New("storage unavailable")
+			},
+		}).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodGet, "/tasks/export", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.JSONEq(t, problemBody(http.StatusInternalServerError, problem.CodeInternal, "failed to export tasks"), w.Body.String())
+	})
+}
@@ -4,7 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/problem"
 )
 
 // GetTask 處理取得單一任務的 HTTP 請求
@@ -12,24 +13,24 @@ import (
 // @Description Get a specific task by its ID
 // @Tags tasks
 // @Accept json
-// @Produce json
+// @Produce json,application/yaml,application/msgpack
 // @Param id path string true "Task ID"
 // @Success 200 {object} model.Task
-// @Failure 404 {object} model.NotFoundResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [get]
 func (h *TaskHandler) GetTask(c *gin.Context) {
 	id := c.Param("id")
 	
-	task, err := h.storage.Get(id)
+	task, err := h.storage.Get(c.Request.Context(), accessFor(c), id)
 	if err != nil {
-		if err == storage.ErrTaskNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		problem.Abort(c, storageProblem(err, "internal server error"))
 		return
 	}
 	
-	c.JSON(http.StatusOK, task)
+	render.Render(c, http.StatusOK, task)
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -36,13 +37,14 @@ func TestGetTask(t *testing.T) {
 			taskID:         "non-existent",
 			setupTask:      nil,
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"error":"Task not found"}`,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// 設定
+			access := storage.Access{All: true}
 			storage := storage.NewMemoryStorage()
 			handler := NewTaskHandler(storage)
 
@@ -51,7 +53,7 @@ func TestGetTask(t *testing.T) {
 
 			// 如果有設定任務，先創建它
 			if tt.setupTask != nil {
-				storage.Create(tt.setupTask)
+				storage.Create(context.Background(), access, tt.setupTask)
 				// Create 會直接修改 task 物件，設定新的 ID
 				actualTaskID = tt.setupTask.ID
 				expectedBody = `{"id":"` + actualTaskID + `","name":"Test Task","status":0}`
@@ -1,7 +1,16 @@
 package task
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/rbac"
 	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/tenant"
 )
 
 type TaskHandler struct {
@@ -12,4 +21,66 @@ func NewTaskHandler(storage storage.Storage) *TaskHandler {
 	return &TaskHandler{
 		storage: storage,
 	}
-}
\ No newline at end of file
+}
+
+// accessFor 依驗證後的呼叫者與 tenant 決定可存取的任務範圍
+// 未啟用驗證時沒有 principal，可存取所有任務；admin 也可存取同一 tenant 內所有使用者的任務
+func accessFor(c *gin.Context) storage.Access {
+	access := storage.Access{Tenant: tenant.From(c), All: true}
+	if p := auth.PrincipalFrom(c); p != nil {
+		access.UserID = p.ID
+		access.All = p.Scope.Includes(auth.ScopeAdmin)
+	}
+	return access
+}
+
+// 任務相關的錯誤代碼
+const (
+	codeTaskNotFound     = "task_not_found"
+	codeShareNotFound    = "share_not_found"
+	codeInvalidShare     = "invalid_share"
+	codePermissionDenied = "permission_denied"
+	codeQuotaExceeded    = "quota_exceeded"
+	codeNoSnapshot       = "snapshot_not_found"
+	codeTaskTrashed      = "task_in_trash"
+	codeConfirmRequired  = "confirmation_required"
+)
+
+// storageProblem 將 storage 回傳的錯誤轉為 problem
+// 已知的錯誤有各自的狀態碼與代碼，其他錯誤以 detail 回應並將原因記錄到請求日誌
+func storageProblem(err error, detail string) *problem.Error {
+	switch {
+	case errors.Is(err, storage.ErrTaskNotFound):
+		return problem.New(http.StatusNotFound, codeTaskNotFound, storage.ErrTaskNotFound.Error())
+	case errors.Is(err, storage.ErrShareNotFound):
+		return problem.New(http.StatusNotFound, codeShareNotFound, storage.ErrShareNotFound.Error())
+	case errors.Is(err, storage.ErrInvalidShare):
+		return problem.New(http.StatusBadRequest, codeInvalidShare, storage.ErrInvalidShare.Error())
+	case errors.Is(err, storage.ErrPermissionDenied):
+		return problem.New(http.StatusForbidden, codePermissionDenied, storage.ErrPermissionDenied.Error())
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		return problem.New(http.StatusForbidden, codeQuotaExceeded, storage.ErrQuotaExceeded.Error())
+	case errors.Is(err, storage.ErrTaskTrashed):
+		return problem.New(http.StatusConflict, codeTaskTrashed, storage.ErrTaskTrashed.Error())
+	case errors.Is(err, storage.ErrNoSnapshot):
+		return problem.New(http.StatusNotFound, codeNoSnapshot, storage.ErrNoSnapshot.Error())
+	}
+	return problem.Unexpected(err, detail)
+}
+
+// Relation 解析呼叫者與 /tasks/:id 任務的關係，供 rbac 檢查 own / shared 條件
+// 看不到的任務回傳 ok=false，交由 handler 回傳 404
+func (h *TaskHandler) Relation(c *gin.Context, p *auth.Principal) (rbac.Relation, bool) {
+	if !strings.HasPrefix(c.FullPath(), "/tasks/:id") {
+		return rbac.RelationNone, false
+	}
+
+	task, err := h.storage.Get(c.Request.Context(), storage.Access{Tenant: tenant.From(c), UserID: p.ID}, c.Param("id"))
+	if err != nil {
+		return rbac.RelationNone, false
+	}
+	if task.OwnerID == p.ID {
+		return rbac.RelationOwner, true
+	}
+	return rbac.RelationShared, true
+}
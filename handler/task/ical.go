@@ -0,0 +1,63 @@
+package task
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/ical"
+	"github.com/gogolook/task-api/model"
+)
+
+// ExportTasksICal 處理以 iCalendar 格式輸出任務的 HTTP 請求
+// @Summary iCalendar feed
+// @Description Render every task as an RFC 5545 VTODO component. Completed tasks use STATUS:COMPLETED, others STATUS:NEEDS-ACTION, and the task ID is the UID.
+// @Tags tasks
+// @Produce text/calendar
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks.ics [get]
+func (h *TaskHandler) ExportTasksICal(c *gin.Context) {
+	enc := ical.NewEncoder(c.Writer)
+	ok := h.streamTasks(c, contentTypes[formatICal], "tasks.ics", func(tasks []model.Task) error {
+		for _, t := range tasks {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return enc.Flush()
+	})
+	if !ok {
+		return
+	}
+	if err := enc.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// readICalRows 讀取 VTODO，轉為與 JSON 請求相同的欄位交給驗證
+func readICalRows(r io.Reader, fn func(row int, raw map[string]interface{}, err error)) error {
+	return ical.Decode(r, func(index int, todo ical.Todo, err error) {
+		if err != nil {
+			fn(index, nil, err)
+			return
+		}
+
+		status := 0.0
+		if todo.Completed() {
+			status = 1
+		}
+		raw := map[string]interface{}{
+			"id":     todo.UID,
+			"name":   todo.Summary,
+			"status": status,
+		}
+		if todo.Due != nil {
+			raw["due"] = todo.Due.Format(time.RFC3339)
+		}
+		fn(index, raw, nil)
+	})
+}
@@ -0,0 +1,88 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTasksICal_RoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	source := storage.NewMemoryStorage()
+	tasks := []*model.Task{
+		{Name: "Pay rent", Status: 0, Due: &due},
+		{Name: "Done, already", Status: 1},
+	}
+	for _, task := range tasks {
+		require.NoError(t, source.Create(context.Background(), storage.Access{All: true}, task))
+	}
+
+	router := gin.New()
+	NewTaskHandler(source).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks.ics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "UID:"+tasks[0].ID+"\r\n")
+	assert.Contains(t, body, "DUE:20261019T090000Z\r\n")
+	assert.Contains(t, body, "SUMMARY:Done\\, already\r\n")
+	assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+
+	// 匯入到另一個環境後欄位應完全相同
+	target := storage.NewMemoryStorage()
+	importRouter := gin.New()
+	NewTaskHandler(target).RegisterRoutes(importRouter)
+
+	req = httptest.NewRequest(http.MethodPost, "/tasks/import?mode=upsert", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/calendar")
+	w = httptest.NewRecorder()
+	importRouter.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result model.ImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 2, result.Created)
+
+	for _, task := range tasks {
+		got, err := target.Get(context.Background(), storage.Access{All: true}, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, *task, *got)
+	}
+}
+
+func TestImportTasks_ICalErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewTaskHandler(storage.NewMemoryStorage()).RegisterRoutes(router)
+
+	body := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nUID:1\r\nSUMMARY:\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:2\r\nSUMMARY:Task\r\nDUE:soon\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import?format=ics", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"dry_run":false,"total":2,"created":0,"updated":0,"errors":[
+		{"row":1,"error":"name cannot be empty","fields":[{"field":"name","code":"blank","message":"name cannot be empty"}]},
+		{"row":2,"error":"invalid DUE value \"soon\""}
+	]}`, w.Body.String())
+}
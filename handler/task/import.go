@@ -0,0 +1,231 @@
+package task
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/ical"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
+	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/validation"
+)
+
+// 匯入匯出支援的格式
+const (
+	formatCSV       = "csv"
+	formatJSONLines = "jsonl"
+	formatICal      = "ics"
+)
+
+var contentTypes = map[string]string{
+	formatCSV:       "text/csv; charset=utf-8",
+	formatJSONLines: "application/x-ndjson",
+	formatICal:      ical.MIMEType + "; charset=utf-8",
+}
+
+// ImportTasks 處理批次匯入任務的 HTTP 請求
+// @Summary Import tasks
+// @Description Import tasks from CSV, JSON Lines or iCalendar VTODO components. Every row is validated first; if any row fails nothing is written. Valid rows are then written together, so a permission, trash, quota or storage error also leaves every task unchanged. Every row counts against the daily creation quota; rows that update an existing task are given back.
+// @Tags tasks
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept text/calendar
+// @Produce json
+// @Param format query string false "Import format, defaults to the request Content-Type" Enums(csv, jsonl, ics)
+// @Param dry_run query bool false "Only validate the rows"
+// @Param mode query string false "create ignores IDs, upsert updates or inserts by ID" Enums(create, upsert) default(create)
+// @Success 200 {object} model.ImportResult
+// @Failure 400 {object} model.ImportResult
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/import [post]
+func (h *TaskHandler) ImportTasks(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = formatFromContentType(c.GetHeader("Content-Type"))
+	}
+	if _, ok := contentTypes[format]; !ok {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "format must be csv, jsonl or ics"))
+		return
+	}
+
+	mode := c.DefaultQuery("mode", "create")
+	if mode != "create" && mode != "upsert" {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "mode must be create or upsert"))
+		return
+	}
+	upsert := mode == "upsert"
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "dry_run must be true or false"))
+		return
+	}
+
+	// 逐列驗證，收集所有錯誤
+	result := model.ImportResult{DryRun: dryRun, Errors: []model.ImportRowError{}}
+	var tasks []model.Task
+	collect := func(row int, raw map[string]interface{}, err error) {
+		result.Total++
+		if err != nil {
+			result.Errors = append(result.Errors, model.ImportRowError{Row: row, Error: err.Error()})
+			return
+		}
+		// 匯出的檔案帶有 id 與 owner_id，匯入時不視為未知欄位；owner_id 一律由呼叫者決定
+		var task model.Task
+		if err := validation.Task(raw, &task, "id", "owner_id"); err != nil {
+			rowErr := model.ImportRowError{Row: row, Error: err.Error()}
+			var fields validation.Errors
+			if errors.As(err, &fields) {
+				rowErr.Fields = fields
+			}
+			result.Errors = append(result.Errors, rowErr)
+			return
+		}
+		if upsert {
+			id, _ := raw["id"].(string)
+			task.ID = id
+		}
+		tasks = append(tasks, task)
+	}
+
+	switch format {
+	case formatCSV:
+		err = readCSVRows(c.Request.Body, collect)
+	case formatJSONLines:
+		err = readJSONLinesRows(c.Request.Body, render.MaxDepth(c), collect)
+	case formatICal:
+		err = readICalRows(c.Request.Body, collect)
+	}
+	if err != nil {
+		problem.Abort(c, render.BindError(err))
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	// 每一列都先計入每日建立配額，寫入後歸還更新既有任務的列
+	if !ratelimit.ReserveCreates(c, len(tasks)) {
+		return
+	}
+
+	// 驗證全部通過才在同一個寫入鎖內一次寫入，任一筆無法寫入時都不寫入
+	written, err := h.storage.Import(c.Request.Context(), accessFor(c), tasks, upsert)
+	if err != nil {
+		ratelimit.ReleaseCreates(c, len(tasks))
+	} else {
+		ratelimit.ReleaseCreates(c, len(tasks)-written.Created)
+	}
+	var taskErr *storage.TaskError
+	if errors.As(err, &taskErr) {
+		switch {
+		case errors.Is(err, storage.ErrPermissionDenied):
+			problem.Abort(c, problem.New(http.StatusForbidden, codePermissionDenied, "permission denied: task "+taskErr.ID))
+			return
+		case errors.Is(err, storage.ErrTaskTrashed):
+			problem.Abort(c, problem.New(http.StatusConflict, codeTaskTrashed, "task "+taskErr.ID+" is in the trash"))
+			return
+		}
+	}
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to import tasks"))
+		return
+	}
+	result.Created = written.Created
+	result.Updated = written.Updated
+
+	c.JSON(http.StatusOK, result)
+}
+
+// formatFromContentType 由 Content-Type 推斷匯入格式
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return formatCSV
+	case "application/x-ndjson", "application/jsonl", "application/json-lines":
+		return formatJSONLines
+	case ical.MIMEType:
+		return formatICal
+	}
+	return ""
+}
+
+// readCSVRows 讀取 CSV，第一列必須是欄位名稱（name、status，可選 id）
+func readCSVRows(r io.Reader, fn func(row int, raw map[string]interface{}, err error)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return errors.New("CSV header is required")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		raw := make(map[string]interface{}, len(header))
+		for i, column := range header {
+			if i >= len(record) {
+				break
+			}
+			switch column {
+			case "status":
+				// 無法解析成數字時保留字串，交由驗證回報型別錯誤
+				if status, err := strconv.ParseFloat(record[i], 64); err == nil {
+					raw[column] = status
+				} else {
+					raw[column] = record[i]
+				}
+			default:
+				raw[column] = record[i]
+			}
+		}
+		fn(row, raw, nil)
+	}
+}
+
+// readJSONLinesRows 讀取 JSON Lines，每行一個 JSON 物件
+// 以 JSONDecoder 逐列串流解析，不會先把整個 body 讀進記憶體
+func readJSONLinesRows(r io.Reader, maxDepth int, fn func(row int, raw map[string]interface{}, err error)) error {
+	dec := render.NewJSONDecoder(r, maxDepth)
+	for row := 1; ; row++ {
+		raw, err := dec.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON on row %d: %w", row, err)
+		}
+		fn(row, raw, nil)
+	}
+}
@@ -0,0 +1,213 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		query          string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedResult model.ImportResult
+		expectedTotal  int
+	}{
+		{
+			name:           "CSV 匯入",
+			query:          "?format=csv",
+			body:           "id,name,status\n,Task 1,0\n,Task 2,1\n",
+			expectedStatus: http.StatusOK,
+			expectedResult: model.ImportResult{Total: 2, Created: 2, Errors: []model.ImportRowError{}},
+			expectedTotal:  3,
+		},
+		{
+			name:           "JSON Lines 依 Content-Type 判斷格式",
+			contentType:    "application/x-ndjson",
+			body:           "{\"name\":\"Task 1\",\"status\":0}\n{\"name\":\"Task 2\",\"status\":1}\n",
+			expectedStatus: http.StatusOK,
+			expectedResult: model.ImportResult{Total: 2, Created: 2, Errors: []model.ImportRowError{}},
+			expectedTotal:  3,
+		},
+		{
+			name:           "dry run 只驗證不寫入",
+			query:          "?format=csv&dry_run=true",
+			body:           "name,status\nTask 1,0\n",
+			expectedStatus: http.StatusOK,
+			expectedResult: model.ImportResult{DryRun: true, Total: 1, Errors: []model.ImportRowError{}},
+			expectedTotal:  1,
+		},
+		{
+			name:           "回報每一列的驗證錯誤且不寫入",
+			query:          "?format=csv",
+			body:           "name,status\nTask 1,0\n   ,0\nTask 3,2\nTask 4,abc\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: model.ImportResult{Total: 4, Errors: []model.ImportRowError{
+				{Row: 2, Error: "name cannot be empty", Fields: []model.FieldError{{Field: "name", Code: "blank", Message: "name cannot be empty"}}},
+				{Row: 3, Error: "status must be 0 or 1", Fields: []model.FieldError{{Field: "status", Code: "enum", Message: "status must be 0 or 1"}}},
+				{Row: 4, Error: "status must be a number", Fields: []model.FieldError{{Field: "status", Code: "type", Message: "status must be a number"}}},
+			}},
+			expectedTotal: 1,
+		},
+		{
+			name:           "同一列的所有錯誤一次回報",
+			query:          "?format=csv",
+			body:           "id,name,status,priority\n1,   ,2,high\n",
+			expectedStatus: http.StatusBadRequest,
+			expectedResult: model.ImportResult{Total: 1, Errors: []model.ImportRowError{
+				{Row: 1, Error: `name cannot be empty; status must be 0 or 1; unknown field "priority"`, Fields: []model.FieldError{
+					{Field: "name", Code: "blank", Message: "name cannot be empty"},
+					{Field: "status", Code: "enum", Message: "status must be 0 or 1"},
+					{Field: "priority", Code: "unknown", Message: `unknown field "priority"`},
+				}},
+			}},
+			expectedTotal: 1,
+		},
+		{
+			name:           "upsert 依 ID 更新或新增",
+			query:          "?format=jsonl&mode=upsert",
+			body:           "{\"id\":\"existing\",\"name\":\"Renamed\",\"status\":1}\n{\"id\":\"new-id\",\"name\":\"New\",\"status\":0}\n",
+			expectedStatus: http.StatusOK,
+			expectedResult: model.ImportResult{Total: 2, Created: 1, Updated: 1, Errors: []model.ImportRowError{}},
+			expectedTotal:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memStorage := storage.NewMemoryStorage()
+			_, err := memStorage.Upsert(context.Background(), storage.Access{All: true}, &model.Task{ID: "existing", Name: "Existing", Status: 0})
+			require.NoError(t, err)
+
+			router := gin.New()
+			NewTaskHandler(memStorage).RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/import"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var result model.ImportResult
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			assert.Equal(t, tt.expectedResult, result)
+
+			list, err := memStorage.List(context.Background(), storage.Access{All: true}, storage.NewPaginationParams(1))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, list.Pagination.Total)
+		})
+	}
+}
+
+func TestImportTasks_InvalidRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewTaskHandler(&storage.MockStorage{}).RegisterRoutes(router)
+
+	tests := []struct {
+		name         string
+		query        string
+		body         string
+		expectedBody string
+	}{
+		{name: "缺少格式", query: "", body: "", expectedBody: problemBody(http.StatusBadRequest, problem.CodeValidationFailed, "format must be csv, jsonl or ics")},
+		{name: "不支援的 mode", query: "?format=csv&mode=replace", body: "", expectedBody: problemBody(http.StatusBadRequest, problem.CodeValidationFailed, "mode must be create or upsert")},
+		{name: "不合法的 dry_run", query: "?format=csv&dry_run=yes", body: "name,status\nTask,0\n", expectedBody: problemBody(http.StatusBadRequest, problem.CodeValidationFailed, "dry_run must be true or false")},
+		{name: "CSV 缺少標題列", query: "?format=csv", body: "", expectedBody: problemBody(http.StatusBadRequest, problem.CodeMalformedBody, "CSV header is required")},
+		{name: "JSON Lines 有重複的 key", query: "?format=jsonl", body: `{"name":"Task","status":0,"status":1}`, expectedBody: problemBody(http.StatusBadRequest, problem.CodeMalformedBody, `invalid JSON on row 1: duplicate key "status"`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/tasks/import"+tt.query, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestImportTasks_AllOrNothing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	all := storage.Access{All: true}
+
+	memStorage := storage.NewMemoryStorage()
+	_, err := memStorage.Upsert(ctx, all, &model.Task{ID: "trashed", Name: "Trashed"})
+	require.NoError(t, err)
+	require.NoError(t, memStorage.Delete(ctx, all, "trashed"))
+
+	router := gin.New()
+	NewTaskHandler(memStorage).RegisterRoutes(router)
+
+	// 最後一列無法寫入時，前面的列也不會寫入
+	body := "{\"id\":\"new-1\",\"name\":\"New 1\",\"status\":0}\n{\"id\":\"trashed\",\"name\":\"Trashed\",\"status\":0}\n"
+	req := httptest.NewRequest(http.MethodPost, "/tasks/import?format=jsonl&mode=upsert", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusConflict, codeTaskTrashed, "task trashed is in the trash"), w.Body.String())
+	_, err = memStorage.Get(ctx, all, "new-1")
+	assert.Equal(t, storage.ErrTaskNotFound, err)
+	assert.Equal(t, 0, memStorage.Count())
+}
+
+func TestImportTasks_DailyQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := ratelimit.New(ratelimit.Limits{DailyCreates: 3})
+	require.NoError(t, err)
+	memStorage := storage.NewMemoryStorage()
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	NewTaskHandler(memStorage).RegisterRoutes(router)
+
+	importTasks := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks/import?format=jsonl"+query, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 每一列都計入每日建立配額
+	w := importTasks("&mode=upsert", "{\"id\":\"task-1\",\"name\":\"Task 1\",\"status\":0}\n{\"name\":\"Task 2\",\"status\":0}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Daily-Quota-Remaining"))
+
+	// 超過配額時整批都不寫入
+	w = importTasks("", "{\"name\":\"Task 3\",\"status\":0}\n{\"name\":\"Task 4\",\"status\":0}\n")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusTooManyRequests, "daily_quota_exceeded", "daily task creation quota exceeded"), w.Body.String())
+	assert.Equal(t, 2, memStorage.Count())
+
+	// 更新既有任務的列不計入
+	w = importTasks("&mode=upsert", "{\"id\":\"task-1\",\"name\":\"Renamed\",\"status\":1}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = importTasks("", "{\"name\":\"Task 3\",\"status\":0}\n")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Daily-Quota-Remaining"))
+	assert.Equal(t, 3, memStorage.Count())
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 )
 
@@ -13,10 +15,14 @@ import (
 // @Description Get a paginated list of tasks (100 items per page)
 // @Tags tasks
 // @Accept json
-// @Produce json
+// @Produce json,application/yaml,application/msgpack,text/csv
 // @Param page query int false "Page number" default(1)
 // @Success 200 {object} storage.PaginationResult
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /tasks [get]
 func (h *TaskHandler) ListTasks(c *gin.Context) {
 	// 解析分頁參數（只允許传递 page）
@@ -30,11 +36,11 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 	// 建立分頁參數（後端固定每頁 100 筆）
 	params := storage.NewPaginationParams(page)
 	
-	result, err := h.storage.List(params)
+	result, err := h.storage.List(c.Request.Context(), accessFor(c), params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Abort(c, storageProblem(err, "failed to list tasks"))
 		return
 	}
 	
-	c.JSON(http.StatusOK, result)
+	render.Render(c, http.StatusOK, result)
 }
\ No newline at end of file
@@ -1,12 +1,14 @@
 package task
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,7 +27,7 @@ func TestListTasks(t *testing.T) {
 		{
 			name: "成功取得空清單",
 			mockStorage: &storage.MockStorage{
-				ListFunc: func(params storage.PaginationParams) (*storage.PaginationResult, error) {
+				ListFunc: func(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
 					return &storage.PaginationResult{
 						Data: []model.Task{},
 						Pagination: storage.PaginationInfo{
@@ -45,7 +47,7 @@ func TestListTasks(t *testing.T) {
 		{
 			name: "成功取得資料清單",
 			mockStorage: &storage.MockStorage{
-				ListFunc: func(params storage.PaginationParams) (*storage.PaginationResult, error) {
+				ListFunc: func(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
 					return &storage.PaginationResult{
 						Data: []model.Task{
 							{ID: "1", Name: "Task 1", Status: 0},
@@ -65,6 +67,16 @@ func TestListTasks(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   `{"data":[{"id":"1","name":"Task 1","status":0},{"id":"2","name":"Task 2","status":1}],"pagination":{"page":1,"limit":100,"total":2,"pages":1,"has_next":false,"has_prev":false}}`,
 		},
+		{
+			name: "storage 逾時",
+			mockStorage: &storage.MockStorage{
+				ListFunc: func(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+					return nil, context.DeadlineExceeded
+				},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   problemBody(http.StatusServiceUnavailable, problem.CodeTimeout, "context deadline exceeded"),
+		},
 	}
 
 	for _, tt := range tests {
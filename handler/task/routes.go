@@ -0,0 +1,36 @@
+package task
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+)
+
+// RegisterRoutes 將所有任務相關路由註冊到指定的 router
+func (h *TaskHandler) RegisterRoutes(r gin.IRouter) {
+	// 清單可額外輸出 CSV，其餘路由只支援 JSON、YAML、MessagePack
+	list := render.Negotiate(true)
+	single := render.Negotiate(false)
+
+	r.GET("/tasks", list, h.ListTasks)
+	r.GET("/tasks.ics", h.ExportTasksICal)
+	r.GET("/tasks/export", h.ExportTasks)
+	r.POST("/tasks/import", h.ImportTasks)
+	r.GET("/tasks/trash", single, h.ListTrash)
+	r.GET("/tasks/:id", single, h.GetTask)
+	r.POST("/tasks", single, h.CreateTask)
+	r.PUT("/tasks/:id", single, h.UpdateTask)
+	r.DELETE("/tasks/:id", single, h.DeleteTask)
+	r.POST("/tasks/:id/restore", single, h.RestoreTask)
+	r.GET("/tasks/:id/shares", single, h.ListShares)
+	r.PUT("/tasks/:id/shares/:user_id", single, h.ShareTask)
+	r.DELETE("/tasks/:id/shares/:user_id", single, h.UnshareTask)
+}
+
+// RegisterAdminRoutes 將清空與還原任務的路由註冊到指定的 router（應掛在需要 admin scope 的 group 下）
+// 這兩個路由不應在正式環境註冊
+func (h *TaskHandler) RegisterAdminRoutes(r gin.IRouter) {
+	single := render.Negotiate(false)
+
+	r.DELETE("/admin/tasks", single, h.DeleteAllTasks)
+	r.POST("/admin/tasks/restore", single, h.RestoreTasks)
+}
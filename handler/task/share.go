@@ -0,0 +1,102 @@
+package task
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+)
+
+// ListShares 處理列出任務分享設定的 HTTP 請求
+// @Summary List task shares
+// @Description List the users a task is shared with. Only the owner can view shares
+// @Tags tasks
+// @Accept json
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "Task ID"
+// @Success 200 {array} model.Share
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/shares [get]
+func (h *TaskHandler) ListShares(c *gin.Context) {
+	shares, err := h.storage.Shares(c.Request.Context(), accessFor(c), c.Param("id"))
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to list shares"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, shares)
+}
+
+// ShareTask 處理分享任務給其他使用者的 HTTP 請求，已分享時更新權限
+// @Summary Share a task
+// @Description Grant another user read or write access to a task. Only the owner can share
+// @Tags tasks
+// @Accept json,application/yaml,application/msgpack
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "Task ID"
+// @Param user_id path string true "User ID to share with"
+// @Param share body model.ShareRequest true "Permission"
+// @Success 200 {object} model.Share
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 415 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/shares/{user_id} [put]
+func (h *TaskHandler) ShareTask(c *gin.Context) {
+	raw, err := render.BindRaw(c)
+	if err != nil {
+		problem.Abort(c, validationProblem(err))
+		return
+	}
+
+	permission, _ := raw["permission"].(string)
+	share := model.Share{UserID: c.Param("user_id"), Permission: model.Permission(permission)}
+	if !share.Permission.Valid() {
+		problem.Abort(c, problem.Validation(model.FieldError{Field: "permission", Code: "enum", Message: "permission must be read or write"}))
+		return
+	}
+
+	if err := h.storage.Share(c.Request.Context(), accessFor(c), c.Param("id"), share); err != nil {
+		problem.Abort(c, storageProblem(err, "failed to update shares"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, share)
+}
+
+// UnshareTask 處理取消分享的 HTTP 請求
+// @Summary Unshare a task
+// @Description Revoke another user's access to a task. Only the owner can unshare
+// @Tags tasks
+// @Accept json
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "Task ID"
+// @Param user_id path string true "User ID to revoke"
+// @Success 200 {object} model.MessageResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/shares/{user_id} [delete]
+func (h *TaskHandler) UnshareTask(c *gin.Context) {
+	if err := h.storage.Unshare(c.Request.Context(), accessFor(c), c.Param("id"), c.Param("user_id")); err != nil {
+		problem.Abort(c, storageProblem(err, "failed to update shares"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, gin.H{"message": "share removed successfully"})
+}
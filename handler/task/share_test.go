@@ -0,0 +1,266 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/rbac"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownershipRouter 建立以 X-User 標頭模擬已驗證呼叫者的 router
+func ownershipRouter(handler *TaskHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			p := &auth.Principal{ID: user, Name: user, Scope: auth.ScopeWrite}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+		c.Next()
+	})
+	handler.RegisterRoutes(router)
+	return router
+}
+
+func doAs(router *gin.Engine, user, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTaskOwnership(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	router := ownershipRouter(NewTaskHandler(memStorage))
+
+	w := doAs(router, "alice", http.MethodPost, "/tasks", `{"name":"Alice Task","status":0}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"owner_id":"alice"`)
+
+	list, err := memStorage.List(context.Background(), storage.Access{UserID: "alice"}, storage.NewPaginationParams(1))
+	require.NoError(t, err)
+	require.Len(t, list.Data, 1)
+	id := list.Data[0].ID
+
+	// 其他使用者的任務一律回傳 404
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		w := doAs(router, "bob", method, "/tasks/"+id, `{"name":"Hijacked","status":1}`)
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+	}
+
+	w = doAs(router, "bob", http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+
+	// 擁有者可以正常存取
+	w = doAs(router, "alice", http.MethodPut, "/tasks/"+id, `{"name":"Renamed","status":1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"`+id+`","name":"Renamed","status":1,"owner_id":"alice"}`, w.Body.String())
+}
+
+func TestTaskShares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	router := ownershipRouter(NewTaskHandler(memStorage))
+
+	task := &model.Task{Name: "Shared Task"}
+	require.NoError(t, memStorage.Create(context.Background(), storage.Access{UserID: "alice"}, task))
+	path := "/tasks/" + task.ID
+
+	tests := []struct {
+		name           string
+		user           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "不合法的權限",
+			user:           "alice",
+			method:         http.MethodPut,
+			path:           path + "/shares/bob",
+			body:           `{"permission":"owner"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"permission must be read or write","errors":[{"field":"permission","code":"enum","message":"permission must be read or write"}],"error":"permission must be read or write"}`,
+		},
+		{
+			name:           "不能分享給自己",
+			user:           "alice",
+			method:         http.MethodPut,
+			path:           path + "/shares/alice",
+			body:           `{"permission":"read"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   problemBody(http.StatusBadRequest, codeInvalidShare, "cannot share a task with its owner"),
+		},
+		{
+			name:           "分享 read 給 bob",
+			user:           "alice",
+			method:         http.MethodPut,
+			path:           path + "/shares/bob",
+			body:           `{"permission":"read"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"user_id":"bob","permission":"read"}`,
+		},
+		{
+			name:           "bob 可以讀取",
+			user:           "bob",
+			method:         http.MethodGet,
+			path:           path,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"` + task.ID + `","name":"Shared Task","status":0,"owner_id":"alice"}`,
+		},
+		{
+			name:           "bob 不能修改",
+			user:           "bob",
+			method:         http.MethodPut,
+			path:           path,
+			body:           `{"name":"Edited","status":1}`,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   problemBody(http.StatusForbidden, codePermissionDenied, "permission denied"),
+		},
+		{
+			name:           "bob 不能查看分享設定",
+			user:           "bob",
+			method:         http.MethodGet,
+			path:           path + "/shares",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   problemBody(http.StatusForbidden, codePermissionDenied, "permission denied"),
+		},
+		{
+			name:           "升級為 write",
+			user:           "alice",
+			method:         http.MethodPut,
+			path:           path + "/shares/bob",
+			body:           `{"permission":"write"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"user_id":"bob","permission":"write"}`,
+		},
+		{
+			name:           "bob 可以修改",
+			user:           "bob",
+			method:         http.MethodPut,
+			path:           path,
+			body:           `{"name":"Edited","status":1}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"` + task.ID + `","name":"Edited","status":1,"owner_id":"alice"}`,
+		},
+		{
+			name:           "bob 不能刪除",
+			user:           "bob",
+			method:         http.MethodDelete,
+			path:           path,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   problemBody(http.StatusForbidden, codePermissionDenied, "permission denied"),
+		},
+		{
+			name:           "列出分享設定",
+			user:           "alice",
+			method:         http.MethodGet,
+			path:           path + "/shares",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"user_id":"bob","permission":"write"}]`,
+		},
+		{
+			name:           "carol 看不到任務",
+			user:           "carol",
+			method:         http.MethodGet,
+			path:           path + "/shares",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
+		},
+		{
+			name:           "取消分享",
+			user:           "alice",
+			method:         http.MethodDelete,
+			path:           path + "/shares/bob",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"message":"share removed successfully"}`,
+		},
+		{
+			name:           "取消不存在的分享",
+			user:           "alice",
+			method:         http.MethodDelete,
+			path:           path + "/shares/bob",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   problemBody(http.StatusNotFound, codeShareNotFound, "share not found"),
+		},
+		{
+			name:           "取消分享後 bob 看不到",
+			user:           "bob",
+			method:         http.MethodGet,
+			path:           path,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doAs(router, tt.user, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestTaskHandler_Relation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	memStorage := storage.NewMemoryStorage()
+	handler := NewTaskHandler(memStorage)
+
+	task := &model.Task{Name: "Shared Task"}
+	require.NoError(t, memStorage.Create(context.Background(), storage.Access{UserID: "alice"}, task))
+	require.NoError(t, memStorage.Share(context.Background(), storage.Access{UserID: "alice"}, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+
+	tests := []struct {
+		name        string
+		user        string
+		path        string
+		expectedRel rbac.Relation
+		expectedOK  bool
+	}{
+		{name: "擁有者", user: "alice", path: "/tasks/" + task.ID, expectedRel: rbac.RelationOwner, expectedOK: true},
+		{name: "被分享者", user: "bob", path: "/tasks/" + task.ID, expectedRel: rbac.RelationShared, expectedOK: true},
+		{name: "看不到的任務", user: "carol", path: "/tasks/" + task.ID, expectedRel: rbac.RelationNone, expectedOK: false},
+		{name: "沒有任務 ID", user: "alice", path: "/tasks", expectedRel: rbac.RelationNone, expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				rel rbac.Relation
+				ok  bool
+			)
+			router := gin.New()
+			capture := func(c *gin.Context) {
+				rel, ok = handler.Relation(c, &auth.Principal{ID: tt.user})
+			}
+			router.GET("/tasks", capture)
+			router.GET("/tasks/:id", capture)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedRel, rel)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
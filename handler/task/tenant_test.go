@@ -0,0 +1,61 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskHandler_Tenants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(tenant.Middleware(tenant.Header("X-Tenant-ID")))
+	handler := NewTaskHandler(storage.NewTenantStorage(2, nil))
+	handler.RegisterRoutes(router)
+	handler.RegisterAdminRoutes(router)
+
+	do := func(tenantID, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", tenantID)
+		req.Header.Set(model.ConfirmHeader, model.ConfirmDeleteAll)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusCreated, do("acme", http.MethodPost, "/tasks", `{"name":"Task 1","status":0}`).Code)
+	assert.Equal(t, http.StatusCreated, do("acme", http.MethodPost, "/tasks", `{"name":"Task 2","status":0}`).Code)
+
+	// 超過 tenant 的任務數上限
+	w := do("acme", http.MethodPost, "/tasks", `{"name":"Task 3","status":0}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusForbidden, codeQuotaExceeded, "task quota exceeded"), w.Body.String())
+
+	// 其他 tenant 的列表與分頁總數都不包含 acme 的任務
+	w = do("globex", http.MethodGet, "/tasks", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Total-Count"))
+
+	assert.Equal(t, http.StatusCreated, do("globex", http.MethodPost, "/tasks", `{"name":"Globex Task","status":0}`).Code)
+
+	// DELETE /admin/tasks 只清空目前的 tenant
+	assert.Equal(t, http.StatusOK, do("acme", http.MethodDelete, "/admin/tasks", "").Code)
+	assert.Equal(t, "0", do("acme", http.MethodGet, "/tasks", "").Header().Get("X-Total-Count"))
+	assert.Equal(t, "1", do("globex", http.MethodGet, "/tasks", "").Header().Get("X-Total-Count"))
+
+	// 還原也只作用在目前的 tenant
+	assert.Equal(t, http.StatusNotFound, do("globex", http.MethodPost, "/admin/tasks/restore", "").Code)
+	assert.Equal(t, http.StatusOK, do("acme", http.MethodPost, "/admin/tasks/restore", "").Code)
+	assert.Equal(t, "2", do("acme", http.MethodGet, "/tasks", "").Header().Get("X-Total-Count"))
+}
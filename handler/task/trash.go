@@ -0,0 +1,63 @@
+package task
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/storage"
+)
+
+// ListTrash 處理列出回收桶的 HTTP 請求
+// @Summary List deleted tasks
+// @Description List the caller's tasks in the trash, most recently deleted first (100 items per page). Trashed tasks are purged permanently after the trash retention
+// @Tags tasks
+// @Produce json,application/yaml,application/msgpack
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} storage.PaginationResult
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/trash [get]
+func (h *TaskHandler) ListTrash(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	result, err := h.storage.Trash(c.Request.Context(), accessFor(c), storage.NewPaginationParams(page))
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to list deleted tasks"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, result)
+}
+
+// RestoreTask 處理將任務移出回收桶的 HTTP 請求
+// @Summary Restore a deleted task
+// @Description Move a task out of the trash. Only the owner can restore it; its shares are restored too
+// @Tags tasks
+// @Produce json,application/yaml,application/msgpack
+// @Param id path string true "Task ID"
+// @Success 200 {object} model.Task
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/restore [post]
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	task, err := h.storage.Restore(c.Request.Context(), accessFor(c), c.Param("id"))
+	if err != nil {
+		problem.Abort(c, storageProblem(err, "failed to restore task"))
+		return
+	}
+
+	render.Render(c, http.StatusOK, task)
+}
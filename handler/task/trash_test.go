@@ -0,0 +1,136 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreTask(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deletedAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mockStorage    *storage.MockStorage
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "成功還原",
+			mockStorage: &storage.MockStorage{
+				RestoreFunc: func(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+					return &model.Task{ID: id, Name: "Task 1", Status: 0}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":"test-id-123","name":"Task 1","status":0}`,
+		},
+		{
+			name: "不在回收桶中",
+			mockStorage: &storage.MockStorage{
+				RestoreFunc: func(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+					return nil, storage.ErrTaskNotFound
+				},
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
+		},
+		{
+			name: "超過任務數上限",
+			mockStorage: &storage.MockStorage{
+				RestoreFunc: func(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+					return nil, storage.ErrQuotaExceeded
+				},
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   problemBody(http.StatusForbidden, codeQuotaExceeded, "task quota exceeded"),
+		},
+		{
+			name: "列出回收桶",
+			mockStorage: &storage.MockStorage{
+				TrashFunc: func(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+					return &storage.PaginationResult{
+						Data:       []model.Task{{ID: "1", Name: "Task 1", DeletedAt: &deletedAt}},
+						Pagination: storage.PaginationInfo{Page: params.Page, Limit: params.Limit, Total: 1, Pages: 1},
+					}, nil
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":[{"id":"1","name":"Task 1","status":0,"deleted_at":"2026-10-19T09:00:00Z"}],"pagination":{"page":1,"limit":100,"total":1,"pages":1,"has_next":false,"has_prev":false}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			NewTaskHandler(tt.mockStorage).RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks/test-id-123/restore", nil)
+			if tt.mockStorage.TrashFunc != nil {
+				req = httptest.NewRequest(http.MethodGet, "/tasks/trash", nil)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestTrash_RoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewTaskHandler(storage.NewMemoryStorage()).RegisterRoutes(router)
+
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/tasks", "application/json", `{"name":"Task 1","status":0}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	id := created.ID
+
+	// 刪除後移到回收桶，一般的讀取視為不存在
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, "/tasks/"+id, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/tasks/"+id, "", "").Code)
+
+	w = do(http.MethodGet, "/tasks/trash", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"`+id+`"`)
+	assert.Contains(t, w.Body.String(), `"deleted_at":`)
+
+	// 回收桶中的 ID 不能再匯入
+	w = do(http.MethodPost, "/tasks/import?mode=upsert", "application/x-ndjson", `{"id":"`+id+`","name":"Task 1","status":0}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, problemBody(http.StatusConflict, codeTaskTrashed, "task "+id+" is in the trash"), w.Body.String())
+
+	w = do(http.MethodPost, "/tasks/"+id+"/restore", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "deleted_at")
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/tasks/"+id, "", "").Code)
+
+	// 永久刪除後無法還原
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, "/tasks/"+id+"?hard=true", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/tasks/"+id+"/restore", "", "").Code)
+}
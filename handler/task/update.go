@@ -1,26 +1,32 @@
 package task
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
 	"github.com/gogolook/task-api/model"
-	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/problem"
 )
 
 // UpdateTask 處理更新指定資料的 HTTP 請求
 // @Summary Update a task
 // @Description Update a specific task by its ID
 // @Tags tasks
-// @Accept json
-// @Produce json
+// @Accept json,application/yaml,application/msgpack
+// @Produce json,application/yaml,application/msgpack
 // @Param id path string true "Task ID"
 // @Param task body model.TaskRequest true "Task data"
 // @Success 200 {object} model.Task
-// @Failure 400 {object} model.BadRequestResponse
-// @Failure 404 {object} model.NotFoundResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 403 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 406 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 415 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	id := c.Param("id")
@@ -28,20 +34,16 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	var task model.Task
 	// 驗證請求資料
 	if err := validateTaskRequest(c, &task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Abort(c, validationProblem(err))
 		return
 	}
 
-	// 更新 storage 中的資料，若資料不存在回傳 404，其他錯誤回傳 500
-	if err := h.storage.Update(id, &task); err != nil {
-		if errors.Is(err, storage.ErrTaskNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task"})
+	// 更新 storage 中的資料，若資料不存在回傳 404，只有 read 權限回傳 403，其他錯誤回傳 500
+	if err := h.storage.Update(c.Request.Context(), accessFor(c), id, &task); err != nil {
+		problem.Abort(c, storageProblem(err, "failed to update task"))
 		return
 	}
 
 	// 回傳更新後的資料
-	c.JSON(http.StatusOK, task)
+	render.Render(c, http.StatusOK, task)
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package task
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
 	"github.com/gogolook/task-api/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,7 +37,7 @@ func TestUpdateTask(t *testing.T) {
 				"status": 1,
 			},
 			mockStorage: &storage.MockStorage{
-				UpdateFunc: func(id string, task *model.Task) error {
+				UpdateFunc: func(ctx context.Context, access storage.Access, id string, task *model.Task) error {
 					task.ID = id
 					return nil
 				},
@@ -49,7 +51,7 @@ func TestUpdateTask(t *testing.T) {
 			requestBody: `{invalid json}`,
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"code":"malformed_body"`,
 		},
 		{
 			name:   "缺少 name 欄位",
@@ -59,7 +61,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"required","message":"name is required"}]`,
 		},
 		{
 			name:   "缺少 status 欄位",
@@ -69,7 +71,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"required","message":"status is required"}]`,
 		},
 		{
 			name:   "status 值超出範圍 (小於 0)",
@@ -80,7 +82,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"enum","message":"status must be 0 or 1"}]`,
 		},
 		{
 			name:   "status 值超出範圍 (大於 1)",
@@ -91,7 +93,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"enum","message":"status must be 0 or 1"}]`,
 		},
 		{
 			name:   "status 型別錯誤 (字串)",
@@ -102,7 +104,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"status","code":"type","message":"status must be a number"}]`,
 		},
 		{
 			name:   "name 型別錯誤 (數字)",
@@ -113,7 +115,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"type","message":"name must be a string"}]`,
 		},
 		{
 			name:   "name 為空字串",
@@ -124,7 +126,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"blank","message":"name cannot be empty"}]`,
 		},
 		{
 			name:   "name 為純空白字串",
@@ -135,7 +137,7 @@ func TestUpdateTask(t *testing.T) {
 			},
 			mockStorage: &storage.MockStorage{},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   `{"error":`,
+			expectedBody:   `"errors":[{"field":"name","code":"blank","message":"name cannot be empty"}]`,
 		},
 		{
 			name:   "資料不存在",
@@ -145,12 +147,12 @@ func TestUpdateTask(t *testing.T) {
 				"status": 1,
 			},
 			mockStorage: &storage.MockStorage{
-				UpdateFunc: func(id string, task *model.Task) error {
+				UpdateFunc: func(ctx context.Context, access storage.Access, id string, task *model.Task) error {
 					return storage.ErrTaskNotFound
 				},
 			},
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"error":"task not found"}`,
+			expectedBody:   problemBody(http.StatusNotFound, codeTaskNotFound, "task not found"),
 		},
 		{
 			name:   "Storage 錯誤",
@@ -160,12 +162,12 @@ func TestUpdateTask(t *testing.T) {
 				"status": 1,
 			},
 			mockStorage: &storage.MockStorage{
-				UpdateFunc: func(id string, task *model.Task) error {
+				UpdateFunc: func(ctx context.Context, access storage.Access, id string, task *model.Task) error {
 					return errors.New("storage error")
 				},
 			},
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   `{"error":"failed to update task"}`,
+			expectedBody:   problemBody(http.StatusInternalServerError, problem.CodeInternal, "failed to update task"),
 		},
 	}
 
@@ -2,54 +2,42 @@ package task
 
 import (
 	"errors"
-	"fmt"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/handler/render"
 	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/validation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 )
 
-// validateTaskRequest 驗證 Task 請求
-func validateTaskRequest(c *gin.Context, task *model.Task) error {
-	// 先解析到 raw map 檢查必填欄位是否存在
-	var raw map[string]interface{}
-	if err := c.ShouldBindJSON(&raw); err != nil {
-		return fmt.Errorf("invalid JSON: %w", err)
+var tracer = otel.Tracer("github.com/gogolook/task-api/handler/task")
+
+// validateTaskRequest 驗證 Task 請求，解析與驗證的時間記錄在獨立的 span
+func validateTaskRequest(c *gin.Context, task *model.Task) (err error) {
+	_, span := tracer.Start(c.Request.Context(), "validateTaskRequest")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	// 先依 Content-Type 解析到 raw map，才能分辨缺少的欄位與未知的欄位
+	raw, err := render.BindRaw(c)
+	if err != nil {
+		return err
 	}
 
-	// 檢查必填欄位是否存在
-	if _, exists := raw["name"]; !exists {
-		return errors.New("name is required")
-	}
-
-	if _, exists := raw["status"]; !exists {
-		return errors.New("status is required")
-	}
-
-	// 檢查型別並賦值
-	name, ok := raw["name"].(string)
-	if !ok {
-		return errors.New("name must be a string")
-	}
-	
-	// 檢查 name 不能為空字串或僅包含空白字元
-	if strings.TrimSpace(name) == "" {
-		return errors.New("name cannot be empty")
-	}
-	
-	task.Name = name
+	return validation.Task(raw, task)
+}
 
-	status, ok := raw["status"].(float64)
-	if !ok {
-		return errors.New("status must be a number")
+// validationProblem 將請求解析或驗證的錯誤轉為 problem
+func validationProblem(err error) *problem.Error {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return problem.Validation(fields...)
 	}
-	
-	// 檢查 status 範圍
-	if status < 0 || status > 1 {
-		return errors.New("status must be 0 or 1")
-	}
-	
-	task.Status = int(status)
-
-	return nil
-}
\ No newline at end of file
+	return render.BindError(err)
+}
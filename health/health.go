@@ -0,0 +1,204 @@
+// Package health 提供 liveness 與 readiness 探針，readiness 會執行註冊的相依性檢查
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusFailing     = "failing"
+	StatusUnavailable = "unavailable"
+
+	// DefaultTimeout 未指定逾時的檢查使用的上限
+	DefaultTimeout = 2 * time.Second
+)
+
+// CheckFunc 檢查一項相依性，回傳 nil 表示正常；應遵守 ctx 的逾時
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// Result 單一檢查的結果
+type Result struct {
+	Name     string `json:"name" example:"storage"`
+	Status   string `json:"status" example:"ok" enums:"ok,failing"`
+	Error    string `json:"error,omitempty" example:"server is shutting down"`
+	Duration string `json:"duration" example:"35µs"`
+}
+
+// Report readiness 的結果，verbose 時包含每項檢查
+type Report struct {
+	Status string   `json:"status" example:"ok" enums:"ok,unavailable"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+// Checker 保存 readiness 檢查
+type Checker struct {
+	mu             sync.RWMutex
+	checks         []check
+	defaultTimeout time.Duration
+}
+
+// NewChecker 建立 Checker，timeout 為未指定逾時的檢查使用的上限，0 表示使用 DefaultTimeout
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{defaultTimeout: timeout}
+}
+
+// Register 註冊 readiness 檢查，timeout 為 0 時使用 Checker 的預設值；結果依註冊順序列出
+func (h *Checker) Register(name string, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = h.defaultTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// Run 同時執行所有檢查，每項檢查各自受逾時限制
+func (h *Checker) Run(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, r := range results {
+		if r.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+// run 執行單一檢查；檢查不理會 ctx 而卡住時，逾時後直接回報失敗，不等待它結束
+func run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := Result{Name: c.name, Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Gate 啟動時的初始化（例如載入快照）完成前回報失敗的檢查
+type Gate struct {
+	mu  sync.RWMutex
+	err error
+}
+
+// NewGate 建立尚未完成的 Gate，pending 為完成前回報的錯誤訊息
+func NewGate(pending string) *Gate {
+	return &Gate{err: errors.New(pending)}
+}
+
+// Done 記錄初始化的結果，err 為 nil 時檢查開始通過，否則持續回報 err
+func (g *Gate) Done(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.err = err
+}
+
+// Check 可作為 CheckFunc 註冊
+func (g *Gate) Check(ctx context.Context) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.err
+}
+
+// RegisterRoutes 註冊 /livez、/readyz 與相容舊版的 /health，這些路由不需要驗證
+func (h *Checker) RegisterRoutes(r gin.IRouter) {
+	r.GET("/livez", h.Livez)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/health", h.Readyz)
+}
+
+// Livez 處理 liveness 探針，只要程序能處理請求就回傳 200，不檢查相依性
+// @Summary Liveness probe
+// @Description Returns 200 as long as the process can serve requests. Dependencies are not checked, so a failing storage does not get the instance restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Router /livez [get]
+func (h *Checker) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// Readyz 處理 readiness 探針，所有檢查通過時回傳 200，否則回傳 503
+// @Summary Readiness probe
+// @Description Runs every registered check (storage ping, startup snapshot load, shutdown state, ...) with a per-check timeout. Returns 503 when any check fails. Add ?verbose to get the result of each check. GET /health is an alias kept for existing deployments.
+// @Tags health
+// @Produce json
+// @Param verbose query bool false "Include the result of each check"
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func (h *Checker) Readyz(c *gin.Context) {
+	report := h.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	if !verbose(c) {
+		report.Checks = nil
+	}
+	c.JSON(status, report)
+}
+
+// verbose 支援 ?verbose 與 ?verbose=true
+func verbose(c *gin.Context) bool {
+	v, ok := c.GetQuery("verbose")
+	if !ok {
+		return false
+	}
+	if v == "" {
+		return true
+	}
+	b, _ := strconv.ParseBool(v)
+	return b
+}
@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(checker *Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	checker.RegisterRoutes(router)
+	return router
+}
+
+func get(router http.Handler, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadyz(t *testing.T) {
+	var storageErr error
+	checker := NewChecker(time.Second)
+	checker.Register("shutdown", 0, func(ctx context.Context) error { return nil })
+	checker.Register("storage", 0, func(ctx context.Context) error { return storageErr })
+	router := newRouter(checker)
+
+	tests := []struct {
+		name           string
+		target         string
+		storageErr     error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "全部通過", target: "/readyz", expectedStatus: http.StatusOK, expectedBody: `{"status":"ok"}`},
+		{name: "舊的 /health", target: "/health", expectedStatus: http.StatusOK, expectedBody: `{"status":"ok"}`},
+		{name: "檢查失敗", target: "/readyz", storageErr: errors.New("disk full"), expectedStatus: http.StatusServiceUnavailable, expectedBody: `{"status":"unavailable"}`},
+		{name: "verbose=false", target: "/readyz?verbose=false", storageErr: errors.New("disk full"), expectedStatus: http.StatusServiceUnavailable, expectedBody: `{"status":"unavailable"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageErr = tt.storageErr
+			w := get(router, tt.target)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestReadyz_Verbose(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("shutdown", 0, func(ctx context.Context) error { return errors.New("server is shutting down") })
+	checker.Register("storage", 0, func(ctx context.Context) error { return nil })
+	router := newRouter(checker)
+
+	for _, target := range []string{"/readyz?verbose", "/readyz?verbose=1"} {
+		w := get(router, target)
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+		var report Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, StatusUnavailable, report.Status)
+		require.Len(t, report.Checks, 2)
+
+		// 依註冊順序列出
+		assert.Equal(t, "shutdown", report.Checks[0].Name)
+		assert.Equal(t, StatusFailing, report.Checks[0].Status)
+		assert.Equal(t, "server is shutting down", report.Checks[0].Error)
+		assert.Equal(t, "storage", report.Checks[1].Name)
+		assert.Equal(t, StatusOK, report.Checks[1].Status)
+		assert.Empty(t, report.Checks[1].Error)
+		assert.NotEmpty(t, report.Checks[1].Duration)
+	}
+}
+
+func TestReadyz_Gate(t *testing.T) {
+	snapshot := NewGate("snapshot is not loaded yet")
+	checker := NewChecker(time.Second)
+	checker.Register("shutdown", 0, func(ctx context.Context) error { return nil })
+	checker.Register("snapshot", 0, snapshot.Check)
+	checker.Register("storage", 0, func(ctx context.Context) error { return nil })
+	router := newRouter(checker)
+
+	tests := []struct {
+		name           string
+		done           func()
+		expectedStatus int
+		expectedCheck  string
+		expectedError  string
+	}{
+		{name: "載入完成前", done: func() {}, expectedStatus: http.StatusServiceUnavailable, expectedCheck: StatusFailing, expectedError: "snapshot is not loaded yet"},
+		{name: "載入失敗", done: func() { snapshot.Done(errors.New("snapshot checksum mismatch")) }, expectedStatus: http.StatusServiceUnavailable, expectedCheck: StatusFailing, expectedError: "snapshot checksum mismatch"},
+		{name: "載入完成", done: func() { snapshot.Done(nil) }, expectedStatus: http.StatusOK, expectedCheck: StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.done()
+			w := get(router, "/readyz?verbose")
+			require.Equal(t, tt.expectedStatus, w.Code)
+
+			var report Report
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			require.Len(t, report.Checks, 3)
+			assert.Equal(t, "snapshot", report.Checks[1].Name)
+			assert.Equal(t, tt.expectedCheck, report.Checks[1].Status)
+			assert.Equal(t, tt.expectedError, report.Checks[1].Error)
+		})
+	}
+}
+
+func TestReadyz_Timeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	checker := NewChecker(time.Second)
+	// 遵守 ctx 的檢查
+	checker.Register("slow", 20*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	// 不理會 ctx 而卡住的檢查
+	checker.Register("stuck", 20*time.Millisecond, func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+	router := newRouter(checker)
+
+	start := time.Now()
+	w := get(router, "/readyz?verbose")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "timed out after 20ms", report.Checks[0].Error)
+	assert.Equal(t, "timed out after 20ms", report.Checks[1].Error)
+}
+
+func TestLivez(t *testing.T) {
+	checker := NewChecker(0)
+	checker.Register("storage", 0, func(ctx context.Context) error { return errors.New("disk full") })
+	router := newRouter(checker)
+
+	// liveness 不受相依性影響
+	w := get(router, "/livez")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
@@ -0,0 +1,314 @@
+// Package ical 以 RFC 5545 VTODO 元件編碼與解析任務
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+
+	// 執行環境（alpine）可能沒有時區資料，內嵌以解析 TZID
+	_ "time/tzdata"
+)
+
+// MIMEType iCalendar 的 media type
+const MIMEType = "text/calendar"
+
+const (
+	statusCompleted   = "COMPLETED"
+	statusNeedsAction = "NEEDS-ACTION"
+
+	// 日期時間格式（UTC 與浮動時間）以及純日期格式
+	utcFormat      = "20060102T150405Z"
+	floatingFormat = "20060102T150405"
+	dateFormat     = "20060102"
+
+	// RFC 5545 建議每行不超過 75 octets
+	maxLineLength = 75
+)
+
+// Todo 解析後的 VTODO 元件
+type Todo struct {
+	UID     string
+	Summary string
+	Status  string
+	Due     *time.Time
+}
+
+// Completed 回傳 STATUS 是否為 COMPLETED
+func (t Todo) Completed() bool {
+	return strings.EqualFold(t.Status, statusCompleted)
+}
+
+// Encoder 將任務寫成 VCALENDAR
+type Encoder struct {
+	w       *bufio.Writer
+	now     time.Time
+	started bool
+	err     error
+}
+
+// NewEncoder 建立寫入 w 的 Encoder，呼叫 Close 才會寫出 END:VCALENDAR
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), now: time.Now().UTC()}
+}
+
+// Encode 寫出一個 VTODO
+func (e *Encoder) Encode(task model.Task) error {
+	e.begin()
+
+	status := statusNeedsAction
+	if task.Status == 1 {
+		status = statusCompleted
+	}
+
+	e.writeLine("BEGIN:VTODO")
+	e.writeLine("UID:" + escapeText(task.ID))
+	e.writeLine("DTSTAMP:" + e.now.Format(utcFormat))
+	e.writeLine("SUMMARY:" + escapeText(task.Name))
+	e.writeLine("STATUS:" + status)
+	if task.Due != nil {
+		e.writeLine("DUE:" + task.Due.UTC().Format(utcFormat))
+	}
+	e.writeLine("END:VTODO")
+	return e.err
+}
+
+// Close 寫出 END:VCALENDAR 並 flush
+func (e *Encoder) Close() error {
+	e.begin()
+	e.writeLine("END:VCALENDAR")
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// Flush 將目前緩衝的內容寫出
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+func (e *Encoder) begin() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.writeLine("BEGIN:VCALENDAR")
+	e.writeLine("VERSION:2.0")
+	e.writeLine("PRODID:-//gogolook//task-api//EN")
+}
+
+// writeLine 寫出一行內容，超過長度時依 RFC 5545 折行（不切斷 UTF-8 字元）
+func (e *Encoder) writeLine(line string) {
+	if e.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxLineLength {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+// escapeText 依 RFC 5545 3.3.11 跳脫 TEXT 值
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeText 還原 escapeText 的跳脫
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Decode 解析 iCalendar 內容，依序對每個 VTODO 呼叫 fn，其餘元件（VEVENT 等）會被忽略
+// fn 的 index 從 1 開始
+func Decode(r io.Reader, fn func(index int, todo Todo, err error)) error {
+	lines, err := unfold(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		current *Todo
+		index   int
+		todoErr error
+		depth   int
+	)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		name, params, value, err := parseContentLine(line)
+		if err != nil {
+			return err
+		}
+
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VTODO") {
+				current = &Todo{}
+				todoErr = nil
+				index++
+				depth = 0
+			} else if current != nil {
+				// VTODO 內的 VALARM 等子元件
+				depth++
+			}
+			continue
+		case "END":
+			if current == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if strings.EqualFold(value, "VTODO") {
+				fn(index, *current, todoErr)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil || depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = unescapeText(value)
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "STATUS":
+			current.Status = strings.ToUpper(value)
+		case "DUE":
+			due, err := parseDateTime(params, value)
+			if err != nil {
+				todoErr = err
+				continue
+			}
+			current.Due = &due
+		}
+	}
+
+	if current != nil {
+		return errors.New("invalid iCalendar: missing END:VTODO")
+	}
+	return nil
+}
+
+// unfold 讀取所有行並還原折行
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid iCalendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseContentLine 解析 "NAME;PARAM=VALUE:value" 格式的內容行
+func parseContentLine(line string) (string, map[string]string, string, error) {
+	// 參數值可能以雙引號包住並含有冒號，需略過引號內的內容
+	inQuote := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", fmt.Errorf("invalid iCalendar content line: %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], nil
+}
+
+// parseDateTime 解析 DATE-TIME 或 DATE 值，支援 UTC、TZID 與浮動時間（視為 UTC）
+func parseDateTime(params map[string]string, value string) (time.Time, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid DUE value %q", value)
+		}
+		return t, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcFormat, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid DUE value %q", value)
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(floatingFormat, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DUE value %q", value)
+	}
+	return t.UTC(), nil
+}
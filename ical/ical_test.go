@@ -0,0 +1,117 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeAll(t *testing.T, input string) ([]Todo, []error) {
+	t.Helper()
+	var todos []Todo
+	var errs []error
+	err := Decode(strings.NewReader(input), func(index int, todo Todo, err error) {
+		todos = append(todos, todo)
+		errs = append(errs, err)
+	})
+	require.NoError(t, err)
+	return todos, errs
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	tasks := []model.Task{
+		{ID: "1", Name: "Buy milk", Status: 0, Due: &due},
+		{ID: "2", Name: `Escape \ ; , and` + "\nnewline", Status: 1},
+		{ID: "3", Name: strings.Repeat("長任務名稱", 20), Status: 0},
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for _, task := range tasks {
+		require.NoError(t, enc.Encode(task))
+	}
+	require.NoError(t, enc.Close())
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(output, "END:VCALENDAR\r\n"))
+	assert.Contains(t, output, "STATUS:NEEDS-ACTION\r\n")
+	assert.Contains(t, output, "STATUS:COMPLETED\r\n")
+	assert.Contains(t, output, "DUE:20261019T093000Z\r\n")
+	for _, line := range strings.Split(output, "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength)
+	}
+
+	todos, errs := decodeAll(t, output)
+	require.Len(t, todos, len(tasks))
+	for i, task := range tasks {
+		require.NoError(t, errs[i])
+		assert.Equal(t, task.ID, todos[i].UID)
+		assert.Equal(t, task.Name, todos[i].Summary)
+		assert.Equal(t, task.Status == 1, todos[i].Completed())
+		assert.Equal(t, task.Due, todos[i].Due)
+	}
+}
+
+func TestEncode_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).Close())
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//gogolook//task-api//EN\r\nEND:VCALENDAR\r\n", buf.String())
+}
+
+func TestDecode(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:event",
+		"SUMMARY:Not a task",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:tz",
+		"SUMMARY:With",
+		"  TZID",
+		`DUE;TZID="Asia/Taipei":20261019T090000`,
+		"BEGIN:VALARM",
+		"SUMMARY:Alarm summary",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:date",
+		"SUMMARY:All day",
+		"DUE;VALUE=DATE:20261020",
+		"STATUS:completed",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:bad",
+		"SUMMARY:Bad due",
+		"DUE:tomorrow",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	todos, errs := decodeAll(t, input)
+	require.Len(t, todos, 3)
+
+	assert.Equal(t, "tz", todos[0].UID)
+	assert.Equal(t, "With TZID", todos[0].Summary)
+	require.NotNil(t, todos[0].Due)
+	assert.Equal(t, time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC), *todos[0].Due)
+	assert.False(t, todos[0].Completed())
+
+	assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), *todos[1].Due)
+	assert.True(t, todos[1].Completed())
+	assert.NoError(t, errs[1])
+
+	assert.EqualError(t, errs[2], `invalid DUE value "tomorrow"`)
+}
+
+func TestDecode_Unterminated(t *testing.T) {
+	err := Decode(strings.NewReader("BEGIN:VTODO\r\nUID:1\r\n"), func(int, Todo, error) {})
+	assert.EqualError(t, err, "invalid iCalendar: missing END:VTODO")
+}
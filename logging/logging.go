@@ -0,0 +1,93 @@
+// Package logging 以 log/slog 輸出結構化日誌，並提供帶有 request ID 的 logger 在 context 中傳遞
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Redacted 取代敏感欄位值的字串
+const Redacted = "[REDACTED]"
+
+// DefaultRedact 預設遮蔽的欄位名稱（不分大小寫），涵蓋憑證相關的 header 與參數
+var DefaultRedact = []string{"authorization", "cookie", "set-cookie", "x-api-key", "api_key", "password", "secret", "token", "admin_key"}
+
+var (
+	// ErrInvalidLevel 日誌等級不是 debug、info、warn 或 error
+	ErrInvalidLevel = errors.New("must be debug, info, warn or error")
+	// ErrInvalidFormat 日誌格式不是 json 或 text
+	ErrInvalidFormat = errors.New("must be json or text")
+)
+
+// Options 日誌的設定
+type Options struct {
+	// Level 最低輸出等級：debug、info、warn 或 error
+	Level string
+	// Format 輸出格式：json 或 text
+	Format string
+	// Redact 需要遮蔽的欄位名稱（不分大小寫），會與 DefaultRedact 合併
+	Redact []string
+}
+
+// ParseLevel 解析日誌等級
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("%w, got %q", ErrInvalidLevel, s)
+	}
+	return level, nil
+}
+
+// New 建立寫到 w 的 logger，名稱在遮蔽清單中的欄位（含 group 內的欄位）一律輸出為 [REDACTED]
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	redact := make(map[string]bool, len(DefaultRedact)+len(opts.Redact))
+	for _, key := range append(DefaultRedact, opts.Redact...) {
+		redact[strings.ToLower(key)] = true
+	}
+	handlerOpts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if redact[strings.ToLower(a.Key)] {
+				return slog.String(a.Key, Redacted)
+			}
+			return a
+		},
+	}
+
+	switch opts.Format {
+	case FormatJSON, "":
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	}
+	return nil, fmt.Errorf("%w, got %q", ErrInvalidFormat, opts.Format)
+}
+
+type contextKey struct{}
+
+// WithContext 將 logger 放進 ctx，之後的 handler 與 storage 以 FromContext 取出
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext 取出 ctx 中的 logger，沒有時回傳 slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
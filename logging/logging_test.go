@@ -0,0 +1,176 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRouter 建立輸出 JSON 日誌到 logs 的 router
+func newRouter(t *testing.T, logs *bytes.Buffer, level string, sampleRate float64) *gin.Engine {
+	logger, err := New(logs, Options{Level: level, Format: FormatJSON, Redact: []string{"X-Internal"}})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(logger, MiddlewareOptions{SampleRate: sampleRate}))
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		FromContext(c.Request.Context()).Info("handler")
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Error(assert.AnError)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tasks"})
+	})
+	return router
+}
+
+// entries 解析每一行 JSON 日誌
+func entries(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		reuse     bool
+	}{
+		{name: "沿用客戶端的 ID", requestID: "req-123", reuse: true},
+		{name: "沒有帶 ID 時產生", requestID: ""},
+		{name: "含控制字元時重新產生", requestID: "bad\tid"},
+		{name: "過長時重新產生", requestID: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			router := newRouter(t, &logs, "info", 1)
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			require.NotEmpty(t, id)
+			if tt.reuse {
+				assert.Equal(t, tt.requestID, id)
+			} else {
+				assert.NotEqual(t, tt.requestID, id)
+			}
+
+			// handler 的日誌與請求日誌都帶有同一個 request ID
+			logged := entries(t, &logs)
+			require.Len(t, logged, 2)
+			assert.Equal(t, "handler", logged[0]["msg"])
+			assert.Equal(t, id, logged[0]["request_id"])
+			assert.Equal(t, "request", logged[1]["msg"])
+			assert.Equal(t, id, logged[1]["request_id"])
+			assert.Equal(t, "/tasks/:id", logged[1]["route"])
+			assert.Equal(t, float64(http.StatusOK), logged[1]["status"])
+		})
+	}
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	var logs bytes.Buffer
+	router := newRouter(t, &logs, "info", 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(RequestIDHeader, "req-500")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "req-500", w.Header().Get(RequestIDHeader))
+
+	// handler 以 c.Error 記錄的原因會出現在請求日誌
+	logged := entries(t, &logs)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "ERROR", logged[0]["level"])
+	assert.Equal(t, "req-500", logged[0]["request_id"])
+	assert.Equal(t, []interface{}{assert.AnError.Error()}, logged[0]["errors"])
+}
+
+func TestMiddleware_Sampling(t *testing.T) {
+	var logs bytes.Buffer
+	router := newRouter(t, &logs, "warn", 0)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/tasks/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-such-route", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	// 成功的請求不取樣，失敗的請求一律記錄
+	logged := entries(t, &logs)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "WARN", logged[0]["level"])
+	assert.Equal(t, float64(http.StatusNotFound), logged[0]["status"])
+	assert.Equal(t, "ERROR", logged[1]["level"])
+}
+
+func TestMiddleware_Redaction(t *testing.T) {
+	var logs bytes.Buffer
+	router := newRouter(t, &logs, "debug", 1)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/1?token=abc&page=2", nil)
+	req.Header.Set("Authorization", "Bearer tk_secret")
+	req.Header.Set("X-API-Key", "tk_secret")
+	req.Header.Set("X-Internal", "hidden")
+	req.Header.Set("Accept", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.NotContains(t, logs.String(), "tk_secret")
+	assert.NotContains(t, logs.String(), "hidden")
+
+	logged := entries(t, &logs)
+	require.Len(t, logged, 2)
+	headers := logged[1]["headers"].(map[string]interface{})
+	assert.Equal(t, Redacted, headers["Authorization"])
+	assert.Equal(t, Redacted, headers["X-Api-Key"])
+	assert.Equal(t, Redacted, headers["X-Internal"])
+	assert.Equal(t, "application/json", headers["Accept"])
+	assert.Equal(t, map[string]interface{}{"token": Redacted, "page": "2"}, logged[1]["query"])
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		expectedErr error
+	}{
+		{name: "JSON", opts: Options{Level: "info", Format: FormatJSON}},
+		{name: "文字", opts: Options{Level: "DEBUG", Format: FormatText}},
+		{name: "不支援的等級", opts: Options{Level: "verbose", Format: FormatJSON}, expectedErr: ErrInvalidLevel},
+		{name: "不支援的格式", opts: Options{Level: "info", Format: "xml"}, expectedErr: ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.opts)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
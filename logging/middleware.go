@@ -0,0 +1,118 @@
+package logging
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader 帶入與回傳 request ID 的 header
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength 沿用客戶端 request ID 的長度上限，超過時改為產生新的 ID
+const maxRequestIDLength = 128
+
+// requestIDKey 儲存 request ID 的 gin context key
+const requestIDKey = "logging.request_id"
+
+// MiddlewareOptions 請求日誌的設定
+type MiddlewareOptions struct {
+	// SampleRate 成功請求（狀態碼小於 400）記錄的比例，0 到 1；失敗的請求一律記錄
+	SampleRate float64
+}
+
+// RequestID 回傳目前請求的 request ID，未經過 Middleware 時為空字串
+func RequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Middleware 為每個請求指定 request ID，並在請求結束後輸出一筆結構化日誌
+// request ID 取自 X-Request-ID（格式不合時重新產生），會回傳在 X-Request-ID header，錯誤回應另以 instance 欄位帶出
+// 帶有 request ID 的 logger 放在 c.Request 的 context，handler 與 storage 以 FromContext 取出
+func Middleware(logger *slog.Logger, opts MiddlewareOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		reqLogger := logger.With("request_id", id)
+		c.Request = c.Request.WithContext(WithContext(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest && !sampled(opts.SampleRate) {
+			return
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		ctx := c.Request.Context()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if query := c.Request.URL.Query(); len(query) > 0 {
+			attrs = append(attrs, slog.Any("query", valuesGroup(query)))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", c.Errors.Errors()))
+		}
+		// header 可能很多，只在 debug 等級輸出；憑證相關的 header 會被遮蔽
+		if reqLogger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", valuesGroup(c.Request.Header)))
+		}
+		reqLogger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// valuesGroup 將 header 或 query 轉為 slog group，讓 ReplaceAttr 能逐一遮蔽敏感欄位
+func valuesGroup(values map[string][]string) slog.Value {
+	attrs := make([]slog.Attr, 0, len(values))
+	for key, v := range values {
+		attrs = append(attrs, slog.String(key, strings.Join(v, ",")))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// validRequestID 客戶端的 request ID 需為長度有限的可見 ASCII 字元，避免日誌注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func sampled(rate float64) bool {
+	return rate >= 1 || rand.Float64() < rate
+}
@@ -1,11 +1,42 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
 	"net/http"
-	
+	"os"
+	"os/signal"
+	runtimedebug "runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/config"
+	"github.com/gogolook/task-api/cors"
+	"github.com/gogolook/task-api/handler/apikey"
+	"github.com/gogolook/task-api/handler/debug"
+	"github.com/gogolook/task-api/handler/limits"
+	"github.com/gogolook/task-api/handler/render"
+	"github.com/gogolook/task-api/handler/snapshots"
 	"github.com/gogolook/task-api/handler/task"
+	"github.com/gogolook/task-api/health"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/metrics"
+	"github.com/gogolook/task-api/problem"
+	"github.com/gogolook/task-api/ratelimit"
+	"github.com/gogolook/task-api/rbac"
+	"github.com/gogolook/task-api/server"
+	"github.com/gogolook/task-api/snapshot"
 	"github.com/gogolook/task-api/storage"
+	"github.com/gogolook/task-api/tenant"
+	"github.com/gogolook/task-api/tracing"
 )
 
 // @title Task API
@@ -24,41 +55,331 @@ import (
 // @BasePath /
 // @schemes https http
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
 func main() {
-	r := gin.Default()
-
-	// CORS middleware - 允許前端域名和 GitHub Pages
-	r.Use(func(c *gin.Context) {
-		origin := c.GetHeader("Origin")
-		if origin == "https://etrex.tw" || origin == "https://etrex.github.io" {
-			c.Header("Access-Control-Allow-Origin", origin)
-			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Content-Type")
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 之後所有日誌（含標準 log 套件的輸出）都以結構化格式寫到 stderr
+	logger, err := logging.New(os.Stderr, cfg.Log.Options())
+	if err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// SIGTERM（Cloud Run 部署、docker stop）與 Ctrl+C 觸發優雅關閉
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	gin.SetMode(cfg.Server.Mode)
+	r := gin.New()
+	// 未設定時不信任任何 X-Forwarded-For，避免偽造 header 繞過依 client IP 計算的速率限制
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("server.trusted_proxies: %v", err)
+	}
+	srv := server.New(cfg.Server.Addr, r, server.Options{
+		ShutdownTimeout: time.Duration(cfg.Server.ShutdownTimeout),
+		ShutdownDelay:   time.Duration(cfg.Server.ShutdownDelay),
+	})
+
+	// 請求日誌放在最前面，之後的 middleware 都能取得 request ID 與 logger；panic 也會被記錄為 500
+	// 錯誤回應的設定需在任何可能回傳錯誤的 middleware 之前套用
+	r.Use(logging.Middleware(logger, logging.MiddlewareOptions{SampleRate: cfg.Log.SampleRate}))
+	r.Use(problem.Middleware(cfg.Errors.Options()))
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, recoverPanic))
+	r.NoRoute(problem.NoRoute)
+
+	// 指標 middleware 需在驗證之前，被 CORS、驗證或速率限制擋下的請求也要記錄
+	var m *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		m = metrics.New()
+		r.Use(m.Middleware())
+	}
+
+	// tracing 的 root span 需涵蓋之後所有 middleware 與 handler
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Options())
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	r.Use(tracing.Middleware())
+
+	// CORS 需在驗證之前處理，preflight 請求不帶憑證
+	r.Use(corsMiddleware(cfg))
+
+	// body 大小上限在讀取前套用，匯入以串流解析，允許較大的 body
+	r.Use(render.LimitBody(render.BodyLimits{
+		MaxSize:  int64(cfg.Server.MaxBodySize),
+		MaxDepth: cfg.Server.MaxJSONDepth,
+		Routes:   map[string]int64{"POST /tasks/import": int64(cfg.Server.MaxImportSize)},
+	}))
+
+	taskStorage, tenantMiddleware := newTaskStorage(cfg.Tenancy)
+	// readiness 檢查直接使用原本的 storage，ping 不計入操作指標
+	pinger, canPing := taskStorage.(storage.Pinger)
+	snapshotter, canSnapshot := taskStorage.(storage.Snapshotter)
+	if purger, ok := taskStorage.(storage.TrashPurger); ok {
+		go storage.RunPurger(ctx, purger, time.Duration(cfg.Storage.TrashRetention), time.Duration(cfg.Storage.TrashPurgeInterval))
+	}
+	if m != nil {
+		taskStorage = m.InstrumentStorage(taskStorage)
+	}
+	if cfg.Tracing.Enabled() {
+		taskStorage = tracing.InstrumentStorage(taskStorage)
+	}
+	taskHandler := task.NewTaskHandler(taskStorage)
+	srv.OnShutdown(taskStorage.Close)
+	// storage 關閉後才 flush span，關閉過程中的 span 也能送出
+	srv.OnShutdown(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
+
+	keyStore := auth.NewMemoryKeyStore()
+	keyHandler := apikey.NewAPIKeyHandler(keyStore)
+
+	// 需要驗證的路由，各路由需要的權限由 rbac 政策決定
+	api := r.Group("/")
+	if cfg.Auth.Enabled {
+		bootstrapAdminKey(keyStore, cfg.Auth.AdminKey)
+		authenticators := []auth.Authenticator{auth.APIKeyAuthenticator{Store: keyStore}}
+		if jwtAuth := jwtAuthenticator(cfg.Auth.JWT); jwtAuth != nil {
+			authenticators = append(authenticators, jwtAuth)
+		}
+		api.Use(auth.Middleware(authenticators...))
+	} else {
+		slog.Warn("authentication is disabled (auth.enabled=false)")
+	}
+	// 速率限制放在驗證之後，才能依 API key 區分呼叫者；停用驗證時依 client IP 計算
+	// 每日建立配額由建立與匯入任務的 handler 依建立的任務數計算
+	limiter := rateLimiter(cfg.RateLimit)
+	if limiter != nil {
+		api.Use(limiter.Middleware())
+	}
+	// tenant 需在驗證之後解析，才能以憑證中的 tenant 為準
+	if tenantMiddleware != nil {
+		api.Use(tenantMiddleware)
+	}
+	if cfg.Auth.Enabled {
+		api.Use(policyEnforcer(ctx, cfg.Auth).Middleware(taskHandler.Relation))
+	}
+
+	taskHandler.RegisterRoutes(api)
+	// 清空與還原任務除了 rbac 政策外，另外要求 admin scope，自訂政策誤開放也不會生效；需明確開啟 server.allow_wipe 才註冊
+	if cfg.Server.AllowWipe {
+		slog.Warn("DELETE /admin/tasks is enabled (server.allow_wipe=true)")
+		taskHandler.RegisterAdminRoutes(api.Group("/", auth.RequireScope(auth.ScopeAdmin)))
+	}
+	// 快照包含所有 tenant 的資料，還原會取代所有 tenant 的資料；除了 admin scope 外，也不接受限定 tenant 的憑證
+	var snapshotStore *snapshot.Store
+	if canSnapshot {
+		snapshotStore, err = snapshot.NewStore(cfg.Storage.SnapshotDir, cfg.Storage.SnapshotKeep)
+		if err != nil {
+			log.Fatalf("snapshot: %v", err)
 		}
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		snapshots.NewSnapshotHandler(snapshotter, snapshotStore).RegisterRoutes(api.Group("/", auth.RequireScope(auth.ScopeAdmin), auth.RequireGlobal()))
+	}
+	// 管理 API key 與速率限制的路由除了 rbac 政策外另外要求 admin scope；停用驗證時沒有 rbac，仍不會對匿名呼叫者開放
+	admin := api.Group("/", auth.RequireScope(auth.ScopeAdmin))
+	keyHandler.RegisterRoutes(admin)
+	if limiter != nil {
+		limits.NewLimitsHandler(limiter).RegisterRoutes(admin)
+	}
+	// 生效中的設定涵蓋所有 tenant，只開放給未綁定 tenant 的 admin
+	debug.NewDebugHandler(cfg).RegisterRoutes(api.Group("/", auth.RequireScope(auth.ScopeAdmin), auth.RequireGlobal()))
+
+	// 健康檢查 endpoint，不需要驗證；關閉流程中 readiness 失敗，讓負載平衡器停止導入流量
+	checker := health.NewChecker(time.Duration(cfg.Health.CheckTimeout))
+	checker.Register("shutdown", 0, func(ctx context.Context) error {
+		if srv.Draining() {
+			return errors.New("server is shutting down")
 		}
-		
-		c.Next()
+		return nil
 	})
+	// 啟動時的快照在背景載入，完成前 readiness 失敗；未設定 storage.load_snapshot 時直接通過
+	snapshotLoaded := health.NewGate("snapshot is not loaded yet")
+	checker.Register("snapshot", 0, snapshotLoaded.Check)
+	go func() {
+		snapshotLoaded.Done(loadSnapshot(ctx, snapshotter, snapshotStore, cfg.Storage.LoadSnapshot))
+	}()
+	if canPing {
+		checker.Register("storage", 0, pinger.Ping)
+	}
+	checker.RegisterRoutes(r)
+	if m != nil {
+		m.RegisterRoutes(r)
+	}
 
-	memStorage := storage.NewMemoryStorage()
-	taskHandler := task.NewTaskHandler(memStorage)
-
-	r.GET("/tasks", taskHandler.ListTasks)
-	r.GET("/tasks/:id", taskHandler.GetTask)
-	r.POST("/tasks", taskHandler.CreateTask)
-	r.PUT("/tasks/:id", taskHandler.UpdateTask)
-	r.DELETE("/tasks/:id", taskHandler.DeleteTask)
-	r.DELETE("/tasks", taskHandler.DeleteAllTasks)
-	
-	// 健康檢查 endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
-	})
+	slog.Info("listening", "addr", cfg.Server.Addr)
+	if err := srv.ListenAndServe(ctx); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+}
+
+// recoverPanic 記錄 handler 的 panic 並回傳 500，避免單一請求讓服務中止
+func recoverPanic(c *gin.Context, recovered any) {
+	logging.FromContext(c.Request.Context()).Error("panic recovered",
+		"panic", fmt.Sprint(recovered),
+		"stack", string(runtimedebug.Stack()),
+	)
+	problem.Abort(c, problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal server error"))
+}
+
+// newTaskStorage 建立任務 storage
+// 啟用 tenancy 時每個 tenant 使用獨立的分區，並回傳解析 tenant 的 middleware
+func newTaskStorage(cfg config.TenancyConfig) (storage.Storage, gin.HandlerFunc) {
+	if !cfg.Enabled {
+		return storage.NewMemoryStorage(), nil
+	}
 
+	var resolvers []tenant.Resolver
+	if cfg.Header != "" {
+		resolvers = append(resolvers, tenant.Header(cfg.Header))
+	}
+	if cfg.Domain != "" {
+		resolvers = append(resolvers, tenant.Subdomain(cfg.Domain))
+	}
 
-	r.Run(":8080")
-}
\ No newline at end of file
+	return storage.NewTenantStorage(cfg.MaxTasks, cfg.Quotas), tenant.Middleware(resolvers...)
+}
+
+// loadSnapshot 還原 storage.load_snapshot 指定的快照；latest 時沒有任何快照視為成功，以空的資料啟動
+func loadSnapshot(ctx context.Context, s storage.Snapshotter, store *snapshot.Store, id string) error {
+	if id == "" {
+		return nil
+	}
+	if store == nil {
+		err := errors.New("storage does not support snapshots")
+		slog.Error("failed to load snapshot", "id", id, "error", err)
+		return err
+	}
+
+	if id == "latest" {
+		infos, err := store.List()
+		if err != nil {
+			slog.Error("failed to load snapshot", "id", id, "error", err)
+			return err
+		}
+		if len(infos) == 0 {
+			slog.Info("no snapshot to load")
+			return nil
+		}
+		id = infos[0].ID
+	}
+
+	snap, err := store.Load(id)
+	if err == nil {
+		err = s.RestoreSnapshot(ctx, snap)
+	}
+	if err != nil {
+		slog.Error("failed to load snapshot", "id", id, "error", err)
+		return fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	slog.Info("loaded snapshot", "id", id, "tasks", snap.Count())
+	return nil
+}
+
+// bootstrapAdminKey 載入設定的 admin key 作為初始 admin key，未設定時產生一組並印到 stderr
+func bootstrapAdminKey(store *auth.MemoryKeyStore, adminKey config.Secret) {
+	if adminKey == "" {
+		secret, _, err := store.Issue("bootstrap-admin", auth.ScopeAdmin, "")
+		if err != nil {
+			log.Fatalf("failed to issue bootstrap admin key: %v", err)
+		}
+		// key 只直接印到 stderr 一次，不經過 log pipeline，不會成為 log 記錄的欄位
+		slog.Warn("TASK_API_ADMIN_KEY is not set, generated a bootstrap admin key and printed it to stderr")
+		fmt.Fprintf(os.Stderr, "bootstrap admin key: %s\n", secret)
+		return
+	}
+
+	if _, err := store.Add("bootstrap-admin", auth.ScopeAdmin, "", adminKey.Value()); err != nil {
+		log.Fatalf("failed to load bootstrap admin key: %v", err)
+	}
+}
+
+// jwtAuthenticator 依 auth.jwt 設定建立 JWT 驗證，未設定 JWKS URL 或 key file 時回傳 nil
+func jwtAuthenticator(cfg config.JWTConfig) auth.Authenticator {
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	var keys *auth.KeySet
+	if cfg.JWKSURL != "" {
+		keys = auth.NewRemoteKeySet(cfg.JWKSURL)
+	} else {
+		ks, err := auth.NewStaticKeySet(cfg.KeyFile)
+		if err != nil {
+			log.Fatalf("failed to load JWT key file: %v", err)
+		}
+		keys = ks
+	}
+
+	return auth.JWTAuthenticator{
+		Keys:         keys,
+		Issuer:       cfg.Issuer,
+		Audience:     cfg.Audience,
+		ScopeClaim:   cfg.ScopeClaim,
+		TenantClaim:  cfg.TenantClaim,
+		DefaultScope: auth.Scope(cfg.DefaultScope),
+	}
+}
+
+// policyEnforcer 載入 auth.policy_file 指定的政策並監看變更，未設定時使用內建政策
+func policyEnforcer(ctx context.Context, cfg config.AuthConfig) *rbac.Enforcer {
+	if cfg.PolicyFile == "" {
+		return rbac.NewEnforcer(rbac.DefaultPolicy())
+	}
+
+	enforcer, err := rbac.NewFileEnforcer(cfg.PolicyFile)
+	if err != nil {
+		log.Fatalf("failed to load policy file: %v", err)
+	}
+	go enforcer.Watch(ctx, time.Duration(cfg.PolicyReloadInterval))
+	return enforcer
+}
+
+// rateLimiter 依 rate_limit 設定建立速率限制，停用時回傳 nil
+func rateLimiter(cfg config.RateLimitConfig) *ratelimit.Limiter {
+	if !cfg.Enabled {
+		slog.Warn("rate limiting is disabled (rate_limit.enabled=false)")
+		return nil
+	}
+
+	limiter, err := ratelimit.New(cfg.Limits())
+	if err != nil {
+		log.Fatalf("invalid rate limits: %v", err)
+	}
+	return limiter
+}
+
+// corsMiddleware 依 cors 設定建立 CORS 政策
+func corsMiddleware(cfg *config.Config) gin.HandlerFunc {
+	policy := cfg.CORS.Policy()
+	// 自訂的 tenant header 也需要允許瀏覽器送出
+	if header := cfg.Tenancy.Header; cfg.Tenancy.Enabled && header != "" && !containsFold(policy.AllowedHeaders, header) {
+		policy.AllowedHeaders = append(policy.AllowedHeaders, header)
+	}
+
+	middleware, err := cors.New(policy)
+	if err != nil {
+		log.Fatalf("invalid CORS configuration: %v", err)
+	}
+	return middleware
+}
+
+func containsFold(items []string, s string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+// Package metrics 以 Prometheus 文字格式提供 HTTP、storage 與 Go runtime 指標
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "task_api"
+
+// unmatchedRoute 沒有對應路由的請求使用的 route 標籤，避免任意路徑造成標籤數量暴增
+const unmatchedRoute = "unmatched"
+
+// Metrics 保存所有指標，使用獨立的 Registry，不依賴 prometheus 的全域狀態
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+
+	storageDuration *prometheus.HistogramVec
+	lockWait        *prometheus.HistogramVec
+}
+
+// New 建立 Metrics 並註冊 Go runtime 與 process 指標
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests currently being served by method and route.",
+		}, []string{"method", "route"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Storage operation latency by operation and result.",
+			// 記憶體操作多在微秒等級，從 10µs 開始
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"operation", "result"}),
+		lockWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "lock_wait_seconds",
+			Help:      "Time spent waiting to acquire the storage lock by mode (read or write).",
+			Buckets:   prometheus.ExponentialBuckets(0.000001, 4, 10),
+		}, []string{"mode"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.inFlight,
+		m.storageDuration,
+		m.lockWait,
+	)
+	return m
+}
+
+// Registry 回傳指標使用的 Registry，可註冊其他 collector
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterRoutes 註冊 GET /metrics，與健康檢查相同不需要驗證
+func (m *Metrics) RegisterRoutes(r gin.IRouter) {
+	r.GET("/metrics", m.Handler)
+}
+
+// Handler 以 Prometheus 文字格式輸出所有指標
+// @Summary Prometheus metrics
+// @Description Exposes HTTP, storage and Go runtime metrics in the Prometheus text exposition format. HTTP metrics are labelled by the route pattern (e.g. /tasks/{id}), never by the raw path.
+// @Tags metrics
+// @Produce plain
+// @Success 200 {string} string "Prometheus text exposition format"
+// @Router /metrics [get]
+func (m *Metrics) Handler(c *gin.Context) {
+	promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}).ServeHTTP(c.Writer, c.Request)
+}
+
+// Middleware 記錄每個請求的次數、延遲與處理中的數量
+// route 標籤使用路由樣式（例如 /tasks/:id），沒有對應路由時為 "unmatched"
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := m.inFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		start := time.Now()
+		defer func() {
+			inFlight.Dec()
+			m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			m.requests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		}()
+
+		c.Next()
+	}
+}
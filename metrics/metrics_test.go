@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(m *Metrics) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Middleware())
+	m.RegisterRoutes(router)
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	return router
+}
+
+func get(router http.Handler, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	router := newRouter(m)
+
+	get(router, "/tasks/1")
+	get(router, "/tasks/2")
+	get(router, "/no-such-route")
+
+	tests := []struct {
+		name     string
+		labels   []string
+		expected float64
+	}{
+		{name: "以路由樣式而非實際路徑計數", labels: []string{"GET", "/tasks/:id", "404"}, expected: 2},
+		{name: "沒有對應路由", labels: []string{"GET", "unmatched", "404"}, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, testutil.ToFloat64(m.requests.WithLabelValues(tt.labels...)))
+		})
+	}
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight.WithLabelValues("GET", "/tasks/:id")))
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	s := m.InstrumentStorage(storage.NewMemoryStorage())
+	router := newRouter(m)
+
+	access := storage.Access{All: true}
+	require.NoError(t, s.Create(context.Background(), access, &model.Task{Name: "Task"}))
+	_, err := s.Get(context.Background(), access, "missing")
+	require.ErrorIs(t, err, storage.ErrTaskNotFound)
+	get(router, "/tasks/1")
+
+	w := get(router, "/metrics")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+
+	body := w.Body.String()
+	for _, expected := range []string{
+		`task_api_http_requests_total{method="GET",route="/tasks/:id",status="404"} 1`,
+		`task_api_http_request_duration_seconds_count{method="GET",route="/tasks/:id"} 1`,
+		`task_api_storage_operation_duration_seconds_count{operation="create",result="ok"} 1`,
+		`task_api_storage_operation_duration_seconds_count{operation="get",result="not_found"} 1`,
+		`task_api_storage_tasks 1`,
+		`task_api_storage_lock_wait_seconds_count{mode="write"} 1`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, body, expected)
+	}
+}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "成功", err: nil, expected: "ok"},
+		{name: "找不到任務", err: storage.ErrTaskNotFound, expected: "not_found"},
+		{name: "權限不足", err: storage.ErrPermissionDenied, expected: "permission_denied"},
+		{name: "超過上限", err: storage.ErrQuotaExceeded, expected: "quota_exceeded"},
+		{name: "其他錯誤", err: storage.ErrTenantRequired, expected: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, result(tt.err))
+		})
+	}
+}
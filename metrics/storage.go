@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InstrumentStorage 以記錄操作延遲的 decorator 包裝 s
+// s 實作 storage.Counter 時一併提供任務數，實作 storage.LockObservable 時記錄鎖等待時間
+func (m *Metrics) InstrumentStorage(s storage.Storage) storage.Storage {
+	if counter, ok := s.(storage.Counter); ok {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "tasks",
+			Help:      "Number of tasks currently stored.",
+		}, func() float64 { return float64(counter.Count()) }))
+	}
+	if observable, ok := s.(storage.LockObservable); ok {
+		read, write := m.lockWait.WithLabelValues("read"), m.lockWait.WithLabelValues("write")
+		observable.SetLockObserver(func(isWrite bool, wait time.Duration) {
+			if isWrite {
+				write.Observe(wait.Seconds())
+				return
+			}
+			read.Observe(wait.Seconds())
+		})
+	}
+	return &instrumentedStorage{next: s, duration: m.storageDuration}
+}
+
+type instrumentedStorage struct {
+	next     storage.Storage
+	duration *prometheus.HistogramVec
+}
+
+// observe 記錄一次操作的延遲，依錯誤分類 result 標籤
+func (s *instrumentedStorage) observe(operation string, start time.Time, err error) {
+	s.duration.WithLabelValues(operation, result(err)).Observe(time.Since(start).Seconds())
+}
+
+func result(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, storage.ErrTaskNotFound), errors.Is(err, storage.ErrShareNotFound):
+		return "not_found"
+	case errors.Is(err, storage.ErrPermissionDenied):
+		return "permission_denied"
+	case errors.Is(err, storage.ErrQuotaExceeded):
+		return "quota_exceeded"
+	}
+	return "error"
+}
+
+func (s *instrumentedStorage) List(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+	start := time.Now()
+	res, err := s.next.List(ctx, access, params)
+	s.observe("list", start, err)
+	return res, err
+}
+
+func (s *instrumentedStorage) Scan(ctx context.Context, access storage.Access, chunk int, fn func(tasks []model.Task) error) error {
+	start := time.Now()
+	err := s.next.Scan(ctx, access, chunk, fn)
+	s.observe("scan", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Get(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+	start := time.Now()
+	task, err := s.next.Get(ctx, access, id)
+	s.observe("get", start, err)
+	return task, err
+}
+
+func (s *instrumentedStorage) Create(ctx context.Context, access storage.Access, task *model.Task) error {
+	start := time.Now()
+	err := s.next.Create(ctx, access, task)
+	s.observe("create", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Update(ctx context.Context, access storage.Access, id string, task *model.Task) error {
+	start := time.Now()
+	err := s.next.Update(ctx, access, id, task)
+	s.observe("update", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Upsert(ctx context.Context, access storage.Access, task *model.Task) (bool, error) {
+	start := time.Now()
+	created, err := s.next.Upsert(ctx, access, task)
+	s.observe("upsert", start, err)
+	return created, err
+}
+
+func (s *instrumentedStorage) Import(ctx context.Context, access storage.Access, tasks []model.Task, upsert bool) (*storage.ImportResult, error) {
+	start := time.Now()
+	result, err := s.next.Import(ctx, access, tasks, upsert)
+	s.observe("import", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorage) Delete(ctx context.Context, access storage.Access, id string) error {
+	start := time.Now()
+	err := s.next.Delete(ctx, access, id)
+	s.observe("delete", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Purge(ctx context.Context, access storage.Access, id string) error {
+	start := time.Now()
+	err := s.next.Purge(ctx, access, id)
+	s.observe("purge", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Trash(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+	start := time.Now()
+	result, err := s.next.Trash(ctx, access, params)
+	s.observe("trash", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorage) Restore(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+	start := time.Now()
+	task, err := s.next.Restore(ctx, access, id)
+	s.observe("restore", start, err)
+	return task, err
+}
+
+func (s *instrumentedStorage) DeleteAll(ctx context.Context, access storage.Access) error {
+	start := time.Now()
+	err := s.next.DeleteAll(ctx, access)
+	s.observe("delete_all", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) RestoreAll(ctx context.Context, access storage.Access) (int, error) {
+	start := time.Now()
+	restored, err := s.next.RestoreAll(ctx, access)
+	s.observe("restore_all", start, err)
+	return restored, err
+}
+
+func (s *instrumentedStorage) Share(ctx context.Context, access storage.Access, id string, share model.Share) error {
+	start := time.Now()
+	err := s.next.Share(ctx, access, id, share)
+	s.observe("share", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Unshare(ctx context.Context, access storage.Access, id, userID string) error {
+	start := time.Now()
+	err := s.next.Unshare(ctx, access, id, userID)
+	s.observe("unshare", start, err)
+	return err
+}
+
+func (s *instrumentedStorage) Shares(ctx context.Context, access storage.Access, id string) ([]model.Share, error) {
+	start := time.Now()
+	shares, err := s.next.Shares(ctx, access, id)
+	s.observe("shares", start, err)
+	return shares, err
+}
+
+func (s *instrumentedStorage) Close() error {
+	return s.next.Close()
+}
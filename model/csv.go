@@ -0,0 +1,18 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// CSVHeader 任務 CSV 的欄位名稱
+var CSVHeader = []string{"id", "name", "status", "due"}
+
+// CSVRecord 將任務轉為 CSV 的一列，沒有到期時間時 due 欄位留空
+func CSVRecord(t Task) []string {
+	due := ""
+	if t.Due != nil {
+		due = t.Due.UTC().Format(time.RFC3339)
+	}
+	return []string{t.ID, t.Name, strconv.Itoa(t.Status), due}
+}
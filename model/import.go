@@ -0,0 +1,18 @@
+package model
+
+// ImportRowError represents a validation error for a single imported row
+type ImportRowError struct {
+	Row   int    `json:"row" example:"3"`
+	Error string `json:"error" example:"status must be 0 or 1"`
+	// Fields lists every invalid field of the row; empty when the row could not be parsed
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ImportResult represents the result of a bulk import
+type ImportResult struct {
+	DryRun  bool             `json:"dry_run" example:"false"`
+	Total   int              `json:"total" example:"120"`
+	Created int              `json:"created" example:"100"`
+	Updated int              `json:"updated" example:"20"`
+	Errors  []ImportRowError `json:"errors"`
+}
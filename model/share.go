@@ -0,0 +1,25 @@
+package model
+
+// Permission 分享給其他使用者的權限
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"
+	PermissionWrite Permission = "write"
+)
+
+// Valid 回傳權限是否為已知的值
+func (p Permission) Valid() bool {
+	return p == PermissionRead || p == PermissionWrite
+}
+
+// Share represents a grant that lets another user access a task
+type Share struct {
+	UserID     string     `json:"user_id" yaml:"user_id" example:"user-456"`
+	Permission Permission `json:"permission" yaml:"permission" example:"read" enums:"read,write"`
+}
+
+// ShareRequest represents the request payload for sharing a task
+type ShareRequest struct {
+	Permission Permission `json:"permission" binding:"required" example:"read" enums:"read,write"`
+}
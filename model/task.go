@@ -1,34 +1,67 @@
 package model
 
+import "time"
+
 // Task represents a task item
 type Task struct {
-	ID     string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Name   string `json:"name" example:"Learn Go programming"`
-	Status int    `json:"status" example:"0" enums:"0,1"`
+	ID     string     `json:"id" yaml:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name   string     `json:"name" yaml:"name" example:"Learn Go programming"`
+	Status int        `json:"status" yaml:"status" example:"0" enums:"0,1"`
+	Due    *time.Time `json:"due,omitempty" yaml:"due,omitempty" example:"2026-10-19T09:00:00Z"`
+	// OwnerID 建立任務的使用者，由驗證後的呼叫者決定，不接受客戶端指定
+	OwnerID string `json:"owner_id,omitempty" yaml:"owner_id,omitempty" example:"user-123"`
+	// DeletedAt 移到回收桶的時間，只出現在 GET /tasks/trash 的結果
+	DeletedAt *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty" example:"2026-10-19T09:00:00Z"`
 }
 
 // TaskRequest represents the request payload for creating or updating a task
 type TaskRequest struct {
-	Name   string `json:"name" binding:"required" example:"Learn Go programming"`
-	Status int    `json:"status" binding:"required" example:"0" enums:"0,1"`
+	Name   string     `json:"name" binding:"required" maxLength:"200" example:"Learn Go programming"`
+	Status int        `json:"status" binding:"required" example:"0" enums:"0,1"`
+	Due    *time.Time `json:"due,omitempty" example:"2026-10-19T09:00:00Z"`
 }
 
-// ErrorResponse represents error response format
+// ErrorResponse represents an RFC 7807 problem details response (application/problem+json)
 type ErrorResponse struct {
-	Error string `json:"error" example:"Internal server error"`
-}
-
-// BadRequestResponse represents 400 Bad Request error response format
-type BadRequestResponse struct {
-	Error string `json:"error" example:"name is required"`
+	// Type identifies the problem kind; it is stable and safe to match on
+	Type string `json:"type" example:"https://task-api.etrex.tw/problems/task_not_found"`
+	// Code is the last segment of Type, for clients that prefer a short identifier
+	Code     string `json:"code" example:"task_not_found"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"task not found"`
+	Instance string `json:"instance,omitempty" example:"4f9c2d1e-8a7b-4c3d-9e2f-1a2b3c4d5e6f"`
+	// Errors lists the offending fields of a validation_failed problem
+	Errors []FieldError `json:"errors,omitempty"`
+	// Error repeats Detail for clients written against the old format; omitted when compatibility mode is off
+	Error string `json:"error,omitempty" example:"task not found"`
+	// RequestID is only present in compatibility mode; use Instance instead
+	RequestID string `json:"request_id,omitempty" example:"4f9c2d1e-8a7b-4c3d-9e2f-1a2b3c4d5e6f"`
 }
 
-// NotFoundResponse represents 404 Not Found error response format
-type NotFoundResponse struct {
-	Error string `json:"error" example:"task not found"`
+// FieldError describes one invalid field of a request
+type FieldError struct {
+	// Field is the JSON path of the offending field
+	Field   string `json:"field" example:"name"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"name is required"`
 }
 
 // MessageResponse represents success message response format
 type MessageResponse struct {
 	Message string `json:"message" example:"Operation completed successfully"`
-}
\ No newline at end of file
+}
+
+// ConfirmHeader 清空所有任務或還原快照時必須帶的確認 header，避免誤送的請求覆蓋資料
+// 清空時值需為 ConfirmDeleteAll，還原快照時為 ConfirmRestoreSnapshot
+const (
+	ConfirmHeader          = "X-Confirm"
+	ConfirmDeleteAll       = "delete-all-tasks"
+	ConfirmRestoreSnapshot = "restore-snapshot"
+)
+
+// RestoreResponse represents the result of restoring the tasks removed by DELETE /admin/tasks, or a snapshot
+type RestoreResponse struct {
+	Message  string `json:"message" example:"Deleted tasks restored successfully"`
+	Restored int    `json:"restored" example:"42"`
+}
@@ -0,0 +1,178 @@
+// Package problem 以 RFC 7807 problem details（application/problem+json）回傳錯誤
+// 每種錯誤有固定的 code，type 為 TypeBase 加上 code，客戶端應以 type 或 code 判斷錯誤種類，而不是 detail 文字
+package problem
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/model"
+)
+
+// ContentType problem details 的 media type
+const ContentType = "application/problem+json"
+
+// TypeBase type URI 的前綴，後接錯誤代碼
+const TypeBase = "https://task-api.etrex.tw/problems/"
+
+// 各套件共用的錯誤代碼；套件可定義更精確的代碼，例如 task_not_found
+const (
+	CodeValidationFailed     = "validation_failed"
+	CodeMalformedBody        = "malformed_body"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+	CodeTimeout              = "timeout"
+	CodeClientClosedRequest  = "client_closed_request"
+)
+
+// StatusClientClosedRequest 客戶端在回應前中斷連線（沿用 nginx 的 499），只會出現在日誌與指標
+const StatusClientClosedRequest = 499
+
+// compatKey 儲存相容模式設定的 gin context key
+const compatKey = "problem.compat"
+
+// Error 回傳給客戶端的錯誤
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	// Errors 驗證失敗的欄位，只用於 validation_failed
+	Errors []model.FieldError
+	// Err 造成錯誤的原因，記錄在請求日誌，不會回傳給客戶端
+	Err error
+}
+
+// New 建立錯誤
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Validation 建立 400 validation_failed 錯誤，detail 以分號串接所有欄位的訊息
+func Validation(fields ...model.FieldError) *Error {
+	e := New(http.StatusBadRequest, CodeValidationFailed, "request validation failed")
+	if len(fields) > 0 {
+		messages := make([]string, len(fields))
+		for i, f := range fields {
+			messages[i] = f.Message
+		}
+		e.Detail = strings.Join(messages, "; ")
+	}
+	e.Errors = fields
+	return e
+}
+
+// Unexpected 將非預期的錯誤轉為 problem，detail 為回傳給客戶端的訊息，原始錯誤只記錄在日誌
+// 請求被取消或逾時不是伺服器錯誤：客戶端中斷回傳 499，逾時回傳 503
+func Unexpected(err error, detail string) *Error {
+	var e *Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		e = New(StatusClientClosedRequest, CodeClientClosedRequest, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		e = New(http.StatusServiceUnavailable, CodeTimeout, err.Error())
+	default:
+		e = New(http.StatusInternalServerError, CodeInternal, detail)
+	}
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Options 錯誤回應的設定
+type Options struct {
+	// Compat 同時輸出舊格式的 error 與 request_id 欄位，讓尚未改用 problem details 的客戶端繼續運作
+	Compat bool
+}
+
+// Middleware 套用錯誤回應設定；handler 呼叫 c.Error 卻沒有寫出回應時補上 500
+// 未經過 Middleware 時（例如 handler 單元測試）預設為相容模式
+func Middleware(opts Options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(compatKey, opts.Compat)
+		c.Next()
+
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			write(c, New(http.StatusInternalServerError, CodeInternal, "internal server error"))
+		}
+	}
+}
+
+// NoRoute 未註冊路由的 404 回應
+func NoRoute(c *gin.Context) {
+	Abort(c, New(http.StatusNotFound, CodeNotFound, "route not found"))
+}
+
+// Abort 寫出錯誤回應並中止後續 handler
+// err 不是 *Error 時視為非預期錯誤，回傳 500 並將原因記錄到請求日誌
+func Abort(c *gin.Context, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = Unexpected(err, "internal server error")
+	}
+	if e.Err != nil {
+		c.Error(e.Err)
+	}
+	write(c, e)
+	c.Abort()
+}
+
+func write(c *gin.Context, e *Error) {
+	body := newBody(c, e)
+	c.Header("Content-Type", ContentType)
+	c.Render(e.Status, render.JSON{Data: body})
+}
+
+func newBody(c *gin.Context, e *Error) model.ErrorResponse {
+	body := model.ErrorResponse{
+		Type:     TypeBase + e.Code,
+		Code:     e.Code,
+		Title:    title(e.Status),
+		Status:   e.Status,
+		Detail:   e.Detail,
+		Instance: logging.RequestID(c),
+		Errors:   e.Errors,
+	}
+	if compat(c) {
+		body.Error = e.Detail
+		body.RequestID = body.Instance
+	}
+	return body
+}
+
+// title 回傳狀態碼的標準說明
+func title(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+func compat(c *gin.Context) bool {
+	v, ok := c.Get(compatKey)
+	if !ok {
+		return true
+	}
+	enabled, _ := v.(bool)
+	return enabled
+}
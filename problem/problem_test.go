@@ -0,0 +1,153 @@
+package problem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		compat         bool
+		err            error
+		expectedStatus int
+		expectedBody   string
+		expectedErrors []string
+	}{
+		{
+			name:           "相容模式",
+			compat:         true,
+			err:            New(http.StatusNotFound, "task_not_found", "task not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/task_not_found","code":"task_not_found","title":"Not Found","status":404,"detail":"task not found","instance":"req-1","error":"task not found","request_id":"req-1"}`,
+		},
+		{
+			name:           "關閉相容模式",
+			err:            New(http.StatusNotFound, "task_not_found", "task not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/task_not_found","code":"task_not_found","title":"Not Found","status":404,"detail":"task not found","instance":"req-1"}`,
+		},
+		{
+			name: "驗證失敗的欄位",
+			err: Validation(
+				model.FieldError{Field: "name", Code: "required", Message: "name is required"},
+				model.FieldError{Field: "status", Code: "enum", Message: "status must be 0 or 1"},
+			),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/validation_failed","code":"validation_failed","title":"Bad Request","status":400,"detail":"name is required; status must be 0 or 1","instance":"req-1","errors":[{"field":"name","code":"required","message":"name is required"},{"field":"status","code":"enum","message":"status must be 0 or 1"}]}`,
+		},
+		{
+			name:           "非預期的錯誤不回傳原因",
+			err:            errors.New("disk full"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/internal_error","code":"internal_error","title":"Internal Server Error","status":500,"detail":"internal server error","instance":"req-1"}`,
+			expectedErrors: []string{"disk full"},
+		},
+		{
+			name:           "客戶端中斷連線",
+			err:            Unexpected(context.Canceled, "failed to list tasks"),
+			expectedStatus: StatusClientClosedRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/client_closed_request","code":"client_closed_request","title":"Client Closed Request","status":499,"detail":"context canceled","instance":"req-1"}`,
+			expectedErrors: []string{"context canceled"},
+		},
+		{
+			name:           "逾時",
+			err:            Unexpected(context.DeadlineExceeded, "failed to list tasks"),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/timeout","code":"timeout","title":"Service Unavailable","status":503,"detail":"context deadline exceeded","instance":"req-1"}`,
+			expectedErrors: []string{"context deadline exceeded"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			router := gin.New()
+			router.Use(logging.Middleware(slog.New(slog.NewTextHandler(io.Discard, nil)), logging.MiddlewareOptions{}))
+			router.Use(func(c *gin.Context) {
+				c.Next()
+				errs = c.Errors.Errors()
+			})
+			router.Use(Middleware(Options{Compat: tt.compat}))
+			router.GET("/tasks", func(c *gin.Context) {
+				Abort(c, tt.err)
+			}, func(c *gin.Context) {
+				t.Error("handler after Abort should not run")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			req.Header.Set(logging.RequestIDHeader, "req-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedErrors, errs)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware(Options{}))
+	router.NoRoute(NoRoute)
+	router.GET("/silent", func(c *gin.Context) {
+		c.Error(errors.New("forgot to respond"))
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "未註冊的路由",
+			path:           "/no-such-route",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/not_found","code":"not_found","title":"Not Found","status":404,"detail":"route not found"}`,
+		},
+		{
+			name:           "記錄錯誤但沒有回應",
+			path:           "/silent",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/internal_error","code":"internal_error","title":"Internal Server Error","status":500,"detail":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("disk full")
+	err := Unexpected(cause, "failed to create task")
+
+	require.ErrorIs(t, err, cause)
+	assert.Equal(t, "internal_error: disk full", err.Error())
+	assert.Equal(t, "task_not_found: task not found", New(http.StatusNotFound, "task_not_found", "task not found").Error())
+}
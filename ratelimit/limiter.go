@@ -0,0 +1,270 @@
+// Package ratelimit 以 token bucket 限制每個呼叫者的請求速率，並提供每日建立任務的配額
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清除閒置 bucket 與過期每日計數的間隔
+const sweepInterval = time.Minute
+
+// ErrInvalidLimits 限制數值不合法
+var ErrInvalidLimits = errors.New("rates, bursts and quotas must not be negative, and burst must be at least 1 when rate is set")
+
+// Limits 一個呼叫者的限制，讀取（GET/HEAD/OPTIONS）與寫入分開計算
+// rate 為 0 表示不限制該類請求，DailyCreates 為 0 表示不限制每日建立數
+type Limits struct {
+	ReadRate     float64 `json:"read_rate" yaml:"read_rate" example:"50"`
+	ReadBurst    int     `json:"read_burst" yaml:"read_burst" example:"100"`
+	WriteRate    float64 `json:"write_rate" yaml:"write_rate" example:"10"`
+	WriteBurst   int     `json:"write_burst" yaml:"write_burst" example:"20"`
+	DailyCreates int     `json:"daily_creates" yaml:"daily_creates" example:"1000"`
+}
+
+// Validate 檢查數值是否合法
+func (l Limits) Validate() error {
+	if l.ReadRate < 0 || l.WriteRate < 0 || l.ReadBurst < 0 || l.WriteBurst < 0 || l.DailyCreates < 0 {
+		return ErrInvalidLimits
+	}
+	if (l.ReadRate > 0 && l.ReadBurst < 1) || (l.WriteRate > 0 && l.WriteBurst < 1) {
+		return ErrInvalidLimits
+	}
+	return nil
+}
+
+func (l Limits) budget(write bool) (float64, int) {
+	if write {
+		return l.WriteRate, l.WriteBurst
+	}
+	return l.ReadRate, l.ReadBurst
+}
+
+// Result 一次取用 token 的結果
+type Result struct {
+	Allowed bool
+	// Unlimited 此類請求沒有設定速率限制
+	Unlimited bool
+	Limit     int
+	Remaining int
+	// Reset bucket 補滿需要的時間
+	Reset time.Duration
+	// RetryAfter 被拒絕時，下一個 token 可用前需要等待的時間
+	RetryAfter time.Duration
+}
+
+// QuotaResult 一次預留每日建立配額的結果
+type QuotaResult struct {
+	Allowed   bool
+	Unlimited bool
+	Limit     int
+	Remaining int
+	// Reset 配額重置（UTC 午夜）前的時間
+	Reset time.Duration
+}
+
+type bucketKey struct {
+	client string
+	write  bool
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type dailyCount struct {
+	day   string
+	count int
+}
+
+// Limiter 保存所有呼叫者的 token bucket 與每日計數，限制可在執行中調整
+type Limiter struct {
+	mu        sync.Mutex
+	defaults  Limits
+	overrides map[string]Limits
+	buckets   map[bucketKey]*bucket
+	daily     map[string]*dailyCount
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// New 建立以 defaults 為預設限制的 Limiter
+func New(defaults Limits) (*Limiter, error) {
+	if err := defaults.Validate(); err != nil {
+		return nil, err
+	}
+	return &Limiter{
+		defaults:  defaults,
+		overrides: make(map[string]Limits),
+		buckets:   make(map[bucketKey]*bucket),
+		daily:     make(map[string]*dailyCount),
+		now:       time.Now,
+	}, nil
+}
+
+// Defaults 回傳預設限制
+func (l *Limiter) Defaults() Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.defaults
+}
+
+// SetDefaults 調整預設限制，立即套用到沒有個別設定的呼叫者
+func (l *Limiter) SetDefaults(limits Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaults = limits
+	return nil
+}
+
+// Overrides 回傳個別 API key 的限制
+func (l *Limiter) Overrides() map[string]Limits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overrides := make(map[string]Limits, len(l.overrides))
+	for id, limits := range l.overrides {
+		overrides[id] = limits
+	}
+	return overrides
+}
+
+// SetOverride 設定個別 API key（principal ID）的限制
+func (l *Limiter) SetOverride(id string, limits Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overrides[id] = limits
+	return nil
+}
+
+// RemoveOverride 移除個別設定，回傳是否存在
+func (l *Limiter) RemoveOverride(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, exists := l.overrides[id]
+	delete(l.overrides, id)
+	return exists
+}
+
+// limitsFor 回傳呼叫者適用的限制，呼叫前需持有鎖
+func (l *Limiter) limitsFor(keyID string) Limits {
+	if limits, ok := l.overrides[keyID]; ok && keyID != "" {
+		return limits
+	}
+	return l.defaults
+}
+
+// Allow 從 client 的讀取或寫入 bucket 取用一個 token
+// keyID 為 API key 或使用者 ID，用來套用個別限制；匿名請求傳入空字串
+func (l *Limiter) Allow(client, keyID string, write bool) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	rate, burst := l.limitsFor(keyID).budget(write)
+	if rate <= 0 {
+		return Result{Allowed: true, Unlimited: true}
+	}
+
+	key := bucketKey{client: client, write: write}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+
+	// 依經過時間補充 token，限制調降時不超過新的 burst
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := Result{Limit: burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsToDuration((float64(burst) - b.tokens) / rate)
+	return res
+}
+
+// ReserveCreates 預留 n 次今日（UTC）的任務建立配額，剩餘配額不足 n 次時不預留；建立失敗時需呼叫 ReleaseCreates 歸還
+func (l *Limiter) ReserveCreates(client, keyID string, n int) QuotaResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now().UTC()
+	quota := l.limitsFor(keyID).DailyCreates
+	if quota <= 0 {
+		return QuotaResult{Allowed: true, Unlimited: true}
+	}
+
+	day := now.Format(time.DateOnly)
+	d, ok := l.daily[client]
+	if !ok || d.day != day {
+		d = &dailyCount{day: day}
+		l.daily[client] = d
+	}
+
+	res := QuotaResult{Limit: quota, Reset: nextMidnight(now).Sub(now)}
+	if d.count+n <= quota {
+		d.count += n
+		res.Allowed = true
+	}
+	res.Remaining = quota - d.count
+	return res
+}
+
+// ReleaseCreates 歸還 ReserveCreates 預留的 n 次配額
+func (l *Limiter) ReleaseCreates(client string, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d, ok := l.daily[client]; ok && d.day == l.now().UTC().Format(time.DateOnly) {
+		d.count = max(d.count-n, 0)
+	}
+}
+
+// sweep 定期清除已補滿的 bucket 與前幾天的計數，避免大量 IP 讓記憶體持續成長，呼叫前需持有鎖
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		rate, burst := l.limitsFor(keyIDOf(key.client)).budget(key.write)
+		if rate <= 0 || b.tokens+now.Sub(b.last).Seconds()*rate >= float64(burst) {
+			delete(l.buckets, key)
+		}
+	}
+	today := now.UTC().Format(time.DateOnly)
+	for client, d := range l.daily {
+		if d.day != today {
+			delete(l.daily, client)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+func nextMidnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/problem"
+)
+
+const keyClientPrefix = "key:"
+
+// ClientKey 回傳請求的限流對象：通過驗證的呼叫者以 principal ID（API key ID 或 JWT subject）計算，否則以 client IP 計算
+// client IP 只採用 engine 信任的代理（SetTrustedProxies）送來的 X-Forwarded-For
+func ClientKey(c *gin.Context) (client, keyID string) {
+	if p := auth.PrincipalFrom(c); p != nil && p.ID != "" {
+		return keyClientPrefix + p.ID, p.ID
+	}
+	return "ip:" + c.ClientIP(), ""
+}
+
+// keyIDOf 從 client key 取回 principal ID，以 IP 計算的對象回傳空字串
+func keyIDOf(client string) string {
+	id, _ := strings.CutPrefix(client, keyClientPrefix)
+	if id == client {
+		return ""
+	}
+	return id
+}
+
+// createQuotaKey gin context 中保存呼叫者每日建立配額的 key
+const createQuotaKey = "ratelimit.create_quota"
+
+type createQuota struct {
+	limiter *Limiter
+	client  string
+	keyID   string
+}
+
+// Middleware 依讀取／寫入 budget 限制請求速率，並讓 handler 以 ReserveCreates 套用每日建立配額
+// 需放在 auth.Middleware 之後，才能以 API key 區分呼叫者
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, keyID := ClientKey(c)
+
+		res := l.Allow(client, keyID, isWrite(c.Request.Method))
+		if !res.Unlimited {
+			c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			c.Header("RateLimit-Reset", seconds(res.Reset))
+		}
+		if !res.Allowed {
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			problem.Abort(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited, "rate limit exceeded"))
+			return
+		}
+
+		c.Set(createQuotaKey, &createQuota{limiter: l, client: client, keyID: keyID})
+		c.Next()
+	}
+}
+
+// ReserveCreates 從呼叫者今日的建立配額預留 n 次，配額不足時回傳 429 並中止請求
+// 建立任務的 handler 在寫入前呼叫，配額因此計算的是建立的任務數而不是路由；寫入失敗時需以 ReleaseCreates 歸還
+// 沒有套用 Middleware 的請求不限制
+func ReserveCreates(c *gin.Context, n int) bool {
+	q, ok := c.Value(createQuotaKey).(*createQuota)
+	if !ok {
+		return true
+	}
+
+	quota := q.limiter.ReserveCreates(q.client, q.keyID, n)
+	if quota.Unlimited {
+		return true
+	}
+	c.Header("X-Daily-Quota-Limit", strconv.Itoa(quota.Limit))
+	c.Header("X-Daily-Quota-Remaining", strconv.Itoa(quota.Remaining))
+	if !quota.Allowed {
+		c.Header("Retry-After", seconds(quota.Reset))
+		problem.Abort(c, problem.New(http.StatusTooManyRequests, "daily_quota_exceeded", "daily task creation quota exceeded"))
+		return false
+	}
+	return true
+}
+
+// ReleaseCreates 歸還 ReserveCreates 預留的 n 次配額
+func ReleaseCreates(c *gin.Context, n int) {
+	if q, ok := c.Value(createQuotaKey).(*createQuota); ok && n > 0 {
+		q.limiter.ReleaseCreates(q.client, n)
+	}
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// seconds 以整數秒（無條件進位）表示 d，供 RateLimit-Reset 與 Retry-After 使用
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
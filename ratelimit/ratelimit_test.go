@@ -0,0 +1,235 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 可手動推進的時鐘
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func newTestLimiter(t *testing.T, limits Limits) (*Limiter, *fakeClock) {
+	t.Helper()
+	l, err := New(limits)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)}
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestLimits_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		wantErr bool
+	}{
+		{name: "全部為 0 表示不限制", limits: Limits{}},
+		{name: "合法的限制", limits: Limits{ReadRate: 1, ReadBurst: 1, WriteRate: 0.5, WriteBurst: 2, DailyCreates: 10}},
+		{name: "負的 rate", limits: Limits{ReadRate: -1, ReadBurst: 1}, wantErr: true},
+		{name: "負的配額", limits: Limits{DailyCreates: -1}, wantErr: true},
+		{name: "有 rate 但 burst 為 0", limits: Limits{WriteRate: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLimits)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, clock := newTestLimiter(t, Limits{ReadRate: 1, ReadBurst: 2, WriteRate: 0.5, WriteBurst: 1})
+
+	// burst 用完後拒絕
+	res := l.Allow("ip:1.2.3.4", "", false)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Limit)
+	assert.Equal(t, 1, res.Remaining)
+	res = l.Allow("ip:1.2.3.4", "", false)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	res = l.Allow("ip:1.2.3.4", "", false)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	// 讀取與寫入分開計算，不同 client 也互不影響
+	assert.True(t, l.Allow("ip:1.2.3.4", "", true).Allowed)
+	assert.False(t, l.Allow("ip:1.2.3.4", "", true).Allowed)
+	assert.True(t, l.Allow("ip:5.6.7.8", "", false).Allowed)
+
+	// 依經過時間補充
+	clock.Advance(time.Second)
+	assert.True(t, l.Allow("ip:1.2.3.4", "", false).Allowed)
+	assert.False(t, l.Allow("ip:1.2.3.4", "", false).Allowed)
+	assert.False(t, l.Allow("ip:1.2.3.4", "", true).Allowed)
+	clock.Advance(time.Second)
+	assert.True(t, l.Allow("ip:1.2.3.4", "", true).Allowed)
+}
+
+func TestLimiter_Overrides(t *testing.T) {
+	l, _ := newTestLimiter(t, Limits{ReadRate: 1, ReadBurst: 1})
+
+	require.NoError(t, l.SetOverride("key-1", Limits{ReadRate: 10, ReadBurst: 3}))
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("key:key-1", "key-1", false).Allowed)
+	}
+	assert.False(t, l.Allow("key:key-1", "key-1", false).Allowed)
+
+	// rate 為 0 表示不限制，不回傳 RateLimit 資訊
+	res := l.Allow("key:key-1", "key-1", true)
+	assert.True(t, res.Allowed)
+	assert.True(t, res.Unlimited)
+
+	// 移除後回到預設限制，bucket 依新的 burst 截斷
+	assert.True(t, l.RemoveOverride("key-1"))
+	assert.False(t, l.RemoveOverride("key-1"))
+	assert.False(t, l.Allow("key:key-1", "key-1", false).Allowed)
+
+	assert.ErrorIs(t, l.SetOverride("key-2", Limits{ReadRate: 1}), ErrInvalidLimits)
+	assert.ErrorIs(t, l.SetDefaults(Limits{WriteBurst: -1}), ErrInvalidLimits)
+	assert.Empty(t, l.Overrides())
+}
+
+func TestLimiter_DailyCreates(t *testing.T) {
+	l, clock := newTestLimiter(t, Limits{DailyCreates: 2})
+
+	res := l.ReserveCreates("ip:1.2.3.4", "", 1)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	assert.Equal(t, 15*time.Hour, res.Reset)
+
+	// 歸還的配額可再使用
+	l.ReleaseCreates("ip:1.2.3.4", 1)
+	// 剩餘配額不足時整批都不預留
+	res = l.ReserveCreates("ip:1.2.3.4", "", 3)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+	assert.True(t, l.ReserveCreates("ip:1.2.3.4", "", 2).Allowed)
+	res = l.ReserveCreates("ip:1.2.3.4", "", 1)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	// UTC 午夜重置
+	clock.Advance(15 * time.Hour)
+	assert.True(t, l.ReserveCreates("ip:1.2.3.4", "", 1).Allowed)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l, clock := newTestLimiter(t, Limits{ReadRate: 1, ReadBurst: 5, DailyCreates: 1})
+
+	l.Allow("ip:1.2.3.4", "", false)
+	l.ReserveCreates("ip:1.2.3.4", "", 1)
+	require.Len(t, l.buckets, 1)
+
+	// 閒置到補滿後清除，計數跨日後清除
+	clock.Advance(24 * time.Hour)
+	l.Allow("ip:5.6.7.8", "", false)
+	assert.Len(t, l.buckets, 1)
+	assert.Empty(t, l.daily)
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	l, clock := newTestLimiter(t, Limits{ReadRate: 1, ReadBurst: 2, WriteRate: 1, WriteBurst: 5, DailyCreates: 2})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-User"); id != "" {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), &auth.Principal{ID: id}))
+		}
+		c.Next()
+	})
+	router.Use(l.Middleware())
+	router.GET("/tasks", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	router.POST("/tasks", func(c *gin.Context) {
+		if !ReserveCreates(c, 1) {
+			return
+		}
+		if c.Query("fail") != "" {
+			ReleaseCreates(c, 1)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	do := func(method, target, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("超過讀取 budget 回傳 429", func(t *testing.T) {
+		w := do(http.MethodGet, "/tasks", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+
+		do(http.MethodGet, "/tasks", "")
+		w = do(http.MethodGet, "/tasks", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/rate_limited","code":"rate_limited","title":"Too Many Requests","status":429,"detail":"rate limit exceeded","error":"rate limit exceeded"}`, w.Body.String())
+
+		// 已驗證的呼叫者與同 IP 的匿名請求分開計算
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/tasks", "key-1").Code)
+	})
+
+	t.Run("每日建立配額只計算成功的建立", func(t *testing.T) {
+		w := do(http.MethodPost, "/tasks?fail=1", "key-2")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Daily-Quota-Remaining"))
+
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/tasks", "key-2").Code)
+		w = do(http.MethodPost, "/tasks", "key-2")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-Daily-Quota-Limit"))
+		assert.Equal(t, "0", w.Header().Get("X-Daily-Quota-Remaining"))
+
+		w = do(http.MethodPost, "/tasks", "key-2")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "54000", w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/daily_quota_exceeded","code":"daily_quota_exceeded","title":"Too Many Requests","status":429,"detail":"daily task creation quota exceeded","error":"daily task creation quota exceeded"}`, w.Body.String())
+
+		clock.Advance(15 * time.Hour)
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/tasks", "key-2").Code)
+	})
+}
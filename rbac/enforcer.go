@@ -0,0 +1,213 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/problem"
+)
+
+// Relation 呼叫者與請求資源的關係
+type Relation int
+
+const (
+	RelationNone Relation = iota
+	RelationShared
+	RelationOwner
+)
+
+// RelationFunc 解析呼叫者與請求資源（例如 /tasks/:id 的任務）的關係
+// 請求沒有對應的資源或資源不存在時 ok 為 false，此時帶條件的權限視為符合，交由 handler 回傳 404
+type RelationFunc func(c *gin.Context, principal *auth.Principal) (rel Relation, ok bool)
+
+// Decision 一次授權判斷的結果
+type Decision struct {
+	Allowed bool
+	Action  string
+	Roles   []string
+	// Rule 決定結果的規則，例如 "member: tasks:delete:own"
+	Rule string
+}
+
+// Enforcer 依目前的政策判斷請求是否允許，政策可在執行中替換
+type Enforcer struct {
+	policy atomic.Pointer[Policy]
+
+	// 從檔案載入時用於重新載入
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+}
+
+// NewEnforcer 以固定的政策建立 Enforcer
+func NewEnforcer(p *Policy) *Enforcer {
+	e := &Enforcer{}
+	e.policy.Store(p)
+	return e
+}
+
+// NewFileEnforcer 從政策檔建立 Enforcer，之後可呼叫 Reload 或 Watch 重新載入
+func NewFileEnforcer(path string) (*Enforcer, error) {
+	e := &Enforcer{path: path}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Policy 回傳目前使用的政策
+func (e *Enforcer) Policy() *Policy {
+	return e.policy.Load()
+}
+
+// SetPolicy 替換目前使用的政策
+func (e *Enforcer) SetPolicy(p *Policy) {
+	e.policy.Store(p)
+}
+
+// Reload 重新讀取政策檔；解析失敗時保留原本的政策
+func (e *Enforcer) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return err
+	}
+	p, err := LoadFile(e.path)
+	if err != nil {
+		return err
+	}
+	e.policy.Store(p)
+	e.modTime = info.ModTime()
+	return nil
+}
+
+// Watch 每隔 interval 檢查政策檔的修改時間，有變更就重新載入，直到 ctx 結束
+func (e *Enforcer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			logger.Error("rbac: cannot stat policy file", "path", e.path, "error", err)
+			continue
+		}
+		e.mu.Lock()
+		changed := !info.ModTime().Equal(e.modTime)
+		e.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		if err := e.Reload(); err != nil {
+			logger.Error("rbac: failed to reload policy, keeping the previous one", "path", e.path, "error", err)
+			continue
+		}
+		logger.Info("rbac: reloaded policy", "path", e.path)
+	}
+}
+
+// Decide 判斷 principal 是否可以執行目前請求的路由
+// relation 可為 nil，此時帶條件的權限一律視為符合
+func (e *Enforcer) Decide(c *gin.Context, principal *auth.Principal, relation RelationFunc) Decision {
+	policy := e.Policy()
+	route := c.Request.Method + " " + c.FullPath()
+
+	action, ok := policy.Routes[route]
+	if !ok {
+		return Decision{Rule: "no route rule for " + route}
+	}
+
+	d := Decision{Action: action, Roles: policy.RolesFor(principal)}
+
+	var (
+		resolved bool
+		rel      Relation
+		found    bool
+		// 最後一條動作相符但條件不符的規則，拒絕時記錄
+		conditional string
+	)
+	for _, role := range d.Roles {
+		for _, perm := range policy.Roles[role] {
+			if !perm.Matches(action) {
+				continue
+			}
+			rule := role + ": " + perm.String()
+			if perm.Condition == "" {
+				d.Allowed, d.Rule = true, rule
+				return d
+			}
+
+			if !resolved {
+				if relation != nil {
+					rel, found = relation(c, principal)
+				}
+				resolved = true
+			}
+			if !found || satisfies(rel, perm.Condition) {
+				d.Allowed, d.Rule = true, rule
+				return d
+			}
+			conditional = rule
+		}
+	}
+
+	d.Rule = conditional
+	if d.Rule == "" {
+		d.Rule = "no role grants " + action
+	}
+	return d
+}
+
+func satisfies(rel Relation, condition string) bool {
+	switch condition {
+	case ConditionOwn:
+		return rel == RelationOwner
+	case ConditionShared:
+		return rel >= RelationShared
+	}
+	return false
+}
+
+// Middleware 依政策授權請求，需放在 auth.Middleware 之後
+// 拒絕時回傳 403 並記錄決定結果的規則
+func (e *Enforcer) Middleware(relation RelationFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c)
+		if principal == nil {
+			c.Header("WWW-Authenticate", `Bearer realm="task-api"`)
+			problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "authentication required"))
+			return
+		}
+
+		d := e.Decide(c, principal, relation)
+		if !d.Allowed {
+			logging.FromContext(c.Request.Context()).Warn("rbac: permission denied",
+				"principal", principal.ID,
+				"source", principal.Source,
+				"roles", d.Roles,
+				"action", d.Action,
+				"rule", d.Rule,
+			)
+			problem.Abort(c, problem.New(http.StatusForbidden, "permission_denied", "permission denied"))
+			return
+		}
+		c.Next()
+	}
+}
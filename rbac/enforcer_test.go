@@ -0,0 +1,186 @@
+package rbac
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRouter 以 X-User / X-Scope 標頭模擬已驗證的呼叫者，task-1 屬於 alice 並分享給 bob
+func testRouter(e *Enforcer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	relation := func(c *gin.Context, p *auth.Principal) (Relation, bool) {
+		switch {
+		case c.Param("id") != "task-1":
+			return RelationNone, false
+		case p.ID == "alice":
+			return RelationOwner, true
+		case p.ID == "bob":
+			return RelationShared, true
+		}
+		return RelationNone, true
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			p := &auth.Principal{ID: user, Scope: auth.Scope(c.GetHeader("X-Scope")), Source: "api_key"}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+		c.Next()
+	}, e.Middleware(relation))
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/tasks", ok)
+	router.POST("/tasks", ok)
+	router.PUT("/tasks/:id", ok)
+	router.DELETE("/tasks/:id", ok)
+	router.DELETE("/admin/tasks", ok)
+	router.GET("/unlisted", ok)
+	return router
+}
+
+func TestEnforcer_Middleware(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	router := testRouter(NewEnforcer(DefaultPolicy()))
+
+	tests := []struct {
+		name           string
+		user           string
+		scope          auth.Scope
+		method         string
+		path           string
+		expectedStatus int
+		expectedLog    string
+	}{
+		{name: "未驗證", method: http.MethodGet, path: "/tasks", expectedStatus: http.StatusUnauthorized},
+		{name: "viewer 可以列出", user: "carol", scope: auth.ScopeRead, method: http.MethodGet, path: "/tasks", expectedStatus: http.StatusOK},
+		{
+			name: "viewer 不能建立", user: "carol", scope: auth.ScopeRead, method: http.MethodPost, path: "/tasks",
+			expectedStatus: http.StatusForbidden, expectedLog: `action=tasks:create rule="no role grants tasks:create"`,
+		},
+		{name: "member 可以建立", user: "carol", scope: auth.ScopeWrite, method: http.MethodPost, path: "/tasks", expectedStatus: http.StatusOK},
+		{name: "擁有者可以修改", user: "alice", scope: auth.ScopeWrite, method: http.MethodPut, path: "/tasks/task-1", expectedStatus: http.StatusOK},
+		{name: "被分享者可以修改", user: "bob", scope: auth.ScopeWrite, method: http.MethodPut, path: "/tasks/task-1", expectedStatus: http.StatusOK},
+		{
+			name: "被分享者不能刪除", user: "bob", scope: auth.ScopeWrite, method: http.MethodDelete, path: "/tasks/task-1",
+			expectedStatus: http.StatusForbidden, expectedLog: `rule="member: tasks:delete:own"`,
+		},
+		{
+			name: "其他人不能修改", user: "carol", scope: auth.ScopeWrite, method: http.MethodPut, path: "/tasks/task-1",
+			expectedStatus: http.StatusForbidden, expectedLog: `rule="member: tasks:update:shared"`,
+		},
+		{name: "不存在的任務交給 handler", user: "carol", scope: auth.ScopeWrite, method: http.MethodDelete, path: "/tasks/missing", expectedStatus: http.StatusOK},
+		{
+			name: "member 不能刪除全部", user: "alice", scope: auth.ScopeWrite, method: http.MethodDelete, path: "/admin/tasks",
+			expectedStatus: http.StatusForbidden, expectedLog: "permission denied principal=alice source=api_key roles=[member]",
+		},
+		{name: "admin 可以刪除全部", user: "root", scope: auth.ScopeAdmin, method: http.MethodDelete, path: "/admin/tasks", expectedStatus: http.StatusOK},
+		{
+			name: "未列出的路由一律拒絕", user: "root", scope: auth.ScopeAdmin, method: http.MethodGet, path: "/unlisted",
+			expectedStatus: http.StatusForbidden, expectedLog: `rule="no route rule for GET /unlisted"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.user != "" {
+				req.Header.Set("X-User", tt.user)
+				req.Header.Set("X-Scope", string(tt.scope))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"type":"https://task-api.etrex.tw/problems/permission_denied","code":"permission_denied","title":"Forbidden","status":403,"detail":"permission denied","error":"permission denied"}`, w.Body.String())
+			}
+			if tt.expectedLog != "" {
+				assert.Contains(t, logs.String(), tt.expectedLog)
+			} else {
+				assert.Empty(t, logs.String())
+			}
+		})
+	}
+}
+
+const viewerOnlyPolicy = `
+routes:
+  GET /tasks: tasks:list
+  POST /tasks: tasks:create
+roles:
+  viewer:
+    allow: [tasks:list]
+bindings:
+  scopes:
+    write: [viewer]
+`
+
+const memberPolicy = `
+routes:
+  GET /tasks: tasks:list
+  POST /tasks: tasks:create
+roles:
+  member:
+    allow: ["tasks:*"]
+bindings:
+  scopes:
+    write: [member]
+`
+
+func TestEnforcer_Reload(t *testing.T) {
+	log.SetOutput(&bytes.Buffer{})
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(viewerOnlyPolicy), 0o600))
+
+	e, err := NewFileEnforcer(path)
+	require.NoError(t, err)
+	router := testRouter(e)
+
+	create := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+		req.Header.Set("X-User", "alice")
+		req.Header.Set("X-Scope", string(auth.ScopeWrite))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, create())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Watch(ctx, 10*time.Millisecond)
+
+	// 修改政策檔後不需重新啟動即生效
+	writePolicy := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	writePolicy(memberPolicy, time.Now().Add(time.Minute))
+	assert.Eventually(t, func() bool { return create() == http.StatusOK }, time.Second, 10*time.Millisecond)
+
+	// 不合法的政策檔不會取代目前的政策
+	writePolicy("roles:\n  a:\n    inherits: [missing]\n", time.Now().Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, create())
+	assert.Error(t, e.Reload())
+}
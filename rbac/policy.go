@@ -0,0 +1,219 @@
+// Package rbac 依角色與政策檔控制每個路由的存取權限
+package rbac
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gogolook/task-api/auth"
+	"gopkg.in/yaml.v3"
+)
+
+// 權限上的資源條件
+const (
+	// ConditionOwn 只有任務擁有者符合
+	ConditionOwn = "own"
+	// ConditionShared 任務擁有者與被分享的使用者都符合
+	ConditionShared = "shared"
+)
+
+//go:embed default_policy.yaml
+var defaultPolicy []byte
+
+// Policy 解析後的存取控制政策
+type Policy struct {
+	// Routes "METHOD /full/path" -> action
+	Routes map[string]string
+	// Roles 角色 -> 已展開繼承的權限
+	Roles map[string][]Permission
+	// ScopeRoles scope -> 角色
+	ScopeRoles map[auth.Scope][]string
+	// UserRoles 使用者 ID -> 額外的角色
+	UserRoles map[string][]string
+}
+
+// Permission 角色允許的一個動作，例如 "tasks:update:own"
+type Permission struct {
+	// Action 動作，可以是 "*" 或以 ":*" 結尾的前綴
+	Action string
+	// Condition 資源條件，空字串表示不限
+	Condition string
+}
+
+func (p Permission) String() string {
+	if p.Condition == "" {
+		return p.Action
+	}
+	return p.Action + ":" + p.Condition
+}
+
+// Matches 回傳此權限是否涵蓋 action（不考慮條件）
+func (p Permission) Matches(action string) bool {
+	if p.Action == "*" || p.Action == action {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(p.Action, "*"); ok {
+		return strings.HasPrefix(action, prefix)
+	}
+	return false
+}
+
+// policyFile 政策檔的格式
+type policyFile struct {
+	Routes map[string]string `yaml:"routes"`
+	Roles  map[string]struct {
+		Inherits []string `yaml:"inherits"`
+		Allow    []string `yaml:"allow"`
+	} `yaml:"roles"`
+	Bindings struct {
+		Scopes map[string][]string `yaml:"scopes"`
+		Users  map[string][]string `yaml:"users"`
+	} `yaml:"bindings"`
+}
+
+// DefaultPolicy 回傳內建的政策，行為與 API key scope 相同
+func DefaultPolicy() *Policy {
+	p, err := Parse(defaultPolicy)
+	if err != nil {
+		panic("rbac: invalid default policy: " + err.Error())
+	}
+	return p
+}
+
+// LoadFile 讀取並解析政策檔
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse 解析 YAML 格式的政策，並檢查角色繼承與綁定是否正確
+func Parse(data []byte) (*Policy, error) {
+	var file policyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	p := &Policy{
+		Routes:     make(map[string]string, len(file.Routes)),
+		Roles:      make(map[string][]Permission, len(file.Roles)),
+		ScopeRoles: make(map[auth.Scope][]string),
+		UserRoles:  make(map[string][]string),
+	}
+
+	for route, action := range file.Routes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route %q: must be \"METHOD /path\"", route)
+		}
+		if action == "" {
+			return nil, fmt.Errorf("route %q has no action", route)
+		}
+		p.Routes[strings.ToUpper(method)+" "+path] = action
+	}
+
+	// 展開角色繼承，偵測循環
+	var expand func(name string, visiting map[string]bool) ([]Permission, error)
+	expand = func(name string, visiting map[string]bool) ([]Permission, error) {
+		if perms, done := p.Roles[name]; done {
+			return perms, nil
+		}
+		role, exists := file.Roles[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown role %q", name)
+		}
+		if visiting[name] {
+			return nil, fmt.Errorf("role %q inherits itself", name)
+		}
+		visiting[name] = true
+
+		var perms []Permission
+		for _, parent := range role.Inherits {
+			inherited, err := expand(parent, visiting)
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, inherited...)
+		}
+		for _, allow := range role.Allow {
+			perm, err := parsePermission(allow)
+			if err != nil {
+				return nil, fmt.Errorf("role %q: %w", name, err)
+			}
+			perms = append(perms, perm)
+		}
+
+		delete(visiting, name)
+		p.Roles[name] = perms
+		return perms, nil
+	}
+	names := make([]string, 0, len(file.Roles))
+	for name := range file.Roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := expand(name, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	for scope, roles := range file.Bindings.Scopes {
+		if !auth.Scope(scope).Valid() {
+			return nil, fmt.Errorf("binding: %w", auth.ErrInvalidScope)
+		}
+		if err := p.checkRoles(roles); err != nil {
+			return nil, fmt.Errorf("binding for scope %q: %w", scope, err)
+		}
+		p.ScopeRoles[auth.Scope(scope)] = roles
+	}
+	for user, roles := range file.Bindings.Users {
+		if err := p.checkRoles(roles); err != nil {
+			return nil, fmt.Errorf("binding for user %q: %w", user, err)
+		}
+		p.UserRoles[user] = roles
+	}
+	return p, nil
+}
+
+func (p *Policy) checkRoles(roles []string) error {
+	for _, role := range roles {
+		if _, exists := p.Roles[role]; !exists {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+// parsePermission 解析 "resource:action[:condition]"
+func parsePermission(s string) (Permission, error) {
+	if s == "" {
+		return Permission{}, errors.New("empty permission")
+	}
+	if s == "*" {
+		return Permission{Action: s}, nil
+	}
+	if i := strings.LastIndex(s, ":"); i > 0 {
+		switch s[i+1:] {
+		case ConditionOwn, ConditionShared:
+			return Permission{Action: s[:i], Condition: s[i+1:]}, nil
+		}
+	}
+	return Permission{Action: s}, nil
+}
+
+// RolesFor 回傳呼叫者擁有的角色（scope 綁定加上使用者綁定）
+func (p *Policy) RolesFor(principal *auth.Principal) []string {
+	roles := append([]string{}, p.ScopeRoles[principal.Scope]...)
+	return append(roles, p.UserRoles[principal.ID]...)
+}
@@ -0,0 +1,97 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/gogolook/task-api/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+
+	assert.Equal(t, "tasks:delete_all", p.Routes["DELETE /admin/tasks"])
+	assert.Equal(t, []string{"member"}, p.RolesFor(&auth.Principal{ID: "u1", Scope: auth.ScopeWrite}))
+
+	// member 繼承 viewer 的權限
+	assert.Contains(t, p.Roles["member"], Permission{Action: "tasks:list"})
+	assert.Contains(t, p.Roles["member"], Permission{Action: "tasks:delete", Condition: ConditionOwn})
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(`
+routes:
+  get /tasks: tasks:list
+roles:
+  auditor:
+    allow: ["tasks:*"]
+bindings:
+  scopes:
+    read: [auditor]
+  users:
+    alice: [auditor]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "tasks:list", p.Routes["GET /tasks"])
+	assert.Equal(t, []string{"auditor", "auditor"}, p.RolesFor(&auth.Principal{ID: "alice", Scope: auth.ScopeRead}))
+	assert.Empty(t, p.RolesFor(&auth.Principal{ID: "bob", Scope: auth.ScopeAdmin}))
+
+	tests := []struct {
+		name          string
+		policy        string
+		expectedError string
+	}{
+		{
+			name:          "不合法的 YAML",
+			policy:        "routes: [",
+			expectedError: "invalid policy",
+		},
+		{
+			name:          "不合法的路由",
+			policy:        "routes:\n  /tasks: tasks:list\n",
+			expectedError: `invalid route "/tasks"`,
+		},
+		{
+			name:          "繼承不存在的角色",
+			policy:        "roles:\n  member:\n    inherits: [viewer]\n",
+			expectedError: `unknown role "viewer"`,
+		},
+		{
+			name:          "循環繼承",
+			policy:        "roles:\n  a:\n    inherits: [b]\n  b:\n    inherits: [a]\n",
+			expectedError: "inherits itself",
+		},
+		{
+			name:          "綁定不存在的角色",
+			policy:        "bindings:\n  users:\n    alice: [root]\n",
+			expectedError: `binding for user "alice": unknown role "root"`,
+		},
+		{
+			name:          "不合法的 scope",
+			policy:        "bindings:\n  scopes:\n    owner: []\n",
+			expectedError: "scope must be read, write or admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.policy))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestPermission_Matches(t *testing.T) {
+	assert.True(t, Permission{Action: "*"}.Matches("keys:issue"))
+	assert.True(t, Permission{Action: "tasks:*"}.Matches("tasks:delete_all"))
+	assert.False(t, Permission{Action: "tasks:*"}.Matches("keys:issue"))
+	assert.True(t, Permission{Action: "tasks:read"}.Matches("tasks:read"))
+	assert.False(t, Permission{Action: "tasks:read"}.Matches("tasks:readme"))
+
+	perm, err := parsePermission("tasks:update:own")
+	require.NoError(t, err)
+	assert.Equal(t, Permission{Action: "tasks:update", Condition: ConditionOwn}, perm)
+	assert.Equal(t, "tasks:update:own", perm.String())
+}
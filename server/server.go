@@ -0,0 +1,122 @@
+// Package server 管理 HTTP 服務的生命週期：收到關閉訊號後停止接受新連線、等待進行中的請求完成，再釋放資源
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogolook/task-api/logging"
+)
+
+// Options 關閉流程的設定
+type Options struct {
+	// ShutdownTimeout 等待進行中請求完成的上限，超過時強制關閉剩餘連線
+	ShutdownTimeout time.Duration
+	// ShutdownDelay 收到訊號後先讓 readiness 失敗、繼續服務的時間，讓負載平衡器停止導入新流量
+	ShutdownDelay time.Duration
+}
+
+// Server 包裝 http.Server，提供可由 context 觸發的優雅關閉
+type Server struct {
+	http *http.Server
+	opts Options
+
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks []func() error
+}
+
+// New 建立在 addr 上服務 handler 的 Server
+func New(addr string, handler http.Handler, opts Options) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		opts: opts,
+	}
+}
+
+// OnShutdown 註冊在所有請求處理完後執行的關閉動作（例如關閉 storage），依註冊順序執行
+func (s *Server) OnShutdown(fn func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// Draining 回傳是否已進入關閉流程，readiness 檢查應在此時回報失敗
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// ListenAndServe 監聽 Addr 並服務到 ctx 結束，之後執行優雅關閉
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve 在 ln 上服務到 ctx 結束，之後依序：
+// 讓 readiness 失敗並等待 ShutdownDelay、停止接受新連線並等待進行中的請求（最多 ShutdownTimeout）、執行 OnShutdown 註冊的動作
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.http.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// 尚未收到關閉訊號就停止，通常是 listener 出錯
+		return err
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	logger := logging.FromContext(ctx)
+	logger.Info("server: shutting down, draining in-flight requests", "timeout", s.opts.ShutdownTimeout.String())
+	if s.opts.ShutdownDelay > 0 {
+		time.Sleep(s.opts.ShutdownDelay)
+	}
+
+	shutdownCtx := context.Background()
+	if s.opts.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, s.opts.ShutdownTimeout)
+		defer cancel()
+	}
+
+	var errs []error
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		// 逾時仍未完成的請求直接中斷
+		s.http.Close()
+		errs = append(errs, fmt.Errorf("drain requests: %w", err))
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	// 即使排空逾時也要執行關閉動作，讓 storage 有機會寫回資料
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		logger.Info("server: shutdown complete")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer 在隨機 port 啟動 Server，回傳 base URL 與 Serve 的結果
+func startServer(t *testing.T, ctx context.Context, s *Server) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, ln)
+	}()
+	return "http://" + ln.Addr().String(), done
+}
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	s := New("", handler, Options{ShutdownTimeout: 5 * time.Second})
+	var closed atomic.Bool
+	s.OnShutdown(func() error {
+		closed.Store(true)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServer(t, ctx, s)
+
+	type result struct {
+		body string
+		err  error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	// 收到關閉訊號：readiness 失敗、不再接受新連線，但進行中的請求繼續處理
+	cancel()
+	require.Eventually(t, s.Draining, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 100 * time.Millisecond}
+		_, err := client.Get(url + "/new")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, closed.Load(), "storage must not be closed while requests are in flight")
+
+	close(release)
+	res := <-inFlight
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+
+	require.NoError(t, <-done)
+	assert.True(t, closed.Load())
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	s := New("", handler, Options{ShutdownTimeout: 50 * time.Millisecond})
+	var closed atomic.Bool
+	s.OnShutdown(func() error {
+		closed.Store(true)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServer(t, ctx, s)
+	go http.Get(url + "/stuck")
+	<-started
+
+	cancel()
+	select {
+	case err := <-done:
+		// 逾時仍回報錯誤，但關閉動作照樣執行
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, closed.Load())
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve did not return after the shutdown timeout")
+	}
+}
+
+func TestServer_ShutdownDelay(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	s := New("", handler, Options{ShutdownDelay: 200 * time.Millisecond, ShutdownTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServer(t, ctx, s)
+	cancel()
+	require.Eventually(t, s.Draining, time.Second, 5*time.Millisecond)
+
+	// 延遲期間仍接受新請求，讓負載平衡器有時間看到 readiness 失敗
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, <-done)
+}
+
+func TestServer_ShutdownHookError(t *testing.T) {
+	s := New("", http.NotFoundHandler(), Options{ShutdownTimeout: time.Second})
+	s.OnShutdown(func() error { return io.ErrShortWrite })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, done := startServer(t, ctx, s)
+	cancel()
+
+	assert.ErrorIs(t, <-done, io.ErrShortWrite)
+}
@@ -0,0 +1,122 @@
+// Package snapshot 以有版本與 checksum 的檔案格式保存 storage 快照
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gogolook/task-api/storage"
+)
+
+const (
+	// FormatName 標頭中的格式名稱，用來辨識快照檔案
+	FormatName = "task-api-snapshot"
+	// Version 目前寫入的格式版本
+	Version = 1
+	// MIMEType 下載快照時使用的 Content-Type
+	MIMEType = "application/vnd.task-api.snapshot"
+	// Extension 快照檔案的副檔名
+	Extension = ".snapshot"
+
+	checksumPrefix = "sha256:"
+)
+
+var (
+	ErrInvalidFormat      = errors.New("not a task-api snapshot")
+	ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+	ErrChecksumMismatch   = errors.New("snapshot checksum mismatch")
+)
+
+// Header 快照檔案的標頭
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Tasks     int       `json:"tasks"`
+	// Checksum 第二行資料（不含換行）的 SHA-256，格式為 "sha256:<hex>"
+	Checksum string `json:"checksum"`
+}
+
+// Encode 將快照寫入 w，回傳寫入的標頭
+// 檔案由兩行 JSON 組成：第一行是標頭，第二行是資料；列出快照時只需要讀取標頭
+func Encode(w io.Writer, snap *storage.Snapshot) (*Header, error) {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	header := &Header{
+		Format:    FormatName,
+		Version:   Version,
+		CreatedAt: snap.CreatedAt,
+		Tasks:     snap.Count(),
+		Checksum:  checksumPrefix + hex.EncodeToString(sum[:]),
+	}
+
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range [][]byte{line, {'\n'}, data, {'\n'}} {
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// Decode 讀取並驗證快照檔案
+func Decode(r io.Reader) (*storage.Snapshot, *Header, error) {
+	br := bufio.NewReader(r)
+	header, err := readHeader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	sum := sha256.Sum256(data)
+	if header.Checksum != checksumPrefix+hex.EncodeToString(sum[:]) {
+		return nil, nil, ErrChecksumMismatch
+	}
+
+	var snap storage.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	if snap.Count() != header.Tasks {
+		return nil, nil, fmt.Errorf("%w: header lists %d tasks, data has %d", ErrInvalidFormat, header.Tasks, snap.Count())
+	}
+	return &snap, header, nil
+}
+
+// ReadHeader 只讀取快照檔案的標頭，不驗證資料
+func ReadHeader(r io.Reader) (*Header, error) {
+	return readHeader(bufio.NewReader(r))
+}
+
+func readHeader(br *bufio.Reader) (*Header, error) {
+	line, err := br.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil || header.Format != FormatName {
+		return nil, ErrInvalidFormat
+	}
+	if header.Version != Version {
+		return nil, fmt.Errorf("%w: %d (supported: %d)", ErrUnsupportedVersion, header.Version, Version)
+	}
+	return &header, nil
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSnapshot() *storage.Snapshot {
+	deletedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	return &storage.Snapshot{
+		CreatedAt: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		Partitions: []storage.Partition{{
+			Tasks: []storage.SnapshotTask{
+				{Task: model.Task{ID: "1", Name: "Task 1", OwnerID: "alice"}, Shares: []model.Share{{UserID: "bob", Permission: model.PermissionRead}}},
+				{Task: model.Task{ID: "2", Name: "Task 2", Status: 1, DeletedAt: &deletedAt}},
+			},
+		}},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	var buf bytes.Buffer
+	header, err := Encode(&buf, testSnapshot())
+	require.NoError(t, err)
+	assert.Equal(t, FormatName, header.Format)
+	assert.Equal(t, Version, header.Version)
+	assert.Equal(t, 2, header.Tasks)
+	assert.True(t, strings.HasPrefix(header.Checksum, "sha256:"))
+
+	// 第一行是標頭，第二行是資料
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `{"format":"task-api-snapshot","version":1,`))
+
+	readHeader, err := ReadHeader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, header, readHeader)
+
+	snap, decodedHeader, err := Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot(), snap)
+	assert.Equal(t, header, decodedHeader)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := Encode(&buf, testSnapshot())
+	require.NoError(t, err)
+	valid := buf.String()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{name: "空檔案", data: "", wantErr: ErrInvalidFormat},
+		{name: "不是快照檔案", data: "id,name,status\n", wantErr: ErrInvalidFormat},
+		{name: "不支援的版本", data: strings.Replace(valid, `"version":1`, `"version":2`, 1), wantErr: ErrUnsupportedVersion},
+		{name: "資料被修改", data: strings.Replace(valid, "Task 1", "Task X", 1), wantErr: ErrChecksumMismatch},
+		{name: "資料被截斷", data: valid[:len(valid)-10], wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(strings.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package snapshot
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogolook/task-api/storage"
+)
+
+// ErrNotFound 指定的快照不存在
+var ErrNotFound = errors.New("snapshot not found")
+
+// idPattern 快照 ID 為建立時間加上 checksum 的前 8 碼，依字串排序即為時間順序
+var idPattern = regexp.MustCompile(`^\d{8}T\d{6}Z-[0-9a-f]{8}$`)
+
+// Info 已保存的快照
+type Info struct {
+	ID        string    `json:"id" example:"20261019T090000Z-1a2b3c4d"`
+	CreatedAt time.Time `json:"created_at" example:"2026-10-19T09:00:00Z"`
+	// Tasks 任務數，含回收桶中的任務
+	Tasks int `json:"tasks" example:"150"`
+	// Size 檔案大小（位元組）
+	Size     int64  `json:"size" example:"24576"`
+	Checksum string `json:"checksum" example:"sha256:1a2b3c4d..."`
+}
+
+// Store 將快照保存在目錄中，只保留最新的 keep 份；keep 為 0 表示不限制
+type Store struct {
+	mu   sync.Mutex
+	dir  string
+	keep int
+}
+
+// NewStore 建立快照目錄（不存在時）並回傳 Store
+func NewStore(dir string, keep int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, keep: keep}, nil
+}
+
+// Save 寫入快照，超過保留份數時刪除最舊的快照
+// 先寫入暫存檔再改名，寫到一半的檔案不會出現在列表中
+func (s *Store) Save(snap *storage.Snapshot) (*Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	header, err := Encode(w, snap)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := header.CreatedAt.UTC().Format("20060102T150405Z") + "-" + strings.TrimPrefix(header.Checksum, checksumPrefix)[:8]
+	if err := os.Rename(tmp.Name(), s.path(id)); err != nil {
+		return nil, err
+	}
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+	return s.info(id)
+}
+
+// List 列出所有快照，由新到舊排列
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), Extension)
+		if !ok || !idPattern.MatchString(id) {
+			continue
+		}
+		info, err := s.info(id)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidFormat) || errors.Is(err, ErrUnsupportedVersion) {
+			// 讀取期間被保留政策刪除，或不是這個版本能讀取的快照
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID > infos[j].ID
+	})
+	return infos, nil
+}
+
+// Open 開啟快照檔案供下載，呼叫端需關閉檔案
+func (s *Store) Open(id string) (*os.File, *Info, error) {
+	if !idPattern.MatchString(id) {
+		return nil, nil, ErrNotFound
+	}
+	f, err := os.Open(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := readInfo(f, id)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// Load 讀取快照並驗證 checksum
+func (s *Store) Load(id string) (*storage.Snapshot, error) {
+	f, _, err := s.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	snap, _, err := Decode(f)
+	return snap, err
+}
+
+func (s *Store) info(id string) (*Info, error) {
+	f, info, err := s.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return info, nil
+}
+
+func readInfo(f *os.File, id string) (*Info, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	header, err := ReadHeader(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{ID: id, CreatedAt: header.CreatedAt, Tasks: header.Tasks, Size: stat.Size(), Checksum: header.Checksum}, nil
+}
+
+// prune 刪除超過保留份數的舊快照，呼叫前需持有 mu
+func (s *Store) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+	infos, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := s.keep; i < len(infos); i++ {
+		if err := os.Remove(s.path(infos[i].ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+Extension)
+}
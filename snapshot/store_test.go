@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	store, err := NewStore(dir, 2)
+	require.NoError(t, err)
+
+	infos, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+
+	var saved []*Info
+	for i := 0; i < 3; i++ {
+		snap := testSnapshot()
+		snap.CreatedAt = snap.CreatedAt.Add(time.Duration(i) * time.Minute)
+		info, err := store.Save(snap)
+		require.NoError(t, err)
+		assert.Regexp(t, `^20261019T090\d00Z-[0-9a-f]{8}$`, info.ID)
+		assert.Equal(t, 2, info.Tasks)
+		assert.Positive(t, info.Size)
+		saved = append(saved, info)
+	}
+
+	// 只保留最新的兩份，由新到舊排列
+	infos, err = store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []Info{*saved[2], *saved[1]}, infos)
+	_, err = store.Load(saved[0].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	snap, err := store.Load(saved[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, snap.Count())
+
+	f, info, err := store.Open(saved[1].ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, info.Size, int64(len(data)))
+
+	// 不合法的 ID 不會被當成路徑使用
+	_, _, err = store.Open("../" + saved[1].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// 目錄中的其他檔案與暫存檔不會出現在列表
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20261019T100000Z-00000000.snapshot"), []byte("garbage\n"), 0o600))
+	infos, err = store.List()
+	require.NoError(t, err)
+	assert.Len(t, infos, 2)
+
+	// 被修改的快照無法載入
+	path := filepath.Join(dir, saved[2].ID+Extension)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)-3] = 'x'
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err = store.Load(saved[2].ID)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestStore_KeepUnlimited(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		snap := &storage.Snapshot{CreatedAt: time.Date(2026, 10, 19, 9, i, 0, 0, time.UTC)}
+		_, err := store.Save(snap)
+		require.NoError(t, err)
+	}
+	infos, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, infos, 3)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/gogolook/task-api/model"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrShareNotFound    = errors.New("share not found")
+	ErrInvalidShare     = errors.New("cannot share a task with its owner")
+	ErrQuotaExceeded    = errors.New("task quota exceeded")
+	ErrTenantRequired   = errors.New("tenant is required")
+	ErrNoSnapshot       = errors.New("no deleted tasks to restore")
+	ErrTaskTrashed      = errors.New("task is in the trash")
+)
+
+// Access 呼叫者的存取範圍
+// 一般使用者只能存取自己擁有或被分享的任務，其他人的任務一律視為不存在
+type Access struct {
+	// Tenant 任務所屬的 tenant，只有 TenantStorage 會使用
+	Tenant string
+	UserID string
+	// All 可存取所有使用者的任務（admin 或未啟用驗證時），仍限定在同一個 tenant 內
+	All bool
+}
+
+// accessLevel 呼叫者對單一任務的權限，數字越大權限越高
+type accessLevel int
+
+const (
+	levelNone accessLevel = iota
+	levelRead
+	levelWrite
+	levelOwner
+)
+
+func levelFor(p model.Permission) accessLevel {
+	switch p {
+	case model.PermissionRead:
+		return levelRead
+	case model.PermissionWrite:
+		return levelWrite
+	}
+	return levelNone
+}
+
+// idSet 以 slice + map 保存一組任務 ID，支援 O(1) 新增、刪除與 O(limit) 分頁
+type idSet struct {
+	ids   []string
+	index map[string]int
+}
+
+func newIDSet() *idSet {
+	return &idSet{index: make(map[string]int)}
+}
+
+func (s *idSet) add(id string) {
+	if _, exists := s.index[id]; exists {
+		return
+	}
+	s.ids = append(s.ids, id)
+	s.index[id] = len(s.ids) - 1
+}
+
+// remove 與 MemoryStorage.Delete 相同，以最後一個元素補位
+func (s *idSet) remove(id string) {
+	index, exists := s.index[id]
+	if !exists {
+		return
+	}
+	lastIndex := len(s.ids) - 1
+	if index != lastIndex {
+		s.ids[index] = s.ids[lastIndex]
+		s.index[s.ids[index]] = index
+	}
+	s.ids = s.ids[:lastIndex]
+	delete(s.index, id)
+}
+
+func (s *idSet) len() int {
+	return len(s.ids)
+}
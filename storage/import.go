@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/model"
+	"github.com/google/uuid"
+)
+
+// TaskError 批次操作中某個任務的錯誤，Err 為 ErrPermissionDenied 等原本的錯誤
+type TaskError struct {
+	ID  string
+	Err error
+}
+
+func (e *TaskError) Error() string {
+	return e.Err.Error() + ": task " + e.ID
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult 批次匯入寫入的數量
+type ImportResult struct {
+	Created int
+	Updated int
+}
+
+// Import 在同一個寫入鎖內寫入所有任務，任一筆無法寫入時都不寫入
+// upsert 為 false 時忽略 ID 全部新增；為 true 時與 Upsert 相同，依 ID 更新或新增（ID 為空時產生新的 UUID）
+// 寫入後 tasks 的 ID 與 OwnerID 會被填入
+func (s *MemoryStorage) Import(ctx context.Context, access Access, tasks []model.Task, upsert bool) (*ImportResult, error) {
+	s.lock()
+	defer s.mu.Unlock()
+
+	// 先檢查所有任務，確定都能寫入後才修改資料
+	updates := make([]bool, len(tasks))
+	added := make(map[string]bool)
+	newTasks := 0
+	for i := range tasks {
+		id := tasks[i].ID
+		if !upsert || id == "" {
+			newTasks++
+			continue
+		}
+		if trashed, ok := s.trash[id]; ok {
+			if !trashed.visibleTo(access) {
+				return nil, &TaskError{ID: id, Err: ErrPermissionDenied}
+			}
+			return nil, &TaskError{ID: id, Err: ErrTaskTrashed}
+		}
+		if index, exists := s.indexMap[id]; exists {
+			if s.level(access, index) < levelWrite {
+				return nil, &TaskError{ID: id, Err: ErrPermissionDenied}
+			}
+			updates[i] = true
+			continue
+		}
+		// 同一批中重複的 ID 由後面的列更新前面新增的任務
+		if added[id] {
+			updates[i] = true
+			continue
+		}
+		added[id] = true
+		newTasks++
+	}
+	if s.maxTasks > 0 && len(s.tasks)+newTasks > s.maxTasks {
+		logging.FromContext(ctx).Warn("task quota exceeded", "tenant", access.Tenant, "max_tasks", s.maxTasks)
+		return nil, ErrQuotaExceeded
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.thaw()
+	result := &ImportResult{}
+	for i := range tasks {
+		task := &tasks[i]
+		if !upsert || task.ID == "" {
+			task.ID = uuid.New().String()
+		}
+		if updates[i] {
+			index := s.indexMap[task.ID]
+			task.OwnerID = s.tasks[index].OwnerID
+			s.tasks[index] = *task
+			result.Updated++
+			continue
+		}
+		task.OwnerID = access.UserID
+		s.tasks = append(s.tasks, *task)
+		s.indexMap[task.ID] = len(s.tasks) - 1
+		s.addVisible(task.OwnerID, task.ID)
+		result.Created++
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_Import(t *testing.T) {
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+
+	storage := NewMemoryStorage()
+	require.NoError(t, storage.Create(ctx, alice, &model.Task{Name: "Existing"}))
+	_, err := storage.Upsert(ctx, alice, &model.Task{ID: "existing", Name: "Existing"})
+	require.NoError(t, err)
+
+	// create 模式忽略 ID
+	tasks := []model.Task{{ID: "existing", Name: "Copy"}, {Name: "New"}}
+	result, err := storage.Import(ctx, alice, tasks, false)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Created: 2}, result)
+	assert.NotEqual(t, "existing", tasks[0].ID)
+	assert.Equal(t, "alice", tasks[1].OwnerID)
+	assert.Equal(t, 4, storage.Count())
+
+	// upsert 模式依 ID 更新或新增，同一批中重複的 ID 更新前面新增的任務
+	result, err = storage.Import(ctx, alice, []model.Task{
+		{ID: "existing", Name: "Renamed", Status: 1},
+		{ID: "new-id", Name: "New"},
+		{ID: "new-id", Name: "New Renamed"},
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, &ImportResult{Created: 1, Updated: 2}, result)
+	got, err := storage.Get(ctx, alice, "new-id")
+	require.NoError(t, err)
+	assert.Equal(t, "New Renamed", got.Name)
+	assert.Equal(t, 5, storage.Count())
+}
+
+func TestMemoryStorage_ImportAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	tests := []struct {
+		name     string
+		access   Access
+		maxTasks int
+		tasks    []model.Task
+		wantErr  error
+		wantID   string
+	}{
+		{
+			name:    "最後一列沒有寫入權限",
+			access:  bob,
+			tasks:   []model.Task{{ID: "bob-1", Name: "Bob"}, {ID: "alice-task", Name: "Hijacked"}},
+			wantErr: ErrPermissionDenied,
+			wantID:  "alice-task",
+		},
+		{
+			name:    "最後一列在回收桶中",
+			access:  alice,
+			tasks:   []model.Task{{ID: "alice-2", Name: "Alice"}, {ID: "trashed", Name: "Trashed"}},
+			wantErr: ErrTaskTrashed,
+			wantID:  "trashed",
+		},
+		{
+			name:     "超過任務數上限",
+			access:   alice,
+			maxTasks: 2,
+			tasks:    []model.Task{{Name: "Task 1"}, {Name: "Task 2"}},
+			wantErr:  ErrQuotaExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewMemoryStorage()
+			_, err := storage.Upsert(ctx, alice, &model.Task{ID: "alice-task", Name: "Alice Task"})
+			require.NoError(t, err)
+			_, err = storage.Upsert(ctx, alice, &model.Task{ID: "trashed", Name: "Trashed"})
+			require.NoError(t, err)
+			require.NoError(t, storage.Delete(ctx, alice, "trashed"))
+			storage.SetMaxTasks(tt.maxTasks)
+
+			_, err = storage.Import(ctx, tt.access, tt.tasks, true)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantID != "" {
+				var taskErr *TaskError
+				require.ErrorAs(t, err, &taskErr)
+				assert.Equal(t, tt.wantID, taskErr.ID)
+			}
+
+			// 前面的列也不會寫入
+			assert.Equal(t, 1, storage.Count())
+			got, err := storage.Get(ctx, alice, "alice-task")
+			require.NoError(t, err)
+			assert.Equal(t, "Alice Task", got.Name)
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package storage
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/gogolook/task-api/logging"
 	"github.com/gogolook/task-api/model"
 	"github.com/google/uuid"
 )
@@ -29,46 +34,143 @@ func NewPaginationParams(page int) PaginationParams {
 	}
 }
 
+// paginate 從已排序的 tasks 取出 params 指定的頁面
+func paginate(tasks []model.Task, params PaginationParams) *PaginationResult {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 {
+		params.Limit = 100
+	}
+
+	total := len(tasks)
+	pages := (total + params.Limit - 1) / params.Limit
+	data := []model.Task{}
+	if offset := (params.Page - 1) * params.Limit; offset < total {
+		end := offset + params.Limit
+		if end > total {
+			end = total
+		}
+		data = tasks[offset:end]
+	}
+
+	return &PaginationResult{
+		Data: data,
+		Pagination: PaginationInfo{
+			Page:    params.Page,
+			Limit:   params.Limit,
+			Total:   total,
+			Pages:   pages,
+			HasNext: params.Page < pages,
+			HasPrev: params.Page > 1,
+		},
+	}
+}
+
 // PaginationResult 分頁結果
 type PaginationResult struct {
-	Data       []model.Task   `json:"data"`
-	Pagination PaginationInfo `json:"pagination"`
+	Data       []model.Task   `json:"data" yaml:"data"`
+	Pagination PaginationInfo `json:"pagination" yaml:"pagination"`
 }
 
 // PaginationInfo 分頁資訊
 type PaginationInfo struct {
-	Page    int  `json:"page" example:"1"`
-	Limit   int  `json:"limit" example:"100"`
-	Total   int  `json:"total" example:"150"`
-	Pages   int  `json:"pages" example:"2"`
-	HasNext bool `json:"has_next" example:"true"`
-	HasPrev bool `json:"has_prev" example:"false"`
+	Page    int  `json:"page" yaml:"page" example:"1"`
+	Limit   int  `json:"limit" yaml:"limit" example:"100"`
+	Total   int  `json:"total" yaml:"total" example:"150"`
+	Pages   int  `json:"pages" yaml:"pages" example:"2"`
+	HasNext bool `json:"has_next" yaml:"has_next" example:"true"`
+	HasPrev bool `json:"has_prev" yaml:"has_prev" example:"false"`
 }
 
+// Storage 任務儲存介面
+// ctx 來自請求，實作應在 ctx 已取消或逾時時盡早回傳 ctx.Err()，遠端實作也應以 ctx 中斷呼叫
+// 所有操作都依 Access 限定在呼叫者可存取的任務：
+// 看不到的任務回傳 ErrTaskNotFound，看得到但權限不足時回傳 ErrPermissionDenied
+// 新增的任務擁有者為 access.UserID
 type Storage interface {
-	List(params PaginationParams) (*PaginationResult, error)
-	Get(id string) (*model.Task, error)
-	Create(task *model.Task) error
-	Update(id string, task *model.Task) error
-	Delete(id string) error
-	DeleteAll() error
+	List(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error)
+	// Scan 以呼叫時的一致資料，每次最多 chunk 筆將呼叫者看得到的任務交給 fn，fn 回傳錯誤時停止並回傳該錯誤
+	// 走訪期間的新增、修改與刪除不會讓任務重複或遺漏
+	Scan(ctx context.Context, access Access, chunk int, fn func(tasks []model.Task) error) error
+	Get(ctx context.Context, access Access, id string) (*model.Task, error)
+	Create(ctx context.Context, access Access, task *model.Task) error
+	Update(ctx context.Context, access Access, id string, task *model.Task) error
+	Upsert(ctx context.Context, access Access, task *model.Task) (bool, error)
+	// Import 批次寫入任務，任一筆無法寫入時都不寫入；無法寫入的任務以 *TaskError 回傳
+	Import(ctx context.Context, access Access, tasks []model.Task, upsert bool) (*ImportResult, error)
+	// Delete 將任務移到回收桶；回收桶中的任務不會出現在 List、Get 與分頁總數
+	Delete(ctx context.Context, access Access, id string) error
+	// Purge 永久刪除任務，不經過回收桶；也可刪除已在回收桶中的任務
+	Purge(ctx context.Context, access Access, id string) error
+	// Trash 列出呼叫者回收桶中的任務，依刪除時間由新到舊排列
+	Trash(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error)
+	// Restore 將回收桶中的任務移回，回傳還原後的任務
+	Restore(ctx context.Context, access Access, id string) (*model.Task, error)
+	DeleteAll(ctx context.Context, access Access) error
+	// RestoreAll 加回最近一次 DeleteAll 清空的任務，回傳加回的數量；沒有可還原的任務時回傳 ErrNoSnapshot
+	RestoreAll(ctx context.Context, access Access) (int, error)
+	Share(ctx context.Context, access Access, id string, share model.Share) error
+	Unshare(ctx context.Context, access Access, id, userID string) error
+	Shares(ctx context.Context, access Access, id string) ([]model.Share, error)
+	// Close 在服務關閉、所有請求處理完後呼叫，讓需要寫回檔案或遠端的實作 flush 資料
+	Close() error
+}
+
+// Pinger 可回報自身狀態的 storage（選擇性實作），readiness 探針會定期呼叫
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// TrashPurger 可永久刪除回收桶中過期任務的 storage（選擇性實作），由 RunPurger 定期呼叫
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+}
+
+// Counter 可回報任務總數的 storage（選擇性實作），供監控使用
+type Counter interface {
+	Count() int
+}
+
+// LockObserver 接收每次取得鎖前等待的時間，write 表示寫入鎖
+type LockObserver func(write bool, wait time.Duration)
+
+// LockObservable 可觀察鎖競爭的 storage（選擇性實作）
+type LockObservable interface {
+	SetLockObserver(fn LockObserver)
 }
 
 type MemoryStorage struct {
 	mu        sync.RWMutex
 	tasks     []model.Task      // 使用 slice 儲存，保持插入順序
 	indexMap  map[string]int    // uuid -> slice index 的映射
+	visible   map[string]*idSet // user id -> 擁有或被分享的任務，讓限定範圍的列表不必掃過全部任務
+	shares    map[string]map[string]model.Permission // task id -> user id -> 權限
+	maxTasks  int // 任務數上限，0 表示不限制
+	wiped     *wipedTasks // 最近一次 DeleteAll 清空前的資料，供 RestoreAll 還原
+	trash     map[string]trashedTask // 回收桶，不在 tasks 與索引中，因此不計入列表、分頁總數與任務數上限
+	frozen    bool // tasks、shares 與 trash 與快照共用中，寫入前需先以 thaw 複製
+
+	observeLock LockObserver
 }
 
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		tasks:    make([]model.Task, 0),
 		indexMap: make(map[string]int),
+		visible:  make(map[string]*idSet),
+		shares:   make(map[string]map[string]model.Permission),
+		trash:    make(map[string]trashedTask),
 	}
 }
 
-func (s *MemoryStorage) List(params PaginationParams) (*PaginationResult, error) {
-	s.mu.RLock()
+func (s *MemoryStorage) List(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
+	// 大量資料的列表會長時間持有讀取鎖，請求已取消時不必再等待鎖
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.rlock()
 	defer s.mu.RUnlock()
 	
 	// 驗證參數
@@ -80,6 +182,13 @@ func (s *MemoryStorage) List(params PaginationParams) (*PaginationResult, error)
 	}
 	
 	total := len(s.tasks)
+	var ids []string
+	if !access.All {
+		if set, ok := s.visible[access.UserID]; ok {
+			ids = set.ids
+		}
+		total = len(ids)
+	}
 	pages := (total + params.Limit - 1) / params.Limit // 向上取整
 	
 	// 計算 offset
@@ -94,7 +203,13 @@ func (s *MemoryStorage) List(params PaginationParams) (*PaginationResult, error)
 		}
 		// 直接切片，O(1) 操作
 		data = make([]model.Task, end-offset)
-		copy(data, s.tasks[offset:end])
+		if access.All {
+			copy(data, s.tasks[offset:end])
+		} else {
+			for i, id := range ids[offset:end] {
+				data[i] = s.tasks[s.indexMap[id]]
+			}
+		}
 	} else {
 		data = []model.Task{}
 	}
@@ -115,57 +230,203 @@ func (s *MemoryStorage) List(params PaginationParams) (*PaginationResult, error)
 	}, nil
 }
 
-func (s *MemoryStorage) Get(id string) (*model.Task, error) {
-	s.mu.RLock()
+// Scan 可存取所有任務時只在寫入鎖內標記資料為共用（copy-on-write，與 Snapshot 相同），走訪不持有鎖；
+// 否則在讀取鎖內複製呼叫者看得到的任務
+func (s *MemoryStorage) Scan(ctx context.Context, access Access, chunk int, fn func(tasks []model.Task) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if chunk < 1 {
+		chunk = 100
+	}
+
+	var tasks []model.Task
+	if access.All {
+		s.lock()
+		tasks = s.tasks
+		s.frozen = true
+		s.mu.Unlock()
+	} else {
+		s.rlock()
+		if set, ok := s.visible[access.UserID]; ok {
+			tasks = make([]model.Task, len(set.ids))
+			for i, id := range set.ids {
+				tasks[i] = s.tasks[s.indexMap[id]]
+			}
+		}
+		s.mu.RUnlock()
+	}
+
+	for start := 0; start < len(tasks); start += chunk {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// 共用的 slice 不能交給 fn 修改，每批各自複製
+		batch := make([]model.Task, min(chunk, len(tasks)-start))
+		copy(batch, tasks[start:])
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, access Access, id string) (*model.Task, error) {
+	s.rlock()
 	defer s.mu.RUnlock()
 	
-	index, exists := s.indexMap[id]
-	if !exists {
-		return nil, ErrTaskNotFound
+	index, err := s.find(access, id, levelRead)
+	if err != nil {
+		return nil, err
 	}
 	
 	task := s.tasks[index]
 	return &task, nil
 }
 
-func (s *MemoryStorage) Create(task *model.Task) error {
-	s.mu.Lock()
+// SetMaxTasks 設定任務數上限，超過時 Create 與新增的 Upsert 回傳 ErrQuotaExceeded；0 表示不限制
+func (s *MemoryStorage) SetMaxTasks(n int) {
+	s.lock()
 	defer s.mu.Unlock()
+
+	s.maxTasks = n
+}
+
+func (s *MemoryStorage) Create(ctx context.Context, access Access, task *model.Task) error {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+	
+	if s.full() {
+		logging.FromContext(ctx).Warn("task quota exceeded", "tenant", access.Tenant, "max_tasks", s.maxTasks)
+		return ErrQuotaExceeded
+	}
 	
 	task.ID = uuid.New().String()
+	task.OwnerID = access.UserID
 	
 	// 新增到 slice 的最後
 	s.tasks = append(s.tasks, *task)
 	
 	// 更新 index map
 	s.indexMap[task.ID] = len(s.tasks) - 1
+	s.addVisible(task.OwnerID, task.ID)
 	
 	return nil
 }
 
-func (s *MemoryStorage) Update(id string, task *model.Task) error {
-	s.mu.Lock()
+// Update 更新任務內容，需要擁有者或 write 分享權限；擁有者不會被改變
+func (s *MemoryStorage) Update(ctx context.Context, access Access, id string, task *model.Task) error {
+	s.lock()
 	defer s.mu.Unlock()
+	s.thaw()
 	
-	index, exists := s.indexMap[id]
-	if !exists {
-		return ErrTaskNotFound
+	index, err := s.find(access, id, levelWrite)
+	if err != nil {
+		return err
 	}
 
 	task.ID = id
+	task.OwnerID = s.tasks[index].OwnerID
 	s.tasks[index] = *task
 	
 	return nil
 }
 
-func (s *MemoryStorage) Delete(id string) error {
-	s.mu.Lock()
+// Upsert 依 task.ID 更新既有任務，不存在時以該 ID 新增（ID 為空時產生新的 UUID），回傳是否為新增
+// ID 已被呼叫者無法寫入的任務使用時回傳 ErrPermissionDenied
+func (s *MemoryStorage) Upsert(ctx context.Context, access Access, task *model.Task) (bool, error) {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+
+	// 回收桶中的任務需先還原，避免同一個 ID 同時存在於回收桶與任務清單
+	if trashed, ok := s.trash[task.ID]; ok {
+		if !trashed.visibleTo(access) {
+			return false, ErrPermissionDenied
+		}
+		return false, ErrTaskTrashed
+	}
+
+	if index, exists := s.indexMap[task.ID]; exists {
+		if s.level(access, index) < levelWrite {
+			return false, ErrPermissionDenied
+		}
+		task.OwnerID = s.tasks[index].OwnerID
+		s.tasks[index] = *task
+		return false, nil
+	}
+
+	if s.full() {
+		logging.FromContext(ctx).Warn("task quota exceeded", "tenant", access.Tenant, "max_tasks", s.maxTasks)
+		return false, ErrQuotaExceeded
+	}
+	task.OwnerID = access.UserID
+	s.tasks = append(s.tasks, *task)
+	s.indexMap[task.ID] = len(s.tasks) - 1
+	s.addVisible(task.OwnerID, task.ID)
+
+	return true, nil
+}
+
+// Delete 將任務移到回收桶，只有擁有者可以刪除
+// 分享設定隨任務保留，還原時一併恢復
+func (s *MemoryStorage) Delete(ctx context.Context, access Access, id string) error {
+	s.lock()
 	defer s.mu.Unlock()
+	s.thaw()
 	
-	index, exists := s.indexMap[id]
-	if !exists {
-		return ErrTaskNotFound
+	index, err := s.find(access, id, levelOwner)
+	if err != nil {
+		return err
+	}
+
+	task := s.tasks[index]
+	deletedAt := time.Now().UTC()
+	task.DeletedAt = &deletedAt
+	s.trash[id] = trashedTask{task: task, shares: s.shares[id]}
+	s.remove(index)
+
+	return nil
+}
+
+// Purge 永久刪除任務，任務可以在回收桶中；只有擁有者可以刪除
+func (s *MemoryStorage) Purge(ctx context.Context, access Access, id string) error {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+
+	if trashed, ok := s.trash[id]; ok {
+		if !trashed.visibleTo(access) {
+			return ErrTaskNotFound
+		}
+		delete(s.trash, id)
+		return nil
+	}
+
+	index, err := s.find(access, id, levelOwner)
+	if err != nil {
+		return err
 	}
+	s.remove(index)
+
+	return nil
+}
+
+// remove 將任務從 slice 與所有索引移除，呼叫前需持有寫入鎖
+func (s *MemoryStorage) remove(index int) {
+	id := s.tasks[index].ID
+
+	// 從擁有者與被分享者的索引移除
+	s.removeVisible(s.tasks[index].OwnerID, id)
+	for userID := range s.shares[id] {
+		s.removeVisible(userID, id)
+	}
+	delete(s.shares, id)
 	
 	lastIndex := len(s.tasks) - 1
 	
@@ -181,17 +442,354 @@ func (s *MemoryStorage) Delete(id string) error {
 	
 	// 從 index map 中刪除
 	delete(s.indexMap, id)
-	
-	return nil
 }
 
-func (s *MemoryStorage) DeleteAll() error {
-	s.mu.Lock()
+// Trash 列出呼叫者回收桶中的任務，依刪除時間由新到舊排列
+// 回收桶只有擁有者看得到，被分享者在任務刪除後就無法存取
+func (s *MemoryStorage) Trash(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.rlock()
+	defer s.mu.RUnlock()
+
+	tasks := make([]model.Task, 0)
+	for _, trashed := range s.trash {
+		if trashed.visibleTo(access) {
+			tasks = append(tasks, trashed.task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return paginate(tasks, params), nil
+}
+
+// Restore 將回收桶中的任務移回，只有擁有者可以還原；還原後計入任務數上限
+func (s *MemoryStorage) Restore(ctx context.Context, access Access, id string) (*model.Task, error) {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+
+	trashed, ok := s.trash[id]
+	if !ok || !trashed.visibleTo(access) {
+		return nil, ErrTaskNotFound
+	}
+	if s.full() {
+		logging.FromContext(ctx).Warn("task quota exceeded", "tenant", access.Tenant, "max_tasks", s.maxTasks)
+		return nil, ErrQuotaExceeded
+	}
+
+	task := trashed.task
+	task.DeletedAt = nil
+	s.tasks = append(s.tasks, task)
+	s.indexMap[id] = len(s.tasks) - 1
+	s.addVisible(task.OwnerID, id)
+	if len(trashed.shares) > 0 {
+		s.shares[id] = trashed.shares
+		for userID := range trashed.shares {
+			s.addVisible(userID, id)
+		}
+	}
+	delete(s.trash, id)
+
+	return &task, nil
+}
+
+// PurgeTrash 永久刪除在 before 之前移到回收桶的任務，回傳刪除的數量
+func (s *MemoryStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	s.lock()
+	defer s.mu.Unlock()
+
+	// 定期執行時多半沒有過期的任務，有要刪除的任務時才複製快照共用的資料
+	var expired []string
+	for id, trashed := range s.trash {
+		if trashed.task.DeletedAt.Before(before) {
+			expired = append(expired, id)
+		}
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	s.thaw()
+	for _, id := range expired {
+		delete(s.trash, id)
+	}
+	return len(expired), nil
+}
+
+// trashedTask 回收桶中的任務與刪除前的分享設定
+type trashedTask struct {
+	task   model.Task
+	shares map[string]model.Permission
+}
+
+// visibleTo 回收桶中的任務只有擁有者與可存取所有任務的呼叫者看得到
+func (t trashedTask) visibleTo(access Access) bool {
+	return access.All || (access.UserID != "" && t.task.OwnerID == access.UserID)
+}
+
+// DeleteAll 刪除所有任務，需要可存取所有任務的權限；回收桶不受影響，仍依保留期限清除
+func (s *MemoryStorage) DeleteAll(ctx context.Context, access Access) error {
+	if !access.All {
+		return ErrPermissionDenied
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock()
 	defer s.mu.Unlock()
+	s.thaw()
+	// 等待寫入鎖期間請求可能已被取消，破壞性操作不應在呼叫者放棄後才執行
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	
-	// 清空 slice 和 map
+	// 清空前保留原本的 slice 和 map 作為快照，之後改用新的，不需要複製
+	// 沒有任務時不覆蓋快照，重複的清空請求不會讓先前的資料無法還原
+	deleted := len(s.tasks)
+	if deleted > 0 {
+		s.wiped = &wipedTasks{tasks: s.tasks, shares: s.shares}
+	}
 	s.tasks = make([]model.Task, 0)
 	s.indexMap = make(map[string]int)
+	s.visible = make(map[string]*idSet)
+	s.shares = make(map[string]map[string]model.Permission)
+	logging.FromContext(ctx).Warn("deleted all tasks", "tenant", access.Tenant, "count", deleted)
 	
 	return nil
+}
+
+// wipedTasks DeleteAll 清空前的任務與分享設定
+type wipedTasks struct {
+	tasks  []model.Task
+	shares map[string]map[string]model.Permission
+}
+
+// RestoreAll 將最近一次 DeleteAll 清空的任務加回，需要可存取所有任務的權限
+// 清空後新增的任務會保留，ID 已存在的任務不會被覆蓋；快照還原後即捨棄，不能重複還原
+// 還原的是原本就存在的資料，不受任務數上限限制
+func (s *MemoryStorage) RestoreAll(ctx context.Context, access Access) (int, error) {
+	if !access.All {
+		return 0, ErrPermissionDenied
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if s.wiped == nil {
+		return 0, ErrNoSnapshot
+	}
+
+	restored := 0
+	for _, task := range s.wiped.tasks {
+		if _, exists := s.indexMap[task.ID]; exists {
+			continue
+		}
+		if _, trashed := s.trash[task.ID]; trashed {
+			continue
+		}
+		s.tasks = append(s.tasks, task)
+		s.indexMap[task.ID] = len(s.tasks) - 1
+		s.addVisible(task.OwnerID, task.ID)
+		if shares := s.wiped.shares[task.ID]; len(shares) > 0 {
+			s.shares[task.ID] = shares
+			for userID := range shares {
+				s.addVisible(userID, task.ID)
+			}
+		}
+		restored++
+	}
+	s.wiped = nil
+	logging.FromContext(ctx).Warn("restored deleted tasks", "tenant", access.Tenant, "count", restored)
+
+	return restored, nil
+}
+
+// Share 將任務分享給其他使用者，已分享時更新權限；只有擁有者可以分享
+func (s *MemoryStorage) Share(ctx context.Context, access Access, id string, share model.Share) error {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+
+	index, err := s.find(access, id, levelOwner)
+	if err != nil {
+		return err
+	}
+	if share.UserID == s.tasks[index].OwnerID {
+		return ErrInvalidShare
+	}
+
+	if s.shares[id] == nil {
+		s.shares[id] = make(map[string]model.Permission)
+	}
+	s.shares[id][share.UserID] = share.Permission
+	s.addVisible(share.UserID, id)
+	return nil
+}
+
+// Unshare 取消分享；只有擁有者可以取消
+func (s *MemoryStorage) Unshare(ctx context.Context, access Access, id, userID string) error {
+	s.lock()
+	defer s.mu.Unlock()
+	s.thaw()
+
+	if _, err := s.find(access, id, levelOwner); err != nil {
+		return err
+	}
+	if _, exists := s.shares[id][userID]; !exists {
+		return ErrShareNotFound
+	}
+
+	delete(s.shares[id], userID)
+	if len(s.shares[id]) == 0 {
+		delete(s.shares, id)
+	}
+	s.removeVisible(userID, id)
+	return nil
+}
+
+// Shares 依使用者 ID 排序列出任務的分享設定；只有擁有者可以查看
+func (s *MemoryStorage) Shares(ctx context.Context, access Access, id string) ([]model.Share, error) {
+	s.rlock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.find(access, id, levelOwner); err != nil {
+		return nil, err
+	}
+
+	shares := make([]model.Share, 0, len(s.shares[id]))
+	for userID, permission := range s.shares[id] {
+		shares = append(shares, model.Share{UserID: userID, Permission: permission})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].UserID < shares[j].UserID
+	})
+	return shares, nil
+}
+
+// Count 回傳任務總數
+func (s *MemoryStorage) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tasks)
+}
+
+// SetLockObserver 設定鎖等待時間的觀察者，需在開始處理請求前呼叫
+func (s *MemoryStorage) SetLockObserver(fn LockObserver) {
+	s.observeLock = fn
+}
+
+// lock 取得寫入鎖，有觀察者時回報等待時間
+func (s *MemoryStorage) lock() {
+	if s.observeLock == nil {
+		s.mu.Lock()
+		return
+	}
+	start := time.Now()
+	s.mu.Lock()
+	s.observeLock(true, time.Since(start))
+}
+
+// rlock 取得讀取鎖，有觀察者時回報等待時間
+func (s *MemoryStorage) rlock() {
+	if s.observeLock == nil {
+		s.mu.RLock()
+		return
+	}
+	start := time.Now()
+	s.mu.RLock()
+	s.observeLock(false, time.Since(start))
+}
+
+// Ping 確認鎖可以取得（沒有卡住的寫入）且索引與資料一致
+func (s *MemoryStorage) Ping(ctx context.Context) error {
+	result := make(chan error, 1)
+	go func() {
+		s.rlock()
+		defer s.mu.RUnlock()
+		if len(s.indexMap) != len(s.tasks) {
+			result <- fmt.Errorf("index out of sync: %d entries for %d tasks", len(s.indexMap), len(s.tasks))
+			return
+		}
+		result <- nil
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("storage lock not acquired: %w", ctx.Err())
+	}
+}
+
+// Close 記憶體 storage 沒有需要釋放的資源
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+// full 回傳是否已達任務數上限，呼叫前需持有鎖
+func (s *MemoryStorage) full() bool {
+	return s.maxTasks > 0 && len(s.tasks) >= s.maxTasks
+}
+
+// find 找出呼叫者至少擁有 required 權限的任務位置，呼叫前需持有鎖
+func (s *MemoryStorage) find(access Access, id string, required accessLevel) (int, error) {
+	index, exists := s.indexMap[id]
+	if !exists {
+		return 0, ErrTaskNotFound
+	}
+
+	level := s.level(access, index)
+	if level == levelNone {
+		return 0, ErrTaskNotFound
+	}
+	if level < required {
+		return 0, ErrPermissionDenied
+	}
+	return index, nil
+}
+
+// level 計算呼叫者對指定位置任務的權限
+func (s *MemoryStorage) level(access Access, index int) accessLevel {
+	task := s.tasks[index]
+	if access.All || (access.UserID != "" && task.OwnerID == access.UserID) {
+		return levelOwner
+	}
+	return levelFor(s.shares[task.ID][access.UserID])
+}
+
+func (s *MemoryStorage) addVisible(userID, id string) {
+	if userID == "" {
+		return
+	}
+	set, ok := s.visible[userID]
+	if !ok {
+		set = newIDSet()
+		s.visible[userID] = set
+	}
+	set.add(id)
+}
+
+func (s *MemoryStorage) removeVisible(userID, id string) {
+	set, ok := s.visible[userID]
+	if !ok {
+		return
+	}
+	set.remove(id)
+	if set.len() == 0 {
+		delete(s.visible, userID)
+	}
 }
\ No newline at end of file
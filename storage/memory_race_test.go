@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -23,14 +24,14 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 					Name:   fmt.Sprintf("Task %d", index),
 					Status: 0,
 				}
-				storage.Create(task)
+				storage.Create(context.Background(), allAccess, task)
 			}(i)
 		}
 		
 		wg.Wait()
 		
 		// 檢查任務數量
-		result, err := storage.List(NewPaginationParams(1))
+		result, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
 		if err != nil {
 			t.Errorf("獲取任務列表失敗: %v", err)
 		}
@@ -42,7 +43,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 	t.Run("並發讀寫同一個 map", func(t *testing.T) {
 		// 準備測試資料
 		task := &model.Task{Name: "Test", Status: 0}
-		storage.Create(task)
+		storage.Create(context.Background(), allAccess, task)
 		taskID := task.ID
 		
 		var wg sync.WaitGroup
@@ -54,7 +55,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 			// 讀取操作
 			go func() {
 				defer wg.Done()
-				storage.Get(taskID)
+				storage.Get(context.Background(), allAccess, taskID)
 			}()
 			
 			// 更新操作
@@ -64,7 +65,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 					Name:   fmt.Sprintf("Updated %d", index),
 					Status: 1,
 				}
-				storage.Update(taskID, updated)
+				storage.Update(context.Background(), allAccess, taskID, updated)
 			}(i)
 		}
 		
@@ -76,7 +77,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 		taskIDs := make([]string, 10)
 		for i := 0; i < 10; i++ {
 			task := &model.Task{Name: fmt.Sprintf("Task %d", i), Status: 0}
-			storage.Create(task)
+			storage.Create(context.Background(), allAccess, task)
 			taskIDs[i] = task.ID
 		}
 		
@@ -87,7 +88,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 			wg.Add(1)
 			go func(id string) {
 				defer wg.Done()
-				storage.Delete(id)
+				storage.Delete(context.Background(), allAccess, id)
 			}(taskIDs[i])
 		}
 		
@@ -98,7 +99,7 @@ func TestMemoryStorage_RaceCondition(t *testing.T) {
 				defer wg.Done()
 				// 在遍歷 map 時，其他 goroutine 正在修改它
 				// 這可能導致 "concurrent map iteration and map write" panic
-				_, _ = storage.List(NewPaginationParams(1))
+				_, _ = storage.List(context.Background(), allAccess, NewPaginationParams(1))
 			}()
 		}
 		
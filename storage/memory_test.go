@@ -1,13 +1,18 @@
 package storage
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/gogolook/task-api/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// allAccess 不限定擁有者的存取範圍
+var allAccess = Access{All: true}
+
 func TestMemoryStorage_Create(t *testing.T) {
 	storage := NewMemoryStorage()
 	
@@ -16,7 +21,7 @@ func TestMemoryStorage_Create(t *testing.T) {
 		Status: 0,
 	}
 	
-	err := storage.Create(task)
+	err := storage.Create(context.Background(), allAccess, task)
 	require.NoError(t, err)
 	assert.NotEmpty(t, task.ID)
 }
@@ -32,12 +37,12 @@ func TestMemoryStorage_List(t *testing.T) {
 	}
 	
 	for _, task := range tasks {
-		err := storage.Create(task)
+		err := storage.Create(context.Background(), allAccess, task)
 		require.NoError(t, err)
 	}
 
 	// 測試 List
-	result, err := storage.List(NewPaginationParams(1))
+	result, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
 	require.NoError(t, err)
 	assert.Len(t, result.Data, 3)
 	assert.Equal(t, 3, result.Pagination.Total)
@@ -46,6 +51,60 @@ func TestMemoryStorage_List(t *testing.T) {
 	assert.False(t, result.Pagination.HasPrev)
 }
 
+func TestMemoryStorage_Scan(t *testing.T) {
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+
+	tests := []struct {
+		name   string
+		access Access
+	}{
+		{name: "可存取所有任務", access: allAccess},
+		{name: "只看得到自己的任務", access: alice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewMemoryStorage()
+			var ids []string
+			for i := 0; i < 5; i++ {
+				task := &model.Task{Name: "Task"}
+				require.NoError(t, storage.Create(ctx, alice, task))
+				ids = append(ids, task.ID)
+			}
+			require.NoError(t, storage.Create(ctx, Access{UserID: "bob"}, &model.Task{Name: "Bob"}))
+			want := ids
+			if tt.access.All {
+				want = nil
+			}
+
+			// 走訪途中刪除第一筆（swap-delete 會移動最後一筆）並修改回傳的任務，結果不重複也不遺漏
+			var got []string
+			err := storage.Scan(ctx, tt.access, 2, func(tasks []model.Task) error {
+				if len(got) == 0 {
+					require.NoError(t, storage.Delete(ctx, alice, ids[0]))
+				}
+				for i := range tasks {
+					got = append(got, tasks[i].ID)
+					tasks[i].Name = "Changed"
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			if want != nil {
+				assert.Equal(t, want, got)
+			} else {
+				assert.Len(t, got, 6)
+				assert.Subset(t, got, ids)
+			}
+
+			task, err := storage.Get(ctx, alice, ids[1])
+			require.NoError(t, err)
+			assert.Equal(t, "Task", task.Name)
+		})
+	}
+}
+
 func TestMemoryStorage_Get(t *testing.T) {
 	storage := NewMemoryStorage()
 	
@@ -55,11 +114,11 @@ func TestMemoryStorage_Get(t *testing.T) {
 		Status: 0,
 	}
 	
-	err := storage.Create(task)
+	err := storage.Create(context.Background(), allAccess, task)
 	require.NoError(t, err)
 	
 	// 測試 Get
-	retrieved, err := storage.Get(task.ID)
+	retrieved, err := storage.Get(context.Background(), allAccess, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, task.Name, retrieved.Name)
 	assert.Equal(t, task.Status, retrieved.Status)
@@ -75,7 +134,7 @@ func TestMemoryStorage_Update(t *testing.T) {
 		Status: 0,
 	}
 	
-	err := storage.Create(task)
+	err := storage.Create(context.Background(), allAccess, task)
 	require.NoError(t, err)
 	
 	// 測試 Update
@@ -84,11 +143,11 @@ func TestMemoryStorage_Update(t *testing.T) {
 		Status: 1,
 	}
 	
-	err = storage.Update(task.ID, updatedTask)
+	err = storage.Update(context.Background(), allAccess, task.ID, updatedTask)
 	require.NoError(t, err)
 	
 	// 驗證更新結果
-	retrieved, err := storage.Get(task.ID)
+	retrieved, err := storage.Get(context.Background(), allAccess, task.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Task", retrieved.Name)
 	assert.Equal(t, 1, retrieved.Status)
@@ -104,15 +163,15 @@ func TestMemoryStorage_Delete(t *testing.T) {
 		Status: 0,
 	}
 	
-	err := storage.Create(task)
+	err := storage.Create(context.Background(), allAccess, task)
 	require.NoError(t, err)
 	
 	// 測試 Delete
-	err = storage.Delete(task.ID)
+	err = storage.Delete(context.Background(), allAccess, task.ID)
 	require.NoError(t, err)
 	
 	// 驗證刪除結果
-	_, err = storage.Get(task.ID)
+	_, err = storage.Get(context.Background(), allAccess, task.ID)
 	assert.Equal(t, ErrTaskNotFound, err)
 }
 
@@ -127,30 +186,72 @@ func TestMemoryStorage_DeleteAll(t *testing.T) {
 	}
 	
 	for _, task := range tasks {
-		err := storage.Create(task)
+		err := storage.Create(context.Background(), allAccess, task)
 		require.NoError(t, err)
 	}
 	
 	// 驗證任務已新增
-	result, err := storage.List(NewPaginationParams(1))
+	result, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
 	require.NoError(t, err)
 	assert.Equal(t, 3, result.Pagination.Total)
 	
 	// 測試 DeleteAll
-	err = storage.DeleteAll()
+	err = storage.DeleteAll(context.Background(), allAccess)
 	require.NoError(t, err)
 	
 	// 驗證所有任務已刪除
-	result, err = storage.List(NewPaginationParams(1))
+	result, err = storage.List(context.Background(), allAccess, NewPaginationParams(1))
 	require.NoError(t, err)
 	assert.Equal(t, 0, result.Pagination.Total)
 	assert.Len(t, result.Data, 0)
 }
 
+func TestMemoryStorage_RestoreAll(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	_, err := storage.RestoreAll(ctx, allAccess)
+	assert.Equal(t, ErrNoSnapshot, err)
+
+	task := &model.Task{Name: "Alice Task"}
+	require.NoError(t, storage.Create(ctx, alice, task))
+	require.NoError(t, storage.Share(ctx, alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+	require.NoError(t, storage.DeleteAll(ctx, allAccess))
+
+	// 再次清空已經沒有任務，不會覆蓋先前的快照
+	require.NoError(t, storage.DeleteAll(ctx, allAccess))
+
+	// 清空後新增的任務會保留
+	later := &model.Task{Name: "Created Later"}
+	require.NoError(t, storage.Create(ctx, bob, later))
+
+	_, err = storage.RestoreAll(ctx, alice)
+	assert.Equal(t, ErrPermissionDenied, err)
+
+	restored, err := storage.RestoreAll(ctx, allAccess)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+
+	// 擁有者與分享設定一併還原
+	got, err := storage.Get(ctx, bob, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.OwnerID)
+	result, err := storage.List(ctx, bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Pagination.Total)
+
+	// 快照還原後即捨棄
+	_, err = storage.RestoreAll(ctx, allAccess)
+	assert.Equal(t, ErrNoSnapshot, err)
+	require.NoError(t, storage.Ping(ctx))
+}
+
 func TestMemoryStorage_GetNotFound(t *testing.T) {
 	storage := NewMemoryStorage()
 	
-	_, err := storage.Get("nonexistent")
+	_, err := storage.Get(context.Background(), allAccess, "nonexistent")
 	assert.Equal(t, ErrTaskNotFound, err)
 }
 
@@ -162,13 +263,327 @@ func TestMemoryStorage_UpdateNotFound(t *testing.T) {
 		Status: 0,
 	}
 	
-	err := storage.Update("nonexistent", task)
+	err := storage.Update(context.Background(), allAccess, "nonexistent", task)
 	assert.Equal(t, ErrTaskNotFound, err)
 }
 
 func TestMemoryStorage_DeleteNotFound(t *testing.T) {
 	storage := NewMemoryStorage()
 	
-	err := storage.Delete("nonexistent")
+	err := storage.Delete(context.Background(), allAccess, "nonexistent")
 	assert.Equal(t, ErrTaskNotFound, err)
-}
\ No newline at end of file
+}
+
+func TestMemoryStorage_Trash(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	task := &model.Task{Name: "Alice Task"}
+	require.NoError(t, storage.Create(ctx, alice, task))
+	require.NoError(t, storage.Share(ctx, alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+	require.NoError(t, storage.Delete(ctx, alice, task.ID))
+
+	// 回收桶中的任務不會出現在一般的讀取與列表，也不計入任務數
+	_, err := storage.Get(ctx, alice, task.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+	result, err := storage.List(ctx, bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Equal(t, 0, storage.Count())
+	assert.Equal(t, ErrTaskNotFound, storage.Delete(ctx, alice, task.ID))
+
+	// 只有擁有者看得到回收桶中的任務
+	trash, err := storage.Trash(ctx, alice, NewPaginationParams(1))
+	require.NoError(t, err)
+	require.Len(t, trash.Data, 1)
+	assert.Equal(t, task.ID, trash.Data[0].ID)
+	assert.NotNil(t, trash.Data[0].DeletedAt)
+	trash, err = storage.Trash(ctx, bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, trash.Data)
+
+	// 回收桶中的 ID 不能再匯入
+	_, err = storage.Upsert(ctx, alice, &model.Task{ID: task.ID, Name: "Imported"})
+	assert.Equal(t, ErrTaskTrashed, err)
+	_, err = storage.Upsert(ctx, bob, &model.Task{ID: task.ID, Name: "Imported"})
+	assert.Equal(t, ErrPermissionDenied, err)
+
+	_, err = storage.Restore(ctx, bob, task.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+
+	// 還原後分享設定一併恢復
+	restored, err := storage.Restore(ctx, alice, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	got, err := storage.Get(ctx, bob, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice Task", got.Name)
+	_, err = storage.Restore(ctx, alice, task.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+
+	// Purge 可以永久刪除一般任務或回收桶中的任務
+	require.NoError(t, storage.Delete(ctx, alice, task.ID))
+	assert.Equal(t, ErrTaskNotFound, storage.Purge(ctx, bob, task.ID))
+	require.NoError(t, storage.Purge(ctx, alice, task.ID))
+	_, err = storage.Restore(ctx, alice, task.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+
+	other := &model.Task{Name: "Other Task"}
+	require.NoError(t, storage.Create(ctx, alice, other))
+	require.NoError(t, storage.Purge(ctx, alice, other.ID))
+	trash, err = storage.Trash(ctx, alice, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, trash.Data)
+	require.NoError(t, storage.Ping(ctx))
+}
+
+func TestMemoryStorage_RestoreQuota(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.SetMaxTasks(1)
+	ctx := context.Background()
+
+	task := &model.Task{Name: "Task 1"}
+	require.NoError(t, storage.Create(ctx, allAccess, task))
+	require.NoError(t, storage.Delete(ctx, allAccess, task.ID))
+	require.NoError(t, storage.Create(ctx, allAccess, &model.Task{Name: "Task 2"}))
+
+	_, err := storage.Restore(ctx, allAccess, task.ID)
+	assert.Equal(t, ErrQuotaExceeded, err)
+}
+
+func TestMemoryStorage_PurgeTrash(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+
+	old := &model.Task{Name: "Old Task"}
+	require.NoError(t, storage.Create(ctx, allAccess, old))
+	require.NoError(t, storage.Delete(ctx, allAccess, old.ID))
+	cutoff := time.Now().Add(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	recent := &model.Task{Name: "Recent Task"}
+	require.NoError(t, storage.Create(ctx, allAccess, recent))
+	require.NoError(t, storage.Delete(ctx, allAccess, recent.ID))
+
+	// 沒有過期的任務時不複製快照共用的資料
+	_, err := storage.Snapshot(ctx)
+	require.NoError(t, err)
+	purged, err := storage.PurgeTrash(ctx, cutoff.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.True(t, storage.frozen)
+
+	purged, err = storage.PurgeTrash(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.False(t, storage.frozen)
+
+	trash, err := storage.Trash(ctx, allAccess, NewPaginationParams(1))
+	require.NoError(t, err)
+	require.Len(t, trash.Data, 1)
+	assert.Equal(t, recent.ID, trash.Data[0].ID)
+}
+
+func TestMemoryStorage_Upsert(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	task := &model.Task{ID: "fixed-id", Name: "Imported Task", Status: 0}
+	created, err := storage.Upsert(context.Background(), allAccess, task)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	got, err := storage.Get(context.Background(), allAccess, "fixed-id")
+	require.NoError(t, err)
+	assert.Equal(t, "Imported Task", got.Name)
+
+	created, err = storage.Upsert(context.Background(), allAccess, &model.Task{ID: "fixed-id", Name: "Updated Task", Status: 1})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	got, err = storage.Get(context.Background(), allAccess, "fixed-id")
+	require.NoError(t, err)
+	assert.Equal(t, model.Task{ID: "fixed-id", Name: "Updated Task", Status: 1}, *got)
+
+	// 沒有 ID 時產生新的 UUID
+	task = &model.Task{Name: "New Task"}
+	created, err = storage.Upsert(context.Background(), allAccess, task)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEmpty(t, task.ID)
+}
+
+func TestMemoryStorage_Ownership(t *testing.T) {
+	storage := NewMemoryStorage()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	aliceTask := &model.Task{Name: "Alice Task"}
+	require.NoError(t, storage.Create(context.Background(), alice, aliceTask))
+	assert.Equal(t, "alice", aliceTask.OwnerID)
+	bobTask := &model.Task{Name: "Bob Task"}
+	require.NoError(t, storage.Create(context.Background(), bob, bobTask))
+
+	// 列表只包含自己的任務
+	result, err := storage.List(context.Background(), alice, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Task{*aliceTask}, result.Data)
+	assert.Equal(t, 1, result.Pagination.Total)
+
+	result, err = storage.List(context.Background(), allAccess, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Pagination.Total)
+
+	// 其他人的任務視為不存在
+	_, err = storage.Get(context.Background(), alice, bobTask.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+	assert.Equal(t, ErrTaskNotFound, storage.Update(context.Background(), alice, bobTask.ID, &model.Task{Name: "Hijacked"}))
+	assert.Equal(t, ErrTaskNotFound, storage.Delete(context.Background(), alice, bobTask.ID))
+
+	// 更新不會改變擁有者
+	require.NoError(t, storage.Update(context.Background(), alice, aliceTask.ID, &model.Task{Name: "Renamed", OwnerID: "bob"}))
+	got, err := storage.Get(context.Background(), alice, aliceTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.OwnerID)
+
+	// Upsert 不能覆寫其他人的任務
+	_, err = storage.Upsert(context.Background(), alice, &model.Task{ID: bobTask.ID, Name: "Hijacked", OwnerID: "alice"})
+	assert.Equal(t, ErrPermissionDenied, err)
+
+	assert.Equal(t, ErrPermissionDenied, storage.DeleteAll(context.Background(), alice))
+
+	require.NoError(t, storage.Delete(context.Background(), bob, bobTask.ID))
+	result, err = storage.List(context.Background(), bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+}
+
+func TestMemoryStorage_Share(t *testing.T) {
+	storage := NewMemoryStorage()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	task := &model.Task{Name: "Shared Task"}
+	require.NoError(t, storage.Create(context.Background(), alice, task))
+
+	// 分享 read：可以讀取與列出，但不能修改
+	require.NoError(t, storage.Share(context.Background(), alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+	got, err := storage.Get(context.Background(), bob, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Shared Task", got.Name)
+
+	result, err := storage.List(context.Background(), bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+
+	assert.Equal(t, ErrPermissionDenied, storage.Update(context.Background(), bob, task.ID, &model.Task{Name: "Edited"}))
+
+	// 升級為 write：可以修改，但不能刪除或再分享
+	require.NoError(t, storage.Share(context.Background(), alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionWrite}))
+	require.NoError(t, storage.Update(context.Background(), bob, task.ID, &model.Task{Name: "Edited"}))
+	assert.Equal(t, ErrPermissionDenied, storage.Delete(context.Background(), bob, task.ID))
+	assert.Equal(t, ErrPermissionDenied, storage.Share(context.Background(), bob, task.ID, model.Share{UserID: "carol", Permission: model.PermissionRead}))
+
+	shares, err := storage.Shares(context.Background(), alice, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Share{{UserID: "bob", Permission: model.PermissionWrite}}, shares)
+
+	assert.Equal(t, ErrInvalidShare, storage.Share(context.Background(), alice, task.ID, model.Share{UserID: "alice", Permission: model.PermissionRead}))
+
+	// 取消分享後即看不到
+	require.NoError(t, storage.Unshare(context.Background(), alice, task.ID, "bob"))
+	_, err = storage.Get(context.Background(), bob, task.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+	assert.Equal(t, ErrShareNotFound, storage.Unshare(context.Background(), alice, task.ID, "bob"))
+
+	// 刪除任務會一併移除分享
+	require.NoError(t, storage.Share(context.Background(), alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+	require.NoError(t, storage.Delete(context.Background(), alice, task.ID))
+	result, err = storage.List(context.Background(), bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+}
+
+func TestMemoryStorage_MaxTasks(t *testing.T) {
+	storage := NewMemoryStorage()
+	storage.SetMaxTasks(2)
+
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task 1"}))
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task 2"}))
+	assert.Equal(t, ErrQuotaExceeded, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task 3"}))
+
+	_, err := storage.Upsert(context.Background(), allAccess, &model.Task{ID: "new", Name: "Task 3"})
+	assert.Equal(t, ErrQuotaExceeded, err)
+
+	// 刪除後即可再新增
+	result, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
+	require.NoError(t, err)
+	require.NoError(t, storage.Delete(context.Background(), allAccess, result.Data[0].ID))
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task 3"}))
+}
+
+func TestMemoryStorage_Ping(t *testing.T) {
+	storage := NewMemoryStorage()
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task"}))
+	assert.NoError(t, storage.Ping(context.Background()))
+
+	// 寫入卡住時在逾時內回報失敗
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, storage.Ping(ctx), context.DeadlineExceeded)
+}
+
+func TestMemoryStorage_LockObserver(t *testing.T) {
+	storage := NewMemoryStorage()
+	var reads, writes int
+	storage.SetLockObserver(func(write bool, wait time.Duration) {
+		if write {
+			writes++
+		} else {
+			reads++
+		}
+	})
+
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task"}))
+	_, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, writes)
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, 1, storage.Count())
+}
+
+func TestMemoryStorage_Canceled(t *testing.T) {
+	storage := NewMemoryStorage()
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := storage.List(ctx, allAccess, NewPaginationParams(1))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, storage.DeleteAll(ctx, allAccess), context.Canceled)
+
+	// 取消的 DeleteAll 不會刪除資料
+	assert.Equal(t, 1, storage.Count())
+}
+
+func TestMemoryStorage_DeleteAllCanceledWhileWaiting(t *testing.T) {
+	storage := NewMemoryStorage()
+	require.NoError(t, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task"}))
+
+	// 持有讀取鎖讓 DeleteAll 等待，期間取消請求
+	storage.mu.RLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- storage.DeleteAll(ctx, allAccess) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	storage.mu.RUnlock()
+
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 1, storage.Count())
+}
@@ -1,22 +1,36 @@
 package storage
 
 import (
+	"context"
+
 	"github.com/gogolook/task-api/model"
 )
 
 // MockStorage 用於測試的 mock storage
 type MockStorage struct {
-	ListFunc      func(params PaginationParams) (*PaginationResult, error)
-	GetFunc       func(id string) (*model.Task, error)
-	CreateFunc    func(task *model.Task) error
-	UpdateFunc    func(id string, task *model.Task) error
-	DeleteFunc    func(id string) error
-	DeleteAllFunc func() error
+	ListFunc       func(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error)
+	ScanFunc       func(ctx context.Context, access Access, chunk int, fn func(tasks []model.Task) error) error
+	GetFunc        func(ctx context.Context, access Access, id string) (*model.Task, error)
+	CreateFunc     func(ctx context.Context, access Access, task *model.Task) error
+	UpdateFunc     func(ctx context.Context, access Access, id string, task *model.Task) error
+	UpsertFunc     func(ctx context.Context, access Access, task *model.Task) (bool, error)
+	ImportFunc     func(ctx context.Context, access Access, tasks []model.Task, upsert bool) (*ImportResult, error)
+	DeleteFunc     func(ctx context.Context, access Access, id string) error
+	PurgeFunc      func(ctx context.Context, access Access, id string) error
+	TrashFunc      func(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error)
+	RestoreFunc    func(ctx context.Context, access Access, id string) (*model.Task, error)
+	DeleteAllFunc  func(ctx context.Context, access Access) error
+	RestoreAllFunc func(ctx context.Context, access Access) (int, error)
+	ShareFunc      func(ctx context.Context, access Access, id string, share model.Share) error
+	UnshareFunc    func(ctx context.Context, access Access, id, userID string) error
+	SharesFunc     func(ctx context.Context, access Access, id string) ([]model.Share, error)
+	CloseFunc      func() error
+	PingFunc       func(ctx context.Context) error
 }
 
-func (m *MockStorage) List(params PaginationParams) (*PaginationResult, error) {
+func (m *MockStorage) List(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
 	if m.ListFunc != nil {
-		return m.ListFunc(params)
+		return m.ListFunc(ctx, access, params)
 	}
 	return &PaginationResult{
 		Data: []model.Task{},
@@ -31,37 +45,121 @@ func (m *MockStorage) List(params PaginationParams) (*PaginationResult, error) {
 	}, nil
 }
 
-func (m *MockStorage) Get(id string) (*model.Task, error) {
+func (m *MockStorage) Scan(ctx context.Context, access Access, chunk int, fn func(tasks []model.Task) error) error {
+	if m.ScanFunc != nil {
+		return m.ScanFunc(ctx, access, chunk, fn)
+	}
+	return nil
+}
+
+func (m *MockStorage) Get(ctx context.Context, access Access, id string) (*model.Task, error) {
 	if m.GetFunc != nil {
-		return m.GetFunc(id)
+		return m.GetFunc(ctx, access, id)
 	}
 	return nil, nil
 }
 
-func (m *MockStorage) Create(task *model.Task) error {
+func (m *MockStorage) Create(ctx context.Context, access Access, task *model.Task) error {
 	if m.CreateFunc != nil {
-		return m.CreateFunc(task)
+		return m.CreateFunc(ctx, access, task)
 	}
 	return nil
 }
 
-func (m *MockStorage) Update(id string, task *model.Task) error {
+func (m *MockStorage) Update(ctx context.Context, access Access, id string, task *model.Task) error {
 	if m.UpdateFunc != nil {
-		return m.UpdateFunc(id, task)
+		return m.UpdateFunc(ctx, access, id, task)
 	}
 	return nil
 }
 
-func (m *MockStorage) Delete(id string) error {
+func (m *MockStorage) Upsert(ctx context.Context, access Access, task *model.Task) (bool, error) {
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(ctx, access, task)
+	}
+	return true, nil
+}
+
+func (m *MockStorage) Import(ctx context.Context, access Access, tasks []model.Task, upsert bool) (*ImportResult, error) {
+	if m.ImportFunc != nil {
+		return m.ImportFunc(ctx, access, tasks, upsert)
+	}
+	return &ImportResult{Created: len(tasks)}, nil
+}
+
+func (m *MockStorage) Delete(ctx context.Context, access Access, id string) error {
 	if m.DeleteFunc != nil {
-		return m.DeleteFunc(id)
+		return m.DeleteFunc(ctx, access, id)
 	}
 	return nil
 }
 
-func (m *MockStorage) DeleteAll() error {
+func (m *MockStorage) Purge(ctx context.Context, access Access, id string) error {
+	if m.PurgeFunc != nil {
+		return m.PurgeFunc(ctx, access, id)
+	}
+	return nil
+}
+
+func (m *MockStorage) Trash(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
+	if m.TrashFunc != nil {
+		return m.TrashFunc(ctx, access, params)
+	}
+	return &PaginationResult{Data: []model.Task{}, Pagination: PaginationInfo{Page: params.Page, Limit: params.Limit}}, nil
+}
+
+func (m *MockStorage) Restore(ctx context.Context, access Access, id string) (*model.Task, error) {
+	if m.RestoreFunc != nil {
+		return m.RestoreFunc(ctx, access, id)
+	}
+	return &model.Task{ID: id}, nil
+}
+
+func (m *MockStorage) DeleteAll(ctx context.Context, access Access) error {
 	if m.DeleteAllFunc != nil {
-		return m.DeleteAllFunc()
+		return m.DeleteAllFunc(ctx, access)
+	}
+	return nil
+}
+
+func (m *MockStorage) RestoreAll(ctx context.Context, access Access) (int, error) {
+	if m.RestoreAllFunc != nil {
+		return m.RestoreAllFunc(ctx, access)
+	}
+	return 0, nil
+}
+
+func (m *MockStorage) Share(ctx context.Context, access Access, id string, share model.Share) error {
+	if m.ShareFunc != nil {
+		return m.ShareFunc(ctx, access, id, share)
+	}
+	return nil
+}
+
+func (m *MockStorage) Unshare(ctx context.Context, access Access, id, userID string) error {
+	if m.UnshareFunc != nil {
+		return m.UnshareFunc(ctx, access, id, userID)
+	}
+	return nil
+}
+
+func (m *MockStorage) Shares(ctx context.Context, access Access, id string) ([]model.Share, error) {
+	if m.SharesFunc != nil {
+		return m.SharesFunc(ctx, access, id)
+	}
+	return []model.Share{}, nil
+}
+
+func (m *MockStorage) Close() error {
+	if m.CloseFunc != nil {
+		return m.CloseFunc()
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (m *MockStorage) Ping(ctx context.Context) error {
+	if m.PingFunc != nil {
+		return m.PingFunc(ctx)
+	}
+	return nil
+}
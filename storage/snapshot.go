@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+)
+
+// ErrInvalidSnapshot 快照內容無法還原，例如任務 ID 重複或 tenant 設定不符
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// Snapshotter 可建立與還原一致快照的 storage（選擇性實作）
+type Snapshotter interface {
+	// Snapshot 取得目前所有任務（含回收桶與分享設定）的快照
+	Snapshot(ctx context.Context) (*Snapshot, error)
+	// RestoreSnapshot 以快照取代目前所有的任務
+	RestoreSnapshot(ctx context.Context, snap *Snapshot) error
+}
+
+// Snapshot 某個時間點的所有任務
+type Snapshot struct {
+	CreatedAt  time.Time   `json:"created_at"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// Count 回傳快照中的任務數，含回收桶中的任務
+func (s *Snapshot) Count() int {
+	total := 0
+	for _, p := range s.Partitions {
+		total += len(p.Tasks)
+	}
+	return total
+}
+
+// Partition 一個 tenant 的任務；未啟用多租戶時只有一個 Tenant 為空字串的分區
+type Partition struct {
+	Tenant string         `json:"tenant,omitempty"`
+	Tasks  []SnapshotTask `json:"tasks"`
+}
+
+// SnapshotTask 快照中的任務與分享設定，DeletedAt 不為 nil 表示在回收桶中
+type SnapshotTask struct {
+	model.Task
+	Shares []model.Share `json:"shares,omitempty"`
+}
+
+// Snapshot 取得目前資料的快照
+// 只在寫入鎖內標記目前的 slice 與 map 為共用（O(1)），之後第一個寫入操作才複製一份（copy-on-write），
+// 轉換為 Snapshot 的過程不持有鎖
+func (s *MemoryStorage) Snapshot(ctx context.Context) (*Snapshot, error) {
+	p, err := s.snapshot(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{CreatedAt: time.Now().UTC(), Partitions: []Partition{*p}}, nil
+}
+
+func (s *MemoryStorage) snapshot(ctx context.Context, tenant string) (*Partition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.lock()
+	tasks, shares, trash := s.tasks, s.shares, s.trash
+	s.frozen = true
+	s.mu.Unlock()
+
+	p := &Partition{Tenant: tenant, Tasks: make([]SnapshotTask, 0, len(tasks)+len(trash))}
+	for _, task := range tasks {
+		p.Tasks = append(p.Tasks, SnapshotTask{Task: task, Shares: sortedShares(shares[task.ID])})
+	}
+	trashed := make([]SnapshotTask, 0, len(trash))
+	for _, t := range trash {
+		trashed = append(trashed, SnapshotTask{Task: t.task, Shares: sortedShares(t.shares)})
+	}
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].ID < trashed[j].ID
+	})
+	p.Tasks = append(p.Tasks, trashed...)
+	return p, nil
+}
+
+// RestoreSnapshot 以快照取代所有任務，快照需來自未啟用多租戶的 storage
+// 還原的是原本就存在的資料，不受任務數上限限制；DeleteAll 保留的資料一併捨棄
+func (s *MemoryStorage) RestoreSnapshot(ctx context.Context, snap *Snapshot) error {
+	var p Partition
+	switch {
+	case len(snap.Partitions) > 1 || (len(snap.Partitions) == 1 && snap.Partitions[0].Tenant != ""):
+		return fmt.Errorf("%w: snapshot was taken with tenancy enabled", ErrInvalidSnapshot)
+	case len(snap.Partitions) == 1:
+		p = snap.Partitions[0]
+	}
+	return s.restore(ctx, p)
+}
+
+func (s *MemoryStorage) restore(ctx context.Context, p Partition) error {
+	// 先在鎖外建立新的資料與索引，持有寫入鎖的時間只有替換的部分
+	restored := NewMemoryStorage()
+	for _, t := range p.Tasks {
+		if t.ID == "" {
+			return fmt.Errorf("%w: task without id", ErrInvalidSnapshot)
+		}
+		if _, exists := restored.indexMap[t.ID]; exists {
+			return fmt.Errorf("%w: duplicate task %q", ErrInvalidSnapshot, t.ID)
+		}
+		if _, exists := restored.trash[t.ID]; exists {
+			return fmt.Errorf("%w: duplicate task %q", ErrInvalidSnapshot, t.ID)
+		}
+
+		var shares map[string]model.Permission
+		if len(t.Shares) > 0 {
+			shares = make(map[string]model.Permission, len(t.Shares))
+			for _, share := range t.Shares {
+				shares[share.UserID] = share.Permission
+			}
+		}
+		if t.DeletedAt != nil {
+			restored.trash[t.ID] = trashedTask{task: t.Task, shares: shares}
+			continue
+		}
+
+		restored.tasks = append(restored.tasks, t.Task)
+		restored.indexMap[t.ID] = len(restored.tasks) - 1
+		restored.addVisible(t.OwnerID, t.ID)
+		if shares != nil {
+			restored.shares[t.ID] = shares
+			for userID := range shares {
+				restored.addVisible(userID, t.ID)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.lock()
+	defer s.mu.Unlock()
+	s.tasks = restored.tasks
+	s.indexMap = restored.indexMap
+	s.visible = restored.visible
+	s.shares = restored.shares
+	s.trash = restored.trash
+	s.wiped = nil
+	s.frozen = false
+
+	return nil
+}
+
+// thaw 快照建立後第一次寫入時複製共用的 slice 與 map，之後的修改不會影響快照；呼叫前需持有寫入鎖
+// 分享設定的內層 map 也會被修改，需要一併複製
+func (s *MemoryStorage) thaw() {
+	if !s.frozen {
+		return
+	}
+
+	tasks := make([]model.Task, len(s.tasks), cap(s.tasks))
+	copy(tasks, s.tasks)
+	s.tasks = tasks
+
+	shares := make(map[string]map[string]model.Permission, len(s.shares))
+	for id, users := range s.shares {
+		shares[id] = copyShares(users)
+	}
+	s.shares = shares
+
+	trash := make(map[string]trashedTask, len(s.trash))
+	for id, t := range s.trash {
+		trash[id] = trashedTask{task: t.task, shares: copyShares(t.shares)}
+	}
+	s.trash = trash
+
+	s.frozen = false
+}
+
+func copyShares(users map[string]model.Permission) map[string]model.Permission {
+	if users == nil {
+		return nil
+	}
+	copied := make(map[string]model.Permission, len(users))
+	for userID, permission := range users {
+		copied[userID] = permission
+	}
+	return copied
+}
+
+// sortedShares 依使用者 ID 排序轉換分享設定
+func sortedShares(users map[string]model.Permission) []model.Share {
+	if len(users) == 0 {
+		return nil
+	}
+	shares := make([]model.Share, 0, len(users))
+	for userID, permission := range users {
+		shares = append(shares, model.Share{UserID: userID, Permission: permission})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].UserID < shares[j].UserID
+	})
+	return shares
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_Snapshot(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+	bob := Access{UserID: "bob"}
+
+	shared := &model.Task{Name: "Shared Task"}
+	require.NoError(t, storage.Create(ctx, alice, shared))
+	require.NoError(t, storage.Share(ctx, alice, shared.ID, model.Share{UserID: "bob", Permission: model.PermissionRead}))
+	trashed := &model.Task{Name: "Trashed Task"}
+	require.NoError(t, storage.Create(ctx, alice, trashed))
+	require.NoError(t, storage.Share(ctx, alice, trashed.ID, model.Share{UserID: "carol", Permission: model.PermissionWrite}))
+	require.NoError(t, storage.Delete(ctx, alice, trashed.ID))
+
+	snap, err := storage.Snapshot(ctx)
+	require.NoError(t, err)
+	require.Len(t, snap.Partitions, 1)
+	assert.Equal(t, 2, snap.Count())
+
+	// 快照建立後的修改不影響快照
+	require.NoError(t, storage.Update(ctx, alice, shared.ID, &model.Task{Name: "Edited", Status: 1}))
+	require.NoError(t, storage.Share(ctx, alice, shared.ID, model.Share{UserID: "bob", Permission: model.PermissionWrite}))
+	_, err = storage.Restore(ctx, alice, trashed.ID)
+	require.NoError(t, err)
+	require.NoError(t, storage.Share(ctx, alice, trashed.ID, model.Share{UserID: "dave", Permission: model.PermissionRead}))
+	require.NoError(t, storage.Create(ctx, bob, &model.Task{Name: "Created Later"}))
+
+	tasks := snap.Partitions[0].Tasks
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "Shared Task", tasks[0].Name)
+	assert.Equal(t, []model.Share{{UserID: "bob", Permission: model.PermissionRead}}, tasks[0].Shares)
+	assert.Equal(t, "Trashed Task", tasks[1].Name)
+	assert.NotNil(t, tasks[1].DeletedAt)
+	assert.Equal(t, []model.Share{{UserID: "carol", Permission: model.PermissionWrite}}, tasks[1].Shares)
+
+	// 還原後回到快照時的狀態，索引與分享設定一併重建
+	require.NoError(t, storage.RestoreSnapshot(ctx, snap))
+	assert.Equal(t, 1, storage.Count())
+	got, err := storage.Get(ctx, bob, shared.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Shared Task", got.Name)
+	assert.Equal(t, ErrPermissionDenied, storage.Update(ctx, bob, shared.ID, &model.Task{Name: "Edited"}))
+	result, err := storage.List(ctx, bob, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pagination.Total)
+
+	trash, err := storage.Trash(ctx, alice, NewPaginationParams(1))
+	require.NoError(t, err)
+	require.Len(t, trash.Data, 1)
+	assert.Equal(t, trashed.ID, trash.Data[0].ID)
+	restored, err := storage.Restore(ctx, alice, trashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Trashed Task", restored.Name)
+	_, err = storage.Get(ctx, Access{UserID: "carol"}, trashed.ID)
+	require.NoError(t, err)
+	require.NoError(t, storage.Ping(ctx))
+}
+
+func TestMemoryStorage_RestoreSnapshotInvalid(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	require.NoError(t, storage.Create(ctx, allAccess, &model.Task{Name: "Existing"}))
+
+	tests := []struct {
+		name    string
+		snap    *Snapshot
+		wantErr string
+	}{
+		{
+			name:    "來自多租戶的快照",
+			snap:    &Snapshot{Partitions: []Partition{{Tenant: "acme"}}},
+			wantErr: "invalid snapshot: snapshot was taken with tenancy enabled",
+		},
+		{
+			name:    "任務 ID 重複",
+			snap:    &Snapshot{Partitions: []Partition{{Tasks: []SnapshotTask{{Task: model.Task{ID: "1"}}, {Task: model.Task{ID: "1"}}}}}},
+			wantErr: `invalid snapshot: duplicate task "1"`,
+		},
+		{
+			name:    "任務沒有 ID",
+			snap:    &Snapshot{Partitions: []Partition{{Tasks: []SnapshotTask{{Task: model.Task{Name: "No ID"}}}}}},
+			wantErr: "invalid snapshot: task without id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := storage.RestoreSnapshot(ctx, tt.snap)
+			assert.ErrorIs(t, err, ErrInvalidSnapshot)
+			assert.EqualError(t, err, tt.wantErr)
+			// 無法還原時不修改現有資料
+			assert.Equal(t, 1, storage.Count())
+		})
+	}
+}
+
+func TestTenantStorage_Snapshot(t *testing.T) {
+	storage := NewTenantStorage(0, nil)
+	ctx := context.Background()
+	acme := Access{Tenant: "acme", All: true}
+	globex := Access{Tenant: "globex", All: true}
+
+	acmeTask := &model.Task{Name: "Acme Task"}
+	require.NoError(t, storage.Create(ctx, acme, acmeTask))
+	require.NoError(t, storage.Create(ctx, globex, &model.Task{Name: "Globex Task"}))
+
+	snap, err := storage.Snapshot(ctx)
+	require.NoError(t, err)
+	require.Len(t, snap.Partitions, 2)
+	assert.Equal(t, "acme", snap.Partitions[0].Tenant)
+	assert.Equal(t, "globex", snap.Partitions[1].Tenant)
+
+	// 快照之後才建立的 tenant 在還原後清空
+	require.NoError(t, storage.Create(ctx, Access{Tenant: "initech", All: true}, &model.Task{Name: "Initech Task"}))
+	require.NoError(t, storage.Purge(ctx, acme, acmeTask.ID))
+
+	require.NoError(t, storage.RestoreSnapshot(ctx, snap))
+	assert.Equal(t, []string{"acme", "globex"}, storage.Tenants())
+	got, err := storage.Get(ctx, acme, acmeTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Task", got.Name)
+	_, err = storage.Get(ctx, globex, acmeTask.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+
+	err = storage.RestoreSnapshot(ctx, &Snapshot{Partitions: []Partition{{}}})
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	assert.Equal(t, 2, storage.Count())
+}
+
+func TestMemoryStorage_SnapshotRace(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx := context.Background()
+	alice := Access{UserID: "alice"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				task := &model.Task{Name: fmt.Sprintf("Task %d-%d", worker, j)}
+				if err := storage.Create(ctx, alice, task); err != nil {
+					t.Error(err)
+					return
+				}
+				_ = storage.Share(ctx, alice, task.ID, model.Share{UserID: "bob", Permission: model.PermissionRead})
+				if j%3 == 0 {
+					_ = storage.Delete(ctx, alice, task.ID)
+				}
+			}
+		}(i)
+	}
+
+	// 寫入期間反覆建立快照，每份快照的任務都不會重複
+	for i := 0; i < 20; i++ {
+		snap, err := storage.Snapshot(ctx)
+		require.NoError(t, err)
+		seen := make(map[string]bool)
+		for _, task := range snap.Partitions[0].Tasks {
+			assert.False(t, seen[task.ID], "duplicate task %s", task.ID)
+			seen[task.ID] = true
+		}
+	}
+	wg.Wait()
+
+	snap, err := storage.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 200, snap.Count())
+}
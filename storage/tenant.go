@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogolook/task-api/logging"
+	"github.com/gogolook/task-api/model"
+)
+
+// TenantStorage 依 Access.Tenant 將任務分到各自獨立的 MemoryStorage
+// 每個 tenant 有自己的 slice 與索引，列表的分頁總數只會計算該 tenant 的任務
+type TenantStorage struct {
+	mu         sync.RWMutex
+	partitions map[string]*MemoryStorage
+	// empty 尚未有資料的 tenant 讀取時使用，避免任意 tenant 名稱都建立分區
+	empty        *MemoryStorage
+	defaultQuota int
+	quotas       map[string]int
+	observeLock  LockObserver
+}
+
+// NewTenantStorage 建立分區 storage
+// defaultQuota 為每個 tenant 的任務數上限，quotas 可針對個別 tenant 覆寫，0 表示不限制
+func NewTenantStorage(defaultQuota int, quotas map[string]int) *TenantStorage {
+	copied := make(map[string]int, len(quotas))
+	for tenant, quota := range quotas {
+		copied[tenant] = quota
+	}
+	return &TenantStorage{
+		partitions:   make(map[string]*MemoryStorage),
+		empty:        NewMemoryStorage(),
+		defaultQuota: defaultQuota,
+		quotas:       copied,
+	}
+}
+
+// Tenants 依名稱排序列出已有資料分區的 tenant
+func (s *TenantStorage) Tenants() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenants := make([]string, 0, len(s.partitions))
+	for tenant := range s.partitions {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Quota 回傳 tenant 的任務數上限
+func (s *TenantStorage) Quota(tenant string) int {
+	if quota, ok := s.quotas[tenant]; ok {
+		return quota
+	}
+	return s.defaultQuota
+}
+
+// partition 取得 tenant 的分區；不存在時只有寫入操作（create）會建立，其餘回傳空的分區
+func (s *TenantStorage) partition(ctx context.Context, tenant string, create bool) (*MemoryStorage, error) {
+	if tenant == "" {
+		return nil, ErrTenantRequired
+	}
+
+	s.mu.RLock()
+	p, ok := s.partitions[tenant]
+	s.mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+	if !create {
+		return s.empty, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.partitions[tenant]; ok {
+		return p, nil
+	}
+	p = NewMemoryStorage()
+	p.SetMaxTasks(s.Quota(tenant))
+	p.SetLockObserver(s.observeLock)
+	s.partitions[tenant] = p
+	logging.FromContext(ctx).Info("created tenant partition", "tenant", tenant, "max_tasks", s.Quota(tenant))
+	return p, nil
+}
+
+func (s *TenantStorage) List(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.List(ctx, access, params)
+}
+
+func (s *TenantStorage) Scan(ctx context.Context, access Access, chunk int, fn func(tasks []model.Task) error) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Scan(ctx, access, chunk, fn)
+}
+
+func (s *TenantStorage) Get(ctx context.Context, access Access, id string) (*model.Task, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(ctx, access, id)
+}
+
+func (s *TenantStorage) Create(ctx context.Context, access Access, task *model.Task) error {
+	p, err := s.partition(ctx, access.Tenant, true)
+	if err != nil {
+		return err
+	}
+	return p.Create(ctx, access, task)
+}
+
+func (s *TenantStorage) Update(ctx context.Context, access Access, id string, task *model.Task) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Update(ctx, access, id, task)
+}
+
+func (s *TenantStorage) Upsert(ctx context.Context, access Access, task *model.Task) (bool, error) {
+	p, err := s.partition(ctx, access.Tenant, true)
+	if err != nil {
+		return false, err
+	}
+	return p.Upsert(ctx, access, task)
+}
+
+func (s *TenantStorage) Import(ctx context.Context, access Access, tasks []model.Task, upsert bool) (*ImportResult, error) {
+	p, err := s.partition(ctx, access.Tenant, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.Import(ctx, access, tasks, upsert)
+}
+
+func (s *TenantStorage) Delete(ctx context.Context, access Access, id string) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Delete(ctx, access, id)
+}
+
+func (s *TenantStorage) Purge(ctx context.Context, access Access, id string) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Purge(ctx, access, id)
+}
+
+func (s *TenantStorage) Trash(ctx context.Context, access Access, params PaginationParams) (*PaginationResult, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.Trash(ctx, access, params)
+}
+
+func (s *TenantStorage) Restore(ctx context.Context, access Access, id string) (*model.Task, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.Restore(ctx, access, id)
+}
+
+// DeleteAll 只清空 access.Tenant 的分區
+func (s *TenantStorage) DeleteAll(ctx context.Context, access Access) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.DeleteAll(ctx, access)
+}
+
+// RestoreAll 只還原 access.Tenant 的分區
+func (s *TenantStorage) RestoreAll(ctx context.Context, access Access) (int, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return 0, err
+	}
+	return p.RestoreAll(ctx, access)
+}
+
+func (s *TenantStorage) Share(ctx context.Context, access Access, id string, share model.Share) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Share(ctx, access, id, share)
+}
+
+func (s *TenantStorage) Unshare(ctx context.Context, access Access, id, userID string) error {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return err
+	}
+	return p.Unshare(ctx, access, id, userID)
+}
+
+func (s *TenantStorage) Shares(ctx context.Context, access Access, id string) ([]model.Share, error) {
+	p, err := s.partition(ctx, access.Tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.Shares(ctx, access, id)
+}
+
+// Ping 檢查所有 tenant 的分區
+func (s *TenantStorage) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	partitions := make(map[string]*MemoryStorage, len(s.partitions))
+	for tenant, p := range s.partitions {
+		partitions[tenant] = p
+	}
+	s.mu.RUnlock()
+
+	for tenant, p := range partitions {
+		if err := p.Ping(ctx); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+// PurgeTrash 清除所有 tenant 回收桶中過期的任務
+func (s *TenantStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	s.mu.RLock()
+	partitions := make([]*MemoryStorage, 0, len(s.partitions))
+	for _, p := range s.partitions {
+		partitions = append(partitions, p)
+	}
+	s.mu.RUnlock()
+
+	total := 0
+	for _, p := range partitions {
+		purged, err := p.PurgeTrash(ctx, before)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+	}
+	return total, nil
+}
+
+// Snapshot 依 tenant 名稱排序取得所有分區的快照
+// 各分區分別在自己的鎖內取得，分區之間不是同一個時間點
+func (s *TenantStorage) Snapshot(ctx context.Context) (*Snapshot, error) {
+	snap := &Snapshot{CreatedAt: time.Now().UTC(), Partitions: []Partition{}}
+	for _, tenant := range s.Tenants() {
+		s.mu.RLock()
+		p := s.partitions[tenant]
+		s.mu.RUnlock()
+
+		partition, err := p.snapshot(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+		snap.Partitions = append(snap.Partitions, *partition)
+	}
+	return snap, nil
+}
+
+// RestoreSnapshot 以快照取代所有 tenant 的分區，快照中沒有的 tenant 會被清空
+func (s *TenantStorage) RestoreSnapshot(ctx context.Context, snap *Snapshot) error {
+	partitions := make(map[string]*MemoryStorage, len(snap.Partitions))
+	for _, partition := range snap.Partitions {
+		if partition.Tenant == "" {
+			return fmt.Errorf("%w: snapshot was taken with tenancy disabled", ErrInvalidSnapshot)
+		}
+		if _, exists := partitions[partition.Tenant]; exists {
+			return fmt.Errorf("%w: duplicate tenant %q", ErrInvalidSnapshot, partition.Tenant)
+		}
+		p := NewMemoryStorage()
+		p.SetMaxTasks(s.Quota(partition.Tenant))
+		if err := p.restore(ctx, partition); err != nil {
+			return fmt.Errorf("tenant %s: %w", partition.Tenant, err)
+		}
+		partitions[partition.Tenant] = p
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range partitions {
+		p.SetLockObserver(s.observeLock)
+	}
+	s.partitions = partitions
+	return nil
+}
+
+// Count 回傳所有 tenant 的任務總數
+func (s *TenantStorage) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, p := range s.partitions {
+		total += p.Count()
+	}
+	return total
+}
+
+// SetLockObserver 設定所有分區（含之後建立的分區）的鎖等待時間觀察者
+func (s *TenantStorage) SetLockObserver(fn LockObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.observeLock = fn
+	for _, p := range s.partitions {
+		p.SetLockObserver(fn)
+	}
+}
+
+// Close 關閉所有 tenant 的分區，回傳所有失敗的錯誤
+func (s *TenantStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for tenant, p := range s.partitions {
+		if err := p.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant, err))
+		}
+	}
+	return errors.Join(errs...)
+}
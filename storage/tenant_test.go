@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantStorage_Isolation(t *testing.T) {
+	storage := NewTenantStorage(0, nil)
+	acme := Access{Tenant: "acme", All: true}
+	globex := Access{Tenant: "globex", All: true}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, storage.Create(context.Background(), acme, &model.Task{Name: "Acme Task"}))
+	}
+	globexTask := &model.Task{Name: "Globex Task"}
+	require.NoError(t, storage.Create(context.Background(), globex, globexTask))
+
+	// 分頁總數只計算自己 tenant 的任務
+	result, err := storage.List(context.Background(), globex, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, []model.Task{*globexTask}, result.Data)
+	assert.Equal(t, 1, result.Pagination.Total)
+
+	result, err = storage.List(context.Background(), acme, PaginationParams{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, 3, result.Pagination.Total)
+
+	// 其他 tenant 的任務視為不存在
+	_, err = storage.Get(context.Background(), acme, globexTask.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+	assert.Equal(t, ErrTaskNotFound, storage.Delete(context.Background(), acme, globexTask.ID))
+	_, err = storage.Upsert(context.Background(), acme, &model.Task{ID: globexTask.ID, Name: "Copy"})
+	require.NoError(t, err)
+
+	// DeleteAll 只清空一個 tenant
+	require.NoError(t, storage.DeleteAll(context.Background(), acme))
+	result, err = storage.List(context.Background(), acme, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Pagination.Total)
+
+	got, err := storage.Get(context.Background(), globex, globexTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Globex Task", got.Name)
+
+	// 其他 tenant 沒有可還原的任務
+	_, err = storage.RestoreAll(context.Background(), globex)
+	assert.Equal(t, ErrNoSnapshot, err)
+	restored, err := storage.RestoreAll(context.Background(), acme)
+	require.NoError(t, err)
+	assert.Equal(t, 4, restored)
+
+	// 回收桶也依 tenant 分開，PurgeTrash 清除所有 tenant
+	require.NoError(t, storage.Delete(context.Background(), globex, globexTask.ID))
+	trash, err := storage.Trash(context.Background(), acme, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, trash.Data)
+	_, err = storage.Restore(context.Background(), acme, globexTask.ID)
+	assert.Equal(t, ErrTaskNotFound, err)
+	purged, err := storage.PurgeTrash(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	assert.Equal(t, []string{"acme", "globex"}, storage.Tenants())
+}
+
+func TestTenantStorage_UnknownTenant(t *testing.T) {
+	ctx := context.Background()
+	acme := Access{Tenant: "acme", All: true}
+
+	// 讀取沒有資料的 tenant 不建立分區，也不影響其他 TenantStorage
+	first := NewTenantStorage(0, nil)
+	require.NoError(t, first.Scan(ctx, acme, 10, func(tasks []model.Task) error {
+		t.Fatal("unexpected tasks")
+		return nil
+	}))
+	assert.Empty(t, first.Tenants())
+	assert.NotSame(t, first.empty, NewTenantStorage(0, nil).empty)
+
+	require.NoError(t, first.Create(ctx, acme, &model.Task{Name: "Acme Task"}))
+	result, err := first.List(ctx, acme, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Zero(t, first.empty.Count())
+}
+
+func TestTenantStorage_Quota(t *testing.T) {
+	storage := NewTenantStorage(1, map[string]int{"acme": 2})
+
+	acme := Access{Tenant: "acme", All: true}
+	require.NoError(t, storage.Create(context.Background(), acme, &model.Task{Name: "Task 1"}))
+	require.NoError(t, storage.Create(context.Background(), acme, &model.Task{Name: "Task 2"}))
+	assert.Equal(t, ErrQuotaExceeded, storage.Create(context.Background(), acme, &model.Task{Name: "Task 3"}))
+
+	globex := Access{Tenant: "globex", All: true}
+	require.NoError(t, storage.Create(context.Background(), globex, &model.Task{Name: "Task 1"}))
+	assert.Equal(t, ErrQuotaExceeded, storage.Create(context.Background(), globex, &model.Task{Name: "Task 2"}))
+}
+
+func TestTenantStorage_RequiresTenant(t *testing.T) {
+	storage := NewTenantStorage(0, nil)
+
+	_, err := storage.List(context.Background(), allAccess, NewPaginationParams(1))
+	assert.Equal(t, ErrTenantRequired, err)
+	assert.Equal(t, ErrTenantRequired, storage.Create(context.Background(), allAccess, &model.Task{Name: "Task"}))
+
+	// 讀取尚未有資料的 tenant 不會建立分區
+	result, err := storage.List(context.Background(), Access{Tenant: "unknown", All: true}, NewPaginationParams(1))
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Empty(t, storage.Tenants())
+}
+
+func TestTenantStorage_Close(t *testing.T) {
+	storage := NewTenantStorage(0, nil)
+	require.NoError(t, storage.Create(context.Background(), Access{Tenant: "acme", All: true}, &model.Task{Name: "Task"}))
+	require.NoError(t, storage.Create(context.Background(), Access{Tenant: "globex", All: true}, &model.Task{Name: "Task"}))
+
+	assert.NoError(t, storage.Close())
+}
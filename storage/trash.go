@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogolook/task-api/logging"
+)
+
+// RunPurger 每隔 interval 永久刪除在回收桶中超過 retention 的任務，直到 ctx 結束
+func RunPurger(ctx context.Context, p TrashPurger, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := logging.FromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := p.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Error("failed to purge trash", "error", err)
+			continue
+		}
+		if purged > 0 {
+			logger.Info("purged trash", "count", purged, "retention", retention.String())
+		}
+	}
+}
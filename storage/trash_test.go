@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPurger(t *testing.T) {
+	storage := NewMemoryStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	task := &model.Task{Name: "Deleted Task"}
+	require.NoError(t, storage.Create(ctx, allAccess, task))
+	require.NoError(t, storage.Delete(ctx, allAccess, task.ID))
+
+	done := make(chan struct{})
+	go func() {
+		RunPurger(ctx, storage, time.Millisecond, 5*time.Millisecond)
+		close(done)
+	}()
+
+	// 超過保留期限的任務會被永久刪除
+	assert.Eventually(t, func() bool {
+		trash, err := storage.Trash(context.Background(), allAccess, NewPaginationParams(1))
+		return err == nil && len(trash.Data) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunPurger did not stop after the context was canceled")
+	}
+}
@@ -0,0 +1,145 @@
+// Package tenant 從請求解析 tenant，讓 storage 將各團隊的資料分開保存
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/gogolook/task-api/problem"
+)
+
+// tenant ID 只允許小寫英數字與 "-"，可直接作為子網域
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Valid 回傳 id 是否為合法的 tenant ID
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// Resolver 從請求解析 tenant ID，解析不到時回傳空字串
+type Resolver func(r *http.Request) string
+
+// Header 從指定的 header 取得 tenant，例如 X-Tenant-ID
+func Header(name string) Resolver {
+	return func(r *http.Request) string {
+		return strings.ToLower(strings.TrimSpace(r.Header.Get(name)))
+	}
+}
+
+// Subdomain 從 Host 的子網域取得 tenant，例如 baseDomain 為 "tasks.example.com" 時，
+// "acme.tasks.example.com" 解析為 "acme"
+func Subdomain(baseDomain string) Resolver {
+	suffix := "." + strings.ToLower(strings.TrimPrefix(baseDomain, "."))
+	return func(r *http.Request) string {
+		host := strings.ToLower(r.Host)
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// 與 auth 相同，gin context 與 request context 共用
+const tenantKey = "tenant.id"
+
+type contextKey struct{}
+
+// WithTenant 將 tenant 放入 context
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 從 context 取出 tenant，未啟用多租戶時回傳空字串
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// From 從 gin context 取出 tenant，未啟用多租戶時回傳空字串
+func From(c *gin.Context) string {
+	if v, ok := c.Get(tenantKey); ok {
+		if id, ok := v.(string); ok {
+			return id
+		}
+	}
+	return FromContext(c.Request.Context())
+}
+
+// Middleware 決定請求所屬的 tenant，需放在 auth.Middleware 之後
+// 呼叫者的憑證帶有 tenant（API key 或 JWT claim）時以憑證為準，resolvers 解析出不同的 tenant 回傳 403；
+// 憑證沒有 tenant 時依序使用 resolvers 的結果，都解析不到回傳 400；只有 admin 憑證可以不限定 tenant，其他憑證回傳 403
+// 停用驗證（沒有 principal）時直接使用 resolvers 的結果
+func Middleware(resolvers ...Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var pinned string
+		p := auth.PrincipalFrom(c)
+		if p != nil {
+			pinned = p.Tenant
+		}
+
+		id := pinned
+		for _, resolve := range resolvers {
+			v := resolve(c.Request)
+			if v == "" {
+				continue
+			}
+			if pinned != "" && v != pinned {
+				problem.Abort(c, problem.New(http.StatusForbidden, "tenant_mismatch", "credentials are not valid for tenant "+v))
+				return
+			}
+			if id == "" {
+				id = v
+			}
+		}
+
+		if id == "" {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "tenant_required", "tenant is required"))
+			return
+		}
+		if !Valid(id) {
+			problem.Abort(c, problem.New(http.StatusBadRequest, "invalid_tenant", "invalid tenant"))
+			return
+		}
+		if p != nil && pinned == "" && !p.Scope.Includes(auth.ScopeAdmin) {
+			problem.Abort(c, problem.New(http.StatusForbidden, "tenant_mismatch", "credentials are not valid for tenant "+id))
+			return
+		}
+
+		c.Set(tenantKey, id)
+		c.Request = c.Request.WithContext(WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// ParseQuotas 解析 "acme=1000,globex=500" 格式的 tenant 任務數上限
+func ParseQuotas(s string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(item, "=")
+		id = strings.TrimSpace(id)
+		if !ok || !Valid(id) {
+			return nil, fmt.Errorf("invalid tenant quota %q", item)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid tenant quota %q", item)
+		}
+		quotas[id] = n
+	}
+	return quotas, nil
+}
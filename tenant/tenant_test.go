@@ -0,0 +1,150 @@
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// 以 X-Principal-Scope 與 X-Principal-Tenant 模擬憑證的 scope 與 tenant
+		if scope := c.GetHeader("X-Principal-Scope"); scope != "" {
+			p := &auth.Principal{ID: "user-1", Scope: auth.Scope(scope), Tenant: c.GetHeader("X-Principal-Tenant")}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+		c.Next()
+	}, Middleware(Header("X-Tenant-ID"), Subdomain("tasks.example.com")))
+	router.GET("/tasks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant": From(c), "context": FromContext(c.Request.Context())})
+	})
+
+	tests := []struct {
+		name           string
+		host           string
+		header         string
+		scope          auth.Scope
+		pinned         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "header",
+			header:         "Acme",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"acme","context":"acme"}`,
+		},
+		{
+			name:           "子網域",
+			host:           "globex.tasks.example.com:8080",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"globex","context":"globex"}`,
+		},
+		{
+			name:           "header 優先於子網域",
+			host:           "globex.tasks.example.com",
+			header:         "acme",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"acme","context":"acme"}`,
+		},
+		{
+			name:           "憑證中的 tenant",
+			scope:          auth.ScopeWrite,
+			pinned:         "initech",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"initech","context":"initech"}`,
+		},
+		{
+			name:           "憑證與 header 相同",
+			header:         "initech",
+			scope:          auth.ScopeWrite,
+			pinned:         "initech",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"initech","context":"initech"}`,
+		},
+		{
+			name:           "憑證不能存取其他 tenant",
+			header:         "acme",
+			scope:          auth.ScopeWrite,
+			pinned:         "initech",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/tenant_mismatch","code":"tenant_mismatch","title":"Forbidden","status":403,"detail":"credentials are not valid for tenant acme","error":"credentials are not valid for tenant acme"}`,
+		},
+		{
+			name:           "不限定 tenant 的 admin 憑證",
+			header:         "acme",
+			scope:          auth.ScopeAdmin,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"tenant":"acme","context":"acme"}`,
+		},
+		{
+			name:           "不限定 tenant 的 write 憑證不能指定 tenant",
+			header:         "acme",
+			scope:          auth.ScopeWrite,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/tenant_mismatch","code":"tenant_mismatch","title":"Forbidden","status":403,"detail":"credentials are not valid for tenant acme","error":"credentials are not valid for tenant acme"}`,
+		},
+		{
+			name:           "沒有 tenant",
+			host:           "tasks.example.com",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/tenant_required","code":"tenant_required","title":"Bad Request","status":400,"detail":"tenant is required","error":"tenant is required"}`,
+		},
+		{
+			name:           "不合法的 tenant",
+			header:         "acme_corp",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/invalid_tenant","code":"invalid_tenant","title":"Bad Request","status":400,"detail":"invalid tenant","error":"invalid tenant"}`,
+		},
+		{
+			name:           "多層子網域不視為 tenant",
+			host:           "a.b.tasks.example.com",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"https://task-api.etrex.tw/problems/tenant_required","code":"tenant_required","title":"Bad Request","status":400,"detail":"tenant is required","error":"tenant is required"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.header != "" {
+				req.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if tt.scope != "" {
+				req.Header.Set("X-Principal-Scope", string(tt.scope))
+				req.Header.Set("X-Principal-Tenant", tt.pinned)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestParseQuotas(t *testing.T) {
+	quotas, err := ParseQuotas("acme=1000, globex = 500,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"acme": 1000, "globex": 500}, quotas)
+
+	quotas, err = ParseQuotas("")
+	require.NoError(t, err)
+	assert.Empty(t, quotas)
+
+	for _, invalid := range []string{"acme", "acme=-1", "acme=many", "Acme Corp=10"} {
+		_, err := ParseQuotas(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,183 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentStorage 以為每個操作建立子 span 的 decorator 包裝 s
+func InstrumentStorage(s storage.Storage) storage.Storage {
+	return &tracedStorage{next: s, tracer: otel.Tracer(instrumentation)}
+}
+
+type tracedStorage struct {
+	next   storage.Storage
+	tracer trace.Tracer
+}
+
+// start 建立 storage 操作的 span，attrs 為操作相關的屬性（例如任務 ID）
+func (s *tracedStorage) start(ctx context.Context, operation string, access storage.Access, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("storage.operation", operation))
+	if access.Tenant != "" {
+		attrs = append(attrs, attribute.String("tenant", access.Tenant))
+	}
+	return s.tracer.Start(ctx, "storage."+operation, trace.WithAttributes(attrs...))
+}
+
+// end 記錄錯誤並結束 span；找不到任務與權限不足是正常的結果，不標記為錯誤
+func end(span trace.Span, err error) {
+	defer span.End()
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	if errors.Is(err, storage.ErrTaskNotFound) || errors.Is(err, storage.ErrShareNotFound) || errors.Is(err, storage.ErrPermissionDenied) {
+		return
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func taskID(id string) attribute.KeyValue {
+	return attribute.String("task.id", id)
+}
+
+func (s *tracedStorage) List(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+	ctx, span := s.start(ctx, "List", access, attribute.Int("page", params.Page), attribute.Int("limit", params.Limit))
+	result, err := s.next.List(ctx, access, params)
+	if err == nil {
+		span.SetAttributes(attribute.Int("result.count", len(result.Data)))
+	}
+	end(span, err)
+	return result, err
+}
+
+func (s *tracedStorage) Scan(ctx context.Context, access storage.Access, chunk int, fn func(tasks []model.Task) error) error {
+	ctx, span := s.start(ctx, "Scan", access, attribute.Int("chunk", chunk))
+	count := 0
+	err := s.next.Scan(ctx, access, chunk, func(tasks []model.Task) error {
+		count += len(tasks)
+		return fn(tasks)
+	})
+	span.SetAttributes(attribute.Int("result.count", count))
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Get(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+	ctx, span := s.start(ctx, "Get", access, taskID(id))
+	task, err := s.next.Get(ctx, access, id)
+	end(span, err)
+	return task, err
+}
+
+func (s *tracedStorage) Create(ctx context.Context, access storage.Access, task *model.Task) error {
+	ctx, span := s.start(ctx, "Create", access)
+	err := s.next.Create(ctx, access, task)
+	if err == nil {
+		span.SetAttributes(taskID(task.ID))
+	}
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Update(ctx context.Context, access storage.Access, id string, task *model.Task) error {
+	ctx, span := s.start(ctx, "Update", access, taskID(id))
+	err := s.next.Update(ctx, access, id, task)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Upsert(ctx context.Context, access storage.Access, task *model.Task) (bool, error) {
+	ctx, span := s.start(ctx, "Upsert", access, taskID(task.ID))
+	created, err := s.next.Upsert(ctx, access, task)
+	span.SetAttributes(attribute.Bool("created", created))
+	end(span, err)
+	return created, err
+}
+
+func (s *tracedStorage) Import(ctx context.Context, access storage.Access, tasks []model.Task, upsert bool) (*storage.ImportResult, error) {
+	ctx, span := s.start(ctx, "Import", access, attribute.Int("task.count", len(tasks)), attribute.Bool("upsert", upsert))
+	result, err := s.next.Import(ctx, access, tasks, upsert)
+	if err == nil {
+		span.SetAttributes(attribute.Int("result.created", result.Created), attribute.Int("result.updated", result.Updated))
+	}
+	end(span, err)
+	return result, err
+}
+
+func (s *tracedStorage) Delete(ctx context.Context, access storage.Access, id string) error {
+	ctx, span := s.start(ctx, "Delete", access, taskID(id))
+	err := s.next.Delete(ctx, access, id)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Purge(ctx context.Context, access storage.Access, id string) error {
+	ctx, span := s.start(ctx, "Purge", access, taskID(id))
+	err := s.next.Purge(ctx, access, id)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Trash(ctx context.Context, access storage.Access, params storage.PaginationParams) (*storage.PaginationResult, error) {
+	ctx, span := s.start(ctx, "Trash", access, attribute.Int("page", params.Page), attribute.Int("limit", params.Limit))
+	result, err := s.next.Trash(ctx, access, params)
+	if err == nil {
+		span.SetAttributes(attribute.Int("result.count", len(result.Data)))
+	}
+	end(span, err)
+	return result, err
+}
+
+func (s *tracedStorage) Restore(ctx context.Context, access storage.Access, id string) (*model.Task, error) {
+	ctx, span := s.start(ctx, "Restore", access, taskID(id))
+	task, err := s.next.Restore(ctx, access, id)
+	end(span, err)
+	return task, err
+}
+
+func (s *tracedStorage) DeleteAll(ctx context.Context, access storage.Access) error {
+	ctx, span := s.start(ctx, "DeleteAll", access)
+	err := s.next.DeleteAll(ctx, access)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) RestoreAll(ctx context.Context, access storage.Access) (int, error) {
+	ctx, span := s.start(ctx, "RestoreAll", access)
+	restored, err := s.next.RestoreAll(ctx, access)
+	end(span, err)
+	return restored, err
+}
+
+func (s *tracedStorage) Share(ctx context.Context, access storage.Access, id string, share model.Share) error {
+	ctx, span := s.start(ctx, "Share", access, taskID(id))
+	err := s.next.Share(ctx, access, id, share)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Unshare(ctx context.Context, access storage.Access, id, userID string) error {
+	ctx, span := s.start(ctx, "Unshare", access, taskID(id))
+	err := s.next.Unshare(ctx, access, id, userID)
+	end(span, err)
+	return err
+}
+
+func (s *tracedStorage) Shares(ctx context.Context, access storage.Access, id string) ([]model.Share, error) {
+	ctx, span := s.start(ctx, "Shares", access, taskID(id))
+	shares, err := s.next.Shares(ctx, access, id)
+	end(span, err)
+	return shares, err
+}
+
+func (s *tracedStorage) Close() error {
+	return s.next.Close()
+}
@@ -0,0 +1,86 @@
+// Package tracing 以 OpenTelemetry 記錄每個請求與 storage 操作的 span
+// 支援 W3C traceparent 傳遞，span 可輸出到 OTLP collector 或 stdout
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// instrumentation 建立 tracer 使用的名稱
+const instrumentation = "github.com/gogolook/task-api/tracing"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ErrUnknownExporter exporter 不是 none、stdout 或 otlp
+var ErrUnknownExporter = errors.New("exporter must be none, stdout or otlp")
+
+// Options tracing 的設定
+type Options struct {
+	// Exporter span 的輸出方式：none、stdout（本機開發）或 otlp
+	Exporter string
+	// Endpoint OTLP/HTTP collector 的網址，例如 http://localhost:4318；空字串時使用 OTEL_EXPORTER_OTLP_ENDPOINT 或 SDK 預設值
+	Endpoint string
+	// ServiceName 回報的 service.name
+	ServiceName string
+	// SampleRatio 沒有上游取樣決定時的取樣比例，0 到 1
+	SampleRatio float64
+}
+
+// Setup 依 opts 建立 TracerProvider 並設為全域，同時設定 W3C trace context 與 baggage 的傳遞方式
+// 回傳的函式會 flush 尚未送出的 span，應在服務關閉時呼叫
+// Exporter 為 none 時不記錄 span，但仍會傳遞上游的 traceparent
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName))),
+		// 上游已決定取樣時沿用，確保同一個 trace 的 span 不會只記錄一部分
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newExporter 依 opts.Exporter 建立 exporter，none 時回傳 nil
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+		return exporter, nil
+	}
+	return nil, ErrUnknownExporter
+}
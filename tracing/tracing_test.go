@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gogolook/task-api/model"
+	"github.com/gogolook/task-api/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans 將全域 TracerProvider 換成記錄到記憶體的 provider，測試結束時還原
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+
+	gin.SetMode(gin.TestMode)
+	s := InstrumentStorage(storage.NewMemoryStorage())
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/tasks/:id", func(c *gin.Context) {
+		_, err := s.Get(c.Request.Context(), storage.Access{All: true}, c.Param("id"))
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/missing", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	storageSpan, rootSpan := spans[0], spans[1]
+
+	// root span 接在上游的 trace 之下
+	assert.Equal(t, "GET /tasks/:id", rootSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rootSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", rootSpan.Parent().SpanID().String())
+	assert.Equal(t, codes.Unset, rootSpan.Status().Code)
+
+	// storage span 是 root span 的子 span，找不到任務不標記為錯誤
+	assert.Equal(t, "storage.Get", storageSpan.Name())
+	assert.Equal(t, rootSpan.SpanContext().SpanID(), storageSpan.Parent().SpanID())
+	assert.Equal(t, codes.Unset, storageSpan.Status().Code)
+	require.Len(t, storageSpan.Events(), 1)
+	assert.Equal(t, "exception", storageSpan.Events()[0].Name)
+}
+
+func TestMiddleware_ServerError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-such-route", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "GET unmatched", spans[1].Name())
+}
+
+func TestInstrumentStorage(t *testing.T) {
+	recorder := recordSpans(t)
+	s := InstrumentStorage(storage.NewMemoryStorage())
+	access := storage.Access{Tenant: "acme", All: true}
+
+	task := &model.Task{Name: "Task"}
+	require.NoError(t, s.Create(context.Background(), access, task))
+	_, err := s.List(context.Background(), access, storage.NewPaginationParams(1))
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "storage.Create", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), taskID(task.ID))
+	assert.Equal(t, "storage.List", spans[1].Name())
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name        string
+		exporter    string
+		expectedErr error
+	}{
+		{name: "不輸出", exporter: ExporterNone},
+		{name: "未設定視為不輸出", exporter: ""},
+		{name: "stdout", exporter: ExporterStdout},
+		{name: "不支援的 exporter", exporter: "zipkin", expectedErr: ErrUnknownExporter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := otel.GetTracerProvider()
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			shutdown, err := Setup(context.Background(), Options{Exporter: tt.exporter, ServiceName: "task-api", SampleRatio: 1})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gogolook/task-api/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxNameLength 任務名稱的字元數上限（以 Unicode code point 計算）
+const MaxNameLength = 200
+
+// taskFields 任務請求可以帶的欄位
+var taskFields = []string{"name", "status", "due"}
+
+// Task 驗證任務欄位並寫入 task，回傳所有欄位錯誤
+// allow 為呼叫端另外處理、不算未知欄位的欄位，例如匯入時的 id
+// 名稱會正規化為 NFC，相同外觀的字串以相同的位元組儲存
+func Task(raw map[string]interface{}, task *model.Task, allow ...string) error {
+	var errs Errors
+
+	if name, ok := taskName(raw, &errs); ok {
+		task.Name = name
+	}
+	if status, ok := taskStatus(raw, &errs); ok {
+		task.Status = status
+	}
+	if due, ok := taskDue(raw, &errs); ok {
+		task.Due = due
+	}
+	unknownFields(raw, allow, &errs)
+
+	return errs.Err()
+}
+
+func taskName(raw map[string]interface{}, errs *Errors) (string, bool) {
+	value, exists := raw["name"]
+	if !exists {
+		errs.Add("name", CodeRequired, "name is required")
+		return "", false
+	}
+	name, ok := value.(string)
+	if !ok {
+		errs.Add("name", CodeType, "name must be a string")
+		return "", false
+	}
+
+	name = norm.NFC.String(name)
+	switch {
+	case strings.TrimSpace(name) == "":
+		errs.Add("name", CodeBlank, "name cannot be empty")
+	case utf8.RuneCountInString(name) > MaxNameLength:
+		errs.Add("name", CodeMaxLength, fmt.Sprintf("name must be at most %d characters", MaxNameLength))
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		errs.Add("name", CodeFormat, "name cannot contain control characters")
+	default:
+		return name, true
+	}
+	return "", false
+}
+
+func taskStatus(raw map[string]interface{}, errs *Errors) (int, bool) {
+	value, exists := raw["status"]
+	if !exists {
+		errs.Add("status", CodeRequired, "status is required")
+		return 0, false
+	}
+	status, ok := value.(float64)
+	if !ok {
+		errs.Add("status", CodeType, "status must be a number")
+		return 0, false
+	}
+	// 不可用 int() 直接截斷，1.5 會被當成 1
+	if status != math.Trunc(status) {
+		errs.Add("status", CodeType, "status must be an integer")
+		return 0, false
+	}
+	if status < 0 || status > 1 {
+		errs.Add("status", CodeEnum, "status must be 0 or 1")
+		return 0, false
+	}
+	return int(status), true
+}
+
+// taskDue 解析選填的到期時間，統一轉為 UTC 並取到秒（與 iCalendar 精度一致）
+func taskDue(raw map[string]interface{}, errs *Errors) (*time.Time, bool) {
+	switch v := raw["due"].(type) {
+	case nil:
+		return nil, true
+	case time.Time:
+		due := v.UTC().Truncate(time.Second)
+		return &due, true
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, true
+		}
+		if parsed, err := time.Parse(time.RFC3339, v); err == nil {
+			due := parsed.UTC().Truncate(time.Second)
+			return &due, true
+		}
+	}
+	errs.Add("due", CodeFormat, "due must be an RFC 3339 timestamp")
+	return nil, false
+}
+
+// unknownFields 回報任務欄位與 allow 以外的欄位，依名稱排序讓錯誤順序固定
+func unknownFields(raw map[string]interface{}, allow []string, errs *Errors) {
+	var unknown []string
+	for key := range raw {
+		if !contains(taskFields, key) && !contains(allow, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs.Add(key, CodeUnknown, fmt.Sprintf("unknown field %q", key))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
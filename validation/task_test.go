@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogolook/task-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask(t *testing.T) {
+	due := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		raw          map[string]interface{}
+		allow        []string
+		expectedTask model.Task
+		expectedErrs Errors
+	}{
+		{
+			name:         "合法的任務",
+			raw:          map[string]interface{}{"name": "Task", "status": float64(1), "due": "2026-10-19T17:00:00+08:00"},
+			expectedTask: model.Task{Name: "Task", Status: 1, Due: &due},
+		},
+		{
+			name: "缺少所有必填欄位",
+			raw:  map[string]interface{}{},
+			expectedErrs: Errors{
+				{Field: "name", Code: CodeRequired, Message: "name is required"},
+				{Field: "status", Code: CodeRequired, Message: "status is required"},
+			},
+		},
+		{
+			name: "一次回報所有欄位錯誤",
+			raw:  map[string]interface{}{"name": "  ", "status": float64(2), "due": "tomorrow"},
+			expectedErrs: Errors{
+				{Field: "name", Code: CodeBlank, Message: "name cannot be empty"},
+				{Field: "status", Code: CodeEnum, Message: "status must be 0 or 1"},
+				{Field: "due", Code: CodeFormat, Message: "due must be an RFC 3339 timestamp"},
+			},
+		},
+		{
+			name: "型別錯誤",
+			raw:  map[string]interface{}{"name": float64(123), "status": "1", "due": float64(1)},
+			expectedErrs: Errors{
+				{Field: "name", Code: CodeType, Message: "name must be a string"},
+				{Field: "status", Code: CodeType, Message: "status must be a number"},
+				{Field: "due", Code: CodeFormat, Message: "due must be an RFC 3339 timestamp"},
+			},
+		},
+		{
+			name: "狀態不是整數",
+			raw:  map[string]interface{}{"name": "Task", "status": 1.5},
+			expectedErrs: Errors{
+				{Field: "status", Code: CodeType, Message: "status must be an integer"},
+			},
+		},
+		{
+			name:         "名稱長度以字元計算",
+			raw:          map[string]interface{}{"name": strings.Repeat("任", MaxNameLength), "status": float64(0)},
+			expectedTask: model.Task{Name: strings.Repeat("任", MaxNameLength)},
+		},
+		{
+			name: "名稱過長",
+			raw:  map[string]interface{}{"name": strings.Repeat("a", MaxNameLength+1), "status": float64(0)},
+			expectedErrs: Errors{
+				{Field: "name", Code: CodeMaxLength, Message: "name must be at most 200 characters"},
+			},
+		},
+		{
+			name: "名稱含控制字元",
+			raw:  map[string]interface{}{"name": "Task\x00", "status": float64(0)},
+			expectedErrs: Errors{
+				{Field: "name", Code: CodeFormat, Message: "name cannot contain control characters"},
+			},
+		},
+		{
+			name:         "名稱正規化為 NFC",
+			raw:          map[string]interface{}{"name": "Cafe\u0301", "status": float64(0)},
+			expectedTask: model.Task{Name: "Caf\u00e9"},
+		},
+		{
+			name: "未知欄位依名稱排序",
+			raw:  map[string]interface{}{"name": "Task", "status": float64(0), "priority": "high", "id": "1"},
+			expectedErrs: Errors{
+				{Field: "id", Code: CodeUnknown, Message: `unknown field "id"`},
+				{Field: "priority", Code: CodeUnknown, Message: `unknown field "priority"`},
+			},
+		},
+		{
+			name:         "呼叫端允許的欄位",
+			raw:          map[string]interface{}{"name": "Task", "status": float64(0), "id": "1"},
+			allow:        []string{"id"},
+			expectedTask: model.Task{Name: "Task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task model.Task
+			err := Task(tt.raw, &task, tt.allow...)
+
+			if tt.expectedErrs == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedTask, task)
+				return
+			}
+			var errs Errors
+			require.ErrorAs(t, err, &errs)
+			assert.Equal(t, tt.expectedErrs, errs)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var errs Errors
+	assert.NoError(t, errs.Err())
+
+	errs.Add("name", CodeRequired, "name is required")
+	errs.Add("status", CodeEnum, "status must be 0 or 1")
+	assert.EqualError(t, errs.Err(), "name is required; status must be 0 or 1")
+}
@@ -0,0 +1,45 @@
+// Package validation 驗證請求欄位並一次回報所有錯誤
+// 規則不依賴傳輸方式，REST、批次與匯入都以解析後的 map 呼叫同一組規則
+package validation
+
+import (
+	"strings"
+
+	"github.com/gogolook/task-api/model"
+)
+
+// 欄位錯誤的規則代碼
+const (
+	CodeRequired  = "required"
+	CodeType      = "type"
+	CodeBlank     = "blank"
+	CodeMaxLength = "max_length"
+	CodeEnum      = "enum"
+	CodeFormat    = "format"
+	CodeUnknown   = "unknown"
+)
+
+// Errors 收集到的欄位錯誤，依發現的順序排列
+type Errors []model.FieldError
+
+// Add 加入一個欄位錯誤，field 為欄位的 JSON 路徑
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, model.FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err 沒有錯誤時回傳 nil，讓呼叫端可以直接 return
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error 以分號串接所有錯誤訊息
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}